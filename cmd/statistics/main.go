@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"eth2-exporter/cache"
 	"eth2-exporter/db"
 	"eth2-exporter/price"
@@ -25,6 +26,7 @@ type options struct {
 	statisticsValidatorToggle bool
 	statisticsResetColumns    string
 	statisticsChartToggle     bool
+	statisticsPreviewToggle   bool
 }
 
 var opt *options
@@ -36,6 +38,10 @@ func main() {
 	statisticsValidatorToggle := flag.Bool("validators.enabled", false, "Toggle exporting validator statistics")
 	statisticsResetColumns := flag.String("validators.reset", "", "validator_stats_status columns to reset. Comma separated. Use 'all' for complete resync.")
 	statisticsChartToggle := flag.Bool("charts.enabled", false, "Toggle exporting chart series")
+	statisticsDryRun := flag.Bool("validators.dry-run", false, "Only log the validator statistics that would be written without writing anything")
+	statisticsBestEffort := flag.Bool("validators.best-effort", false, "Run all sub-exports of a day even if one of them fails, the failed ones are reported at the end")
+	statisticsValidatorFilter := flag.String("validators.filter", "", "Only export the statistics of the given validator indices, for testing. Comma separated.")
+	statisticsPreviewToggle := flag.Bool("validators.preview", false, "Toggle exporting a preview of the validator statistics of the current day from its finalized epochs")
 
 	versionFlag := flag.Bool("version", false, "Show version and exit")
 	flag.Parse()
@@ -52,6 +58,7 @@ func main() {
 		statisticsChartToggle:     *statisticsChartToggle,
 		statisticsResetColumns:    *statisticsResetColumns,
 		statisticsValidatorToggle: *statisticsValidatorToggle,
+		statisticsPreviewToggle:   *statisticsPreviewToggle,
 	}
 
 	logrus.Printf("version: %v, config file path: %v", version.Version, *configPath)
@@ -62,6 +69,22 @@ func main() {
 		logrus.Fatalf("error reading config file: %v", err)
 	}
 	utils.Config = cfg
+	if *statisticsDryRun {
+		utils.Config.Statistics.DryRun = true
+	}
+	if *statisticsBestEffort {
+		utils.Config.Statistics.BestEffort = true
+	}
+	if *statisticsValidatorFilter != "" {
+		for _, s := range strings.Split(*statisticsValidatorFilter, ",") {
+			validatorIndex, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				utils.LogFatal(err, "error parsing validators.filter flag to uint", 0)
+			}
+			utils.Config.Statistics.ValidatorFilter = append(utils.Config.Statistics.ValidatorFilter, validatorIndex)
+		}
+		logrus.Warnf("only exporting the statistics of the validators %v", utils.Config.Statistics.ValidatorFilter)
+	}
 
 	if *statisticsChartToggle && utils.Config.Chain.Config.DepositChainID != 1 {
 		logrus.Infof("Execution charts are currently only available for mainnet")
@@ -211,13 +234,12 @@ func statisticsLoop() {
 			continue
 		}
 
-		epochsPerDay := utils.EpochsPerDay()
-		if latestEpoch < epochsPerDay {
+		if latestEpoch < utils.FirstEpochOfDay(1) {
 			logrus.Infof("skipping exporting stats, first day has not been indexed yet")
 			time.Sleep(time.Minute)
 			continue
 		}
-		currentDay := latestEpoch / epochsPerDay
+		currentDay := utils.EpochToDay(latestEpoch)
 		previousDay := currentDay - 1
 
 		if previousDay > currentDay {
@@ -237,6 +259,10 @@ func statisticsLoop() {
 			if lastExportedDayValidator <= previousDay || lastExportedDayValidator == 0 {
 				for day := lastExportedDayValidator; day <= previousDay; day++ {
 					err := db.WriteValidatorStatisticsForDay(day)
+					if errors.Is(err, db.ErrStatisticsExportSkipped) {
+						logrus.Infof("stats of day %v are exported by another instance, retrying later: %v", day, err)
+						break
+					}
 					if err != nil {
 						logrus.Errorf("error exporting stats for day %v: %v", day, err)
 						break
@@ -244,6 +270,14 @@ func statisticsLoop() {
 				}
 			}
 
+			if opt.statisticsPreviewToggle {
+				err := db.WriteValidatorStatisticsPreviewForDay(currentDay, latestEpoch)
+				if errors.Is(err, db.ErrStatisticsExportSkipped) {
+					logrus.Infof("stats preview of day %v is exported by another instance: %v", currentDay, err)
+				} else if err != nil {
+					logrus.Errorf("error exporting stats preview for day %v: %v", currentDay, err)
+				}
+			}
 		}
 
 		if opt.statisticsChartToggle {
@@ -275,6 +309,12 @@ func statisticsLoop() {
 }
 
 func clearStatsStatusTable(day uint64, columns string) {
+	if utils.Config.Statistics.DryRun {
+		if len(columns) > 0 {
+			logrus.Infof("dry run: not resetting columns %v of validator_stats_status for day %v", columns, day)
+		}
+		return
+	}
 	if columns == "all" {
 		logrus.Infof("Delete validator_stats_status for day %v", day)
 		_, err := db.WriterDb.Exec("DELETE FROM validator_stats_status WHERE day = $1", day)
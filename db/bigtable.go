@@ -1074,6 +1074,107 @@ func (bigtable *Bigtable) GetValidatorFailedAttestationsCount(validators []uint6
 	return res, nil
 }
 
+// GetValidatorMissedAttestationComponents returns the missed source, target and head votes of the validators between firstEpoch and
+// lastEpoch (inclusive). The attestation history only tells whether a vote was included at all, so the components are derived from
+// the rewards and penalties of each epoch. Only validators that missed a vote are returned.
+func (bigtable *Bigtable) GetValidatorMissedAttestationComponents(validators []uint64, firstEpoch uint64, lastEpoch uint64) (map[uint64]*types.ValidatorAttestationComponentsStatistic, error) {
+	if firstEpoch > lastEpoch {
+		return nil, fmt.Errorf("GetValidatorMissedAttestationComponents received an invalid firstEpoch (%d) and lastEpoch (%d) combination", firstEpoch, lastEpoch)
+	}
+
+	income, err := bigtable.GetValidatorIncomeDetailsHistory(validators, firstEpoch, lastEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("retrieved income details history for epochs %v - %v", firstEpoch, lastEpoch)
+
+	res := make(map[uint64]*types.ValidatorAttestationComponentsStatistic)
+	addMissedAttestationComponents(res, income)
+	return res, nil
+}
+
+// addMissedAttestationComponents adds the missed source, target and head votes found in the given
+// per epoch income details to the statistics, creating entries for validators that missed a vote
+func addMissedAttestationComponents(res map[uint64]*types.ValidatorAttestationComponentsStatistic, income map[uint64]map[uint64]*itypes.ValidatorEpochIncome) {
+	for validator, epochs := range income {
+		for _, details := range epochs {
+			source, target, head := missedAttestationComponents(details)
+			if !source && !target && !head {
+				continue
+			}
+			stat := res[validator]
+			if stat == nil {
+				stat = &types.ValidatorAttestationComponentsStatistic{Index: validator}
+				res[validator] = stat
+			}
+			if source {
+				stat.MissedSource++
+			}
+			if target {
+				stat.MissedTarget++
+			}
+			if head {
+				stat.MissedHead++
+			}
+		}
+	}
+}
+
+// missedAttestationComponents reports which votes of the attestation duty of an epoch were missed.
+// Missed source and target votes are penalized, a head vote is only correct if the target vote is, so
+// a missed target vote is a missed head vote as well. Otherwise a missed head vote has no penalty and
+// only shows as a missing head reward, which is not paid during an inactivity leak either. It is only
+// counted if the epoch paid a source reward, i.e. outside of an inactivity leak.
+func missedAttestationComponents(income *itypes.ValidatorEpochIncome) (source bool, target bool, head bool) {
+	if income == nil {
+		return false, false, false
+	}
+	source = income.AttestationSourcePenalty > 0
+	target = income.AttestationTargetPenalty > 0
+	head = target || (income.AttestationSourceReward > 0 && income.AttestationHeadReward == 0)
+	return source, target, head
+}
+
+func (bigtable *Bigtable) GetValidatorAttestationInclusionStatistics(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]*types.ValidatorAttestationInclusionStatistic, error) {
+	if startEpoch > endEpoch {
+		return nil, fmt.Errorf("GetValidatorAttestationInclusionStatistics received an invalid startEpoch (%d) and endEpoch (%d) combination", startEpoch, endEpoch)
+	}
+
+	data, err := bigtable.GetValidatorAttestationHistory(validators, startEpoch, endEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("retrieved attestation history for epochs %v - %v", startEpoch, endEpoch)
+
+	return aggregateAttestationInclusion(data), nil
+}
+
+// aggregateAttestationInclusion sums up the inclusion distances of all included attestations per validator, missed attestations are ignored
+func aggregateAttestationInclusion(history map[uint64][]*types.ValidatorAttestation) map[uint64]*types.ValidatorAttestationInclusionStatistic {
+	res := make(map[uint64]*types.ValidatorAttestationInclusionStatistic)
+	for validator, attestations := range history {
+		for _, attestation := range attestations {
+			if attestation.Status != 1 || attestation.InclusionSlot <= attestation.AttesterSlot {
+				continue
+			}
+			if res[validator] == nil {
+				res[validator] = &types.ValidatorAttestationInclusionStatistic{
+					Index: validator,
+				}
+			}
+			distance := attestation.InclusionSlot - attestation.AttesterSlot
+			res[validator].IncludedAttestations++
+			res[validator].InclusionDistanceSum += distance
+			if distance > res[validator].MaxInclusionDistance {
+				res[validator].MaxInclusionDistance = distance
+			}
+		}
+	}
+	return res
+}
+
 func (bigtable *Bigtable) GetValidatorSyncDutiesStatistics(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]*types.ValidatorSyncDutiesStatistic, error) {
 	data, err := bigtable.GetValidatorSyncDutiesHistory(validators, startEpoch, endEpoch)
 
@@ -1167,6 +1268,34 @@ func (bigtable *Bigtable) GetValidatorEffectiveness(validators []uint64, epoch u
 }
 
 func (bigtable *Bigtable) GetValidatorBalanceStatistics(startEpoch, endEpoch uint64) (map[uint64]*types.ValidatorBalanceStatistic, error) {
+	return bigtable.getValidatorBalanceStatistics(startEpoch, endEpoch, gcp_bigtable.FamilyFilter(VALIDATOR_BALANCES_FAMILY))
+}
+
+// GetValidatorBalanceStatisticsAfterValidator returns the balance statistics of the validators with an index above validatorIndex only
+func (bigtable *Bigtable) GetValidatorBalanceStatisticsAfterValidator(validatorIndex, startEpoch, endEpoch uint64) (map[uint64]*types.ValidatorBalanceStatistic, error) {
+	return bigtable.getValidatorBalanceStatistics(startEpoch, endEpoch, gcp_bigtable.ChainFilters(
+		gcp_bigtable.FamilyFilter(VALIDATOR_BALANCES_FAMILY),
+		gcp_bigtable.ColumnFilter(validatorIndexAboveRegex(validatorIndex)),
+	))
+}
+
+// validatorIndexAboveRegex returns a regex matching the decimal validator indices above validatorIndex, as the balance columns are not
+// padded they can't be selected by a column range
+func validatorIndexAboveRegex(validatorIndex uint64) string {
+	digits := strconv.FormatUint(validatorIndex, 10)
+	// any index with more digits
+	alternatives := []string{fmt.Sprintf("[1-9][0-9]{%d,}", len(digits))}
+	// indices with the same number of digits sharing a prefix, followed by a higher digit
+	for i := 0; i < len(digits); i++ {
+		if digits[i] == '9' {
+			continue
+		}
+		alternatives = append(alternatives, fmt.Sprintf("%s[%c-9][0-9]{%d}", digits[:i], digits[i]+1, len(digits)-i-1))
+	}
+	return "(" + strings.Join(alternatives, "|") + ")"
+}
+
+func (bigtable *Bigtable) getValidatorBalanceStatistics(startEpoch, endEpoch uint64, filter gcp_bigtable.Filter) (map[uint64]*types.ValidatorBalanceStatistic, error) {
 	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*10))
 	defer cancel()
 
@@ -1256,7 +1385,7 @@ func (bigtable *Bigtable) GetValidatorBalanceStatistics(startEpoch, endEpoch uin
 				resultContainer.mu.Unlock()
 
 				return true
-			}, gcp_bigtable.RowFilter(gcp_bigtable.FamilyFilter(VALIDATOR_BALANCES_FAMILY)))
+			}, gcp_bigtable.RowFilter(filter))
 
 			return err
 
@@ -1846,8 +1975,7 @@ func GetCurrentDayClIncome(validator_indices []uint64) (map[uint64]int64, map[ui
 	}
 
 	currentDay := uint64(lastDay + 1)
-	startEpoch := currentDay * utils.EpochsPerDay()
-	endEpoch := startEpoch + utils.EpochsPerDay() - 1
+	startEpoch, endEpoch := utils.GetFirstAndLastEpochForDay(currentDay)
 	income, err := BigtableClient.GetValidatorIncomeDetailsHistory(validator_indices, startEpoch, endEpoch)
 	if err != nil {
 		return dayIncome, dayProposerIncome, err
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"embed"
 	"encoding/hex"
+	"errors"
 	"eth2-exporter/metrics"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
@@ -686,6 +687,11 @@ func SaveValidatorQueue(validators *types.ValidatorQueue) error {
 	return err
 }
 
+// BlockSeenDelay returns how long after the start of its slot a block has been seen
+func BlockSeenDelay(slot uint64, seenAt time.Time) time.Duration {
+	return seenAt.Sub(utils.SlotToTime(slot))
+}
+
 func SaveBlock(block *types.Block) error {
 
 	blocksMap := make(map[uint64]map[string]*types.Block)
@@ -707,6 +713,14 @@ func SaveBlock(block *types.Block) error {
 		return fmt.Errorf("error saving blocks to db: %w", err)
 	}
 
+	if !block.SeenAt.IsZero() {
+		// only the first time a block is seen counts, it might have been exported by a full check before
+		_, err = tx.Exec("UPDATE blocks SET seen_delay_ms = $1 WHERE slot = $2 AND blockroot = $3 AND seen_delay_ms IS NULL", BlockSeenDelay(block.Slot, block.SeenAt).Milliseconds(), block.Slot, block.BlockRoot)
+		if err != nil {
+			return fmt.Errorf("error saving seen delay of block %v: %w", block.Slot, err)
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("error committing db transaction: %w", err)
 	}
@@ -1884,11 +1898,11 @@ func updateQueueDeposits() error {
 			FROM CumSum
 			/* join so we can retrieve the validator index again */
 			left join validators on validators.pubkey = CumSum.publickey
-			/* we want the deposit that pushed the cum sum over 32 ETH */
-			WHERE cumTotal>=32000000000
+			/* we want the deposit that pushed the cum sum over the max effective balance (32 ETH) */
+			WHERE cumTotal>=$1
 			ORDER BY publickey, cumTotal asc 
 		) AS data
-		WHERE validator_queue_deposits.validatorindex=data.validatorindex`)
+		WHERE validator_queue_deposits.validatorindex=data.validatorindex`, utils.Config.Chain.Config.MaxEffectiveBalance)
 	if err != nil {
 		logger.Errorf("error updating validator_queue_deposits: %v", err)
 		return err
@@ -1986,7 +2000,7 @@ func GetTotalEligibleEther() (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return total / 1e9, nil
+	return total / utils.ClCurrencyDivisor(), nil
 }
 
 func GetDepositThresholdTime() (*time.Time, error) {
@@ -1997,17 +2011,17 @@ func GetDepositThresholdTime() (*time.Time, error) {
 			from (
 				SELECT
 					publickey,
-					32e9 AS amount,
+					$2::numeric AS amount,
 					MAX(block_ts) as block_ts,
 					MAX(block_number) as block_number
 				FROM eth1_deposits
 				WHERE valid_signature = true
 				GROUP BY publickey
-				HAVING SUM(amount) >= 32e9
+				HAVING SUM(amount) >= $2
 			) a
 		) b
 		where totalsum > $1;
-		 `, utils.Config.Chain.Config.MinGenesisActiveValidatorCount*32e9)
+		 `, utils.Config.Chain.Config.MinGenesisActiveValidatorCount*utils.Config.Chain.Config.MaxEffectiveBalance, utils.Config.Chain.Config.MaxEffectiveBalance)
 	if err != nil {
 		return nil, err
 	}
@@ -3097,6 +3111,8 @@ func GetPendingBLSChangeValidatorCount() (uint64, error) {
 	return count, nil
 }
 
+// GetLastExportedStatisticDay returns the highest completely exported statistics day, 0 if no day has been exported yet.
+// Use GetLastFinalizedStatisticDay to tell day 0 and no exported day apart.
 func GetLastExportedStatisticDay() (uint64, error) {
 	var lastStatsDay uint64
 	err := ReaderDb.Get(&lastStatsDay, "SELECT COALESCE(MAX(day),0) FROM validator_stats_status WHERE status")
@@ -3107,6 +3123,56 @@ func GetLastExportedStatisticDay() (uint64, error) {
 	return lastStatsDay, nil
 }
 
+// GetStatisticsExportLag returns how far the statistics export is behind. A day can only be exported once its last epoch is finalized,
+// so the lag is the time passed since the day following the last exported day became exportable, 0 if it is not finalized yet.
+func GetStatisticsExportLag() (time.Duration, error) {
+	lastExportedDay, err := GetLastExportedStatisticDay()
+	if err != nil {
+		return 0, err
+	}
+	return statisticsExportLag(lastExportedDay, time.Now()), nil
+}
+
+// statisticsExportLag returns the time passed at now since the day after lastExportedDay could have been exported, which is
+// the end of that day plus the two epochs it takes to finalize its last epoch
+func statisticsExportLag(lastExportedDay uint64, now time.Time) time.Duration {
+	finalityDelay := time.Duration(2*utils.Config.Chain.Config.SlotsPerEpoch*utils.Config.Chain.Config.SecondsPerSlot) * time.Second
+	exportable := utils.DayToTime(int64(lastExportedDay) + 2).Add(finalityDelay)
+	if now.Before(exportable) {
+		return 0
+	}
+	return now.Sub(exportable)
+}
+
+// ErrNoStatisticsExported is returned by GetFirstExportedStatisticDay and GetLastFinalizedStatisticDay if no day has been completely exported yet
+var ErrNoStatisticsExported = errors.New("no statistics day exported")
+
+// GetLastFinalizedStatisticDay returns the highest day with status set in validator_stats_status, meaning all of its statistics have
+// been exported. Days after it may already have rows in validator_stats from sub-exports that completed, they must not be treated
+// as exported.
+func GetLastFinalizedStatisticDay() (uint64, error) {
+	var lastStatsDay uint64
+	err := ReaderDb.Get(&lastStatsDay, "SELECT day FROM validator_stats_status WHERE status ORDER BY day DESC LIMIT 1")
+	if err == sql.ErrNoRows {
+		return 0, ErrNoStatisticsExported
+	} else if err != nil {
+		return 0, fmt.Errorf("error getting lastStatsDay %w", err)
+	}
+	return lastStatsDay, nil
+}
+
+// GetFirstExportedStatisticDay returns the earliest completely exported statistics day
+func GetFirstExportedStatisticDay() (uint64, error) {
+	var firstStatsDay uint64
+	err := ReaderDb.Get(&firstStatsDay, "SELECT day FROM validator_stats_status WHERE status ORDER BY day LIMIT 1")
+	if err == sql.ErrNoRows {
+		return 0, ErrNoStatisticsExported
+	} else if err != nil {
+		return 0, fmt.Errorf("error getting firstStatsDay %w", err)
+	}
+	return firstStatsDay, nil
+}
+
 func GetValidatorIncomePerforamance(validators []uint64, incomePerformance *types.ValidatorIncomePerformance) error {
 	validatorsPQArray := pq.Array(validators)
 	// el rewards are converted from wei to gwei
@@ -3170,6 +3236,63 @@ func GetValidatorWithdrawalsForEpochs(validators []uint64, fromEpoch uint64, toE
 	`, validatorsPQArray, fromEpoch, toEpoch)
 }
 
+// validatorAmount is the summed deposit or withdrawal amount (in gwei) of a validator
+type validatorAmount struct {
+	ValidatorIndex uint64 `db:"validatorindex"`
+	Amount         uint64 `db:"amount"`
+}
+
+// GetValidatorDepositsForEpochsByValidator returns the deposited amount of each of the given validators within the epoch range.
+// Validators without deposits are contained with an amount of 0.
+func GetValidatorDepositsForEpochsByValidator(validators []uint64, fromEpoch uint64, toEpoch uint64) (map[uint64]uint64, error) {
+	amounts := []validatorAmount{}
+	err := ReaderDb.Select(&amounts, `
+		SELECT 
+			v.validatorindex,
+			COALESCE(SUM(d.amount), 0) AS amount
+		FROM blocks_deposits d
+		INNER JOIN blocks b ON b.blockroot = d.block_root AND b.status = '1' and b.epoch >= $2 and b.epoch <= $3
+		INNER JOIN validators v ON v.pubkey = d.publickey
+		WHERE v.validatorindex = ANY($1)
+		GROUP BY v.validatorindex
+	`, pq.Array(validators), fromEpoch, toEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting deposits of %v validators for epochs %v - %v: %w", len(validators), fromEpoch, toEpoch, err)
+	}
+	return validatorAmountsByIndex(validators, amounts), nil
+}
+
+// GetValidatorWithdrawalsForEpochsByValidator returns the withdrawn amount of each of the given validators within the epoch range.
+// Validators without withdrawals are contained with an amount of 0.
+func GetValidatorWithdrawalsForEpochsByValidator(validators []uint64, fromEpoch uint64, toEpoch uint64) (map[uint64]uint64, error) {
+	amounts := []validatorAmount{}
+	err := ReaderDb.Select(&amounts, `
+		SELECT 
+			d.validatorindex,
+			COALESCE(SUM(d.amount), 0) AS amount
+		FROM blocks_withdrawals d
+		INNER JOIN blocks b ON b.blockroot = d.block_root AND b.status = '1' and b.epoch >= $2 and b.epoch <= $3
+		WHERE d.validatorindex = ANY($1)
+		GROUP BY d.validatorindex
+	`, pq.Array(validators), fromEpoch, toEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting withdrawals of %v validators for epochs %v - %v: %w", len(validators), fromEpoch, toEpoch, err)
+	}
+	return validatorAmountsByIndex(validators, amounts), nil
+}
+
+// validatorAmountsByIndex sums up the amounts by validator index, every requested validator is contained in the result
+func validatorAmountsByIndex(validators []uint64, amounts []validatorAmount) map[uint64]uint64 {
+	res := make(map[uint64]uint64, len(validators))
+	for _, validator := range validators {
+		res[validator] = 0
+	}
+	for _, amount := range amounts {
+		res[amount.ValidatorIndex] += amount.Amount
+	}
+	return res
+}
+
 func GetValidatorBalanceForDay(validators []uint64, day uint64, balance *uint64) error {
 	validatorsPQArray := pq.Array(validators)
 	return ReaderDb.Get(balance, `
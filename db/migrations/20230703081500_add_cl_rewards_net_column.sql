@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add cl_rewards_gwei_net column to validator_stats';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS cl_rewards_gwei_net BIGINT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove cl_rewards_gwei_net column from validator_stats';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS cl_rewards_gwei_net;
+-- +goose StatementEnd
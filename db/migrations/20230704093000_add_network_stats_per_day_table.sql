@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add network_stats_per_day table';
+CREATE TABLE IF NOT EXISTS
+    network_stats_per_day (
+        DAY INT NOT NULL,
+        balance_p25 BIGINT,
+        balance_median BIGINT,
+        balance_p75 BIGINT,
+        PRIMARY KEY (DAY)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop network_stats_per_day table';
+DROP TABLE IF EXISTS network_stats_per_day;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add attestation inclusion distance columns';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS avg_inclusion_distance DOUBLE PRECISION;
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS max_inclusion_distance INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS inclusion_distance_exported BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove attestation inclusion distance columns';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS avg_inclusion_distance;
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS max_inclusion_distance;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS inclusion_distance_exported;
+-- +goose StatementEnd
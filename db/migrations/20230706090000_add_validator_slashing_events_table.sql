@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add validator_slashing_events table';
+CREATE TABLE IF NOT EXISTS
+    validator_slashing_events (
+        validatorindex INT NOT NULL,
+        DAY INT NOT NULL,
+        slot INT NOT NULL,
+        block_index INT NOT NULL,
+        slasher INT NOT NULL,
+        TYPE VARCHAR(20) NOT NULL,
+        PRIMARY KEY (slot, block_index, validatorindex, TYPE)
+    );
+CREATE INDEX IF NOT EXISTS idx_validator_slashing_events_validatorindex ON validator_slashing_events (validatorindex);
+CREATE INDEX IF NOT EXISTS idx_validator_slashing_events_day ON validator_slashing_events (DAY);
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS slashing_events_exported BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop validator_slashing_events table';
+DROP TABLE IF EXISTS validator_slashing_events;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS slashing_events_exported;
+-- +goose StatementEnd
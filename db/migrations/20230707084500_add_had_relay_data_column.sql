@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add had_relay_data column';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS had_relay_data BOOLEAN;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove had_relay_data column';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS had_relay_data;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add proposer reward breakdown columns';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS cl_proposer_attestation_inclusion_rewards_gwei BIGINT;
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS cl_proposer_sync_inclusion_rewards_gwei BIGINT;
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS cl_proposer_slashing_inclusion_rewards_gwei BIGINT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove proposer reward breakdown columns';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS cl_proposer_attestation_inclusion_rewards_gwei;
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS cl_proposer_sync_inclusion_rewards_gwei;
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS cl_proposer_slashing_inclusion_rewards_gwei;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add sync_participation_rate column';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS sync_participation_rate DOUBLE PRECISION;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove sync_participation_rate column';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS sync_participation_rate;
+-- +goose StatementEnd
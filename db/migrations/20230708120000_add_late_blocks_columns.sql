@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add late blocks columns';
+ALTER TABLE blocks ADD COLUMN IF NOT EXISTS seen_delay_ms INT;
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS late_blocks INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove late blocks columns';
+ALTER TABLE blocks DROP COLUMN IF EXISTS seen_delay_ms;
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS late_blocks;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add slashing income columns';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS slashing_reward_gwei BIGINT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS slashing_income_exported BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove slashing income columns';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS slashing_reward_gwei;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS slashing_income_exported;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add mev and local blocks columns';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS mev_blocks INT;
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS local_blocks INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove mev and local blocks columns';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS mev_blocks;
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS local_blocks;
+-- +goose StatementEnd
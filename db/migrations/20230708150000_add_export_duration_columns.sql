@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add export duration columns';
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS failed_attestations_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS sync_duties_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS withdrawals_deposits_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS balance_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS cl_rewards_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS el_rewards_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS total_performance_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS block_stats_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS inclusion_distance_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS slashing_events_export_ms INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS slashing_income_export_ms INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove export duration columns';
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS failed_attestations_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS sync_duties_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS withdrawals_deposits_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS balance_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS cl_rewards_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS el_rewards_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS total_performance_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS block_stats_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS inclusion_distance_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS slashing_events_export_ms;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS slashing_income_export_ms;
+-- +goose StatementEnd
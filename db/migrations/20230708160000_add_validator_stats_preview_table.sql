@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add validator_stats_preview table';
+CREATE TABLE IF NOT EXISTS
+    validator_stats_preview (
+        validatorindex INT NOT NULL,
+        DAY INT NOT NULL,
+        last_epoch INT NOT NULL,
+        end_balance BIGINT,
+        cl_rewards_gwei_net BIGINT,
+        PRIMARY KEY (validatorindex, DAY)
+    );
+CREATE INDEX IF NOT EXISTS idx_validator_stats_preview_day ON validator_stats_preview (DAY);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop validator_stats_preview table';
+DROP TABLE IF EXISTS validator_stats_preview;
+-- +goose StatementEnd
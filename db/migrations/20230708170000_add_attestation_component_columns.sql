@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add missed source, target and head columns';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS missed_source INT;
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS missed_target INT;
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS missed_head INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove missed source, target and head columns';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS missed_source;
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS missed_target;
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS missed_head;
+-- +goose StatementEnd
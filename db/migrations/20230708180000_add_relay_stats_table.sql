@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add relay_stats table';
+CREATE TABLE IF NOT EXISTS
+    relay_stats (
+        DAY INT NOT NULL,
+        relay VARCHAR NOT NULL,
+        blocks INT NOT NULL,
+        mev_rewards_wei DECIMAL NOT NULL,
+        avg_bid_wei DECIMAL NOT NULL,
+        PRIMARY KEY (DAY, relay)
+    );
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS relay_stats_exported BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS relay_stats_export_ms INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop relay_stats table';
+DROP TABLE IF EXISTS relay_stats;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS relay_stats_exported;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS relay_stats_export_ms;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add attestation effectiveness column';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS attestation_effectiveness DOUBLE PRECISION;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove attestation effectiveness column';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS attestation_effectiveness;
+-- +goose StatementEnd
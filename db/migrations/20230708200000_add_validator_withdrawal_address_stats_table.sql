@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add validator_withdrawal_address_stats table';
+CREATE TABLE IF NOT EXISTS
+    validator_withdrawal_address_stats (
+        validatorindex INT NOT NULL,
+        address bytea NOT NULL,
+        DAY INT NOT NULL,
+        withdrawals INT NOT NULL,
+        withdrawals_amount BIGINT NOT NULL,
+        PRIMARY KEY (validatorindex, address, DAY)
+    );
+CREATE INDEX IF NOT EXISTS idx_validator_withdrawal_address_stats_address_day ON validator_withdrawal_address_stats (address, DAY);
+CREATE INDEX IF NOT EXISTS idx_validator_withdrawal_address_stats_day ON validator_withdrawal_address_stats (DAY);
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS withdrawal_address_stats_exported BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS withdrawal_address_stats_export_ms INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop validator_withdrawal_address_stats table';
+DROP TABLE IF EXISTS validator_withdrawal_address_stats;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS withdrawal_address_stats_exported;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS withdrawal_address_stats_export_ms;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add orphaned el rewards column';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS orphaned_el_rewards_wei NUMERIC;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove orphaned el rewards column';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS orphaned_el_rewards_wei;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- network_stats_per_day is created by 20230704093000 with the balance percentiles written by the balance export, the aggregates
+-- written by the network stats export are added here. They stay nullable as the percentiles of a day are written first.
+SELECT 'up SQL query - add network stats columns to network_stats_per_day';
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS active_validators INT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS total_balance BIGINT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS total_effective_balance BIGINT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS avg_balance BIGINT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS cl_rewards_gwei BIGINT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS el_rewards_wei DECIMAL;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS mev_rewards_wei DECIMAL;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS deposits INT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS deposits_amount BIGINT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS withdrawals INT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS withdrawals_amount BIGINT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS proposed_blocks INT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS missed_blocks INT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS orphaned_blocks INT;
+ALTER TABLE network_stats_per_day ADD COLUMN IF NOT EXISTS sync_participation_rate DOUBLE PRECISION;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS network_stats_exported BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS network_stats_export_ms INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop network stats columns from network_stats_per_day';
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS active_validators;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS total_balance;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS total_effective_balance;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS avg_balance;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS cl_rewards_gwei;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS el_rewards_wei;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS mev_rewards_wei;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS deposits;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS deposits_amount;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS withdrawals;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS withdrawals_amount;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS proposed_blocks;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS missed_blocks;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS orphaned_blocks;
+ALTER TABLE network_stats_per_day DROP COLUMN IF EXISTS sync_participation_rate;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS network_stats_exported;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS network_stats_export_ms;
+-- +goose StatementEnd
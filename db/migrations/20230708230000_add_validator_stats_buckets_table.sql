@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add validator_stats_buckets table';
+CREATE TABLE IF NOT EXISTS
+    validator_stats_buckets (
+        validatorindex INT NOT NULL,
+        bucket VARCHAR(100) NOT NULL,
+        first_epoch INT NOT NULL,
+        last_epoch INT NOT NULL,
+        min_balance BIGINT,
+        max_balance BIGINT,
+        min_effective_balance BIGINT,
+        max_effective_balance BIGINT,
+        start_balance BIGINT,
+        start_effective_balance BIGINT,
+        end_balance BIGINT,
+        end_effective_balance BIGINT,
+        participated_sync INT,
+        missed_sync INT,
+        orphaned_sync INT,
+        sync_participation_rate DOUBLE PRECISION,
+        missed_attestations INT,
+        orphaned_attestations INT,
+        missed_source INT,
+        missed_target INT,
+        missed_head INT,
+        PRIMARY KEY (validatorindex, bucket)
+    );
+CREATE INDEX IF NOT EXISTS idx_validator_stats_buckets_bucket ON validator_stats_buckets (bucket);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop validator_stats_buckets table';
+DROP TABLE IF EXISTS validator_stats_buckets;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add balance_checkpoint column';
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS balance_checkpoint INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop balance_checkpoint column';
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS balance_checkpoint;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add attestation components status columns to validator_stats_status';
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS attestation_components_exported BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS attestation_components_export_ms INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove attestation components status columns from validator_stats_status';
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS attestation_components_exported;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS attestation_components_export_ms;
+-- +goose StatementEnd
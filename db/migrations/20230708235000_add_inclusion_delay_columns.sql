@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add inclusion delay columns';
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS avg_inclusion_delay DOUBLE PRECISION;
+ALTER TABLE validator_stats ADD COLUMN IF NOT EXISTS max_inclusion_delay INT;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS inclusion_delay_exported BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS inclusion_delay_export_ms INT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - remove inclusion delay columns';
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS avg_inclusion_delay;
+ALTER TABLE validator_stats DROP COLUMN IF EXISTS max_inclusion_delay;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS inclusion_delay_exported;
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS inclusion_delay_export_ms;
+-- +goose StatementEnd
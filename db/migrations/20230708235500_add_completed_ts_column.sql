@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query - add completed_ts column';
+ALTER TABLE validator_stats_status ADD COLUMN IF NOT EXISTS completed_ts TIMESTAMP WITHOUT TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query - drop completed_ts column';
+ALTER TABLE validator_stats_status DROP COLUMN IF EXISTS completed_ts;
+-- +goose StatementEnd
@@ -1,24 +1,44 @@
 package db
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"eth2-exporter/cache"
 	"eth2-exporter/metrics"
 	"eth2-exporter/price"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
+	"math/rand"
+	"net/http"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	itypes "github.com/gobitfly/eth-rewards/types"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func WriteValidatorStatisticsForDay(day uint64) error {
@@ -35,116 +55,206 @@ func WriteValidatorStatisticsForDay(day uint64) error {
 		return err
 	}
 
-	logger.Infof("getting exported state for day %v", day)
-	start := time.Now()
+	completed, err := writeValidatorStatisticsForDay(day, exportStart)
+	if err != nil {
+		return err
+	}
+	if completed {
+		// the hooks run once the day lock is released, a slow hook or webhook doesn't hold up other exporters of the day
+		runStatisticsExportHooks(day, exportStart)
+	}
+	return nil
+}
 
-	type Exported struct {
-		Status              bool `db:"status"`
-		FailedAttestations  bool `db:"failed_attestations_exported"`
-		SyncDuties          bool `db:"sync_duties_exported"`
-		WithdrawalsDeposits bool `db:"withdrawals_deposits_exported"`
-		Balance             bool `db:"balance_exported"`
-		ClRewards           bool `db:"cl_rewards_exported"`
-		ElRewards           bool `db:"el_rewards_exported"`
-		TotalPerformance    bool `db:"total_performance_exported"`
-		BlockStats          bool `db:"block_stats_exported"`
+// writeValidatorStatisticsForDay runs the outstanding sub-exports of day while holding the lock of the day and reports whether
+// this run marked the day as completely exported
+func writeValidatorStatisticsForDay(day uint64, exportStart time.Time) (bool, error) {
+	// the day is locked as a whole in addition to its sub-exports, so concurrent runs of the same day don't race on its completion.
+	// Depending on the export lock mode a second run waits, fails with ErrStatisticsExportLocked or is skipped with
+	// ErrStatisticsExportSkipped.
+	unlock, err := lockStatisticsExport(day, "validator_stats")
+	if err != nil {
+		return false, err
 	}
-	exported := Exported{}
+	defer unlock()
 
-	err := ReaderDb.Get(&exported, `
-		SELECT 
-			status,
-			failed_attestations_exported,
-			sync_duties_exported,
-			withdrawals_deposits_exported,
-			balance_exported,
-			cl_rewards_exported,
-			el_rewards_exported,
-			total_performance_exported,
-			block_stats_exported
-		FROM validator_stats_status 
-		WHERE day = $1;
-		`, day)
+	logger.Infof("getting exported state for day %v", day)
+	start := time.Now()
 
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("error retrieving exported state: %v", err)
+	exported, err := GetValidatorStatsStatus(day)
+	if err != nil {
+		return false, err
 	}
 	logger.Infof("getting exported state took %v", time.Since(start))
 
-	if exported.FailedAttestations && exported.SyncDuties && exported.WithdrawalsDeposits && exported.Balance && exported.ClRewards && exported.ElRewards && exported.TotalPerformance && exported.BlockStats && exported.Status {
+	if exported.AllSubExportsExported() && exported.Status {
 		logger.Infof("Skipping day %v as it is already exported", day)
-		return nil
+		return false, nil
 	}
 
-	if exported.FailedAttestations {
-		logger.Infof("Skipping failed attestations")
-	} else if err := WriteValidatorFailedAttestationsStatisticsForDay(day); err != nil {
-		return err
+	subExports := []struct {
+		name     string
+		exported bool
+		export   func(day uint64) error
+	}{
+		{"failed attestations", exported.FailedAttestations, WriteValidatorFailedAttestationsStatisticsForDay},
+		{"attestation components", exported.AttestationComponents || !utils.Config.Statistics.AttestationComponents, WriteValidatorAttestationComponentsForDay},
+		{"attestation inclusion distance", exported.InclusionDistance, WriteValidatorAttestationInclusionStats},
+		{"attestation inclusion delay", exported.InclusionDelay, WriteValidatorInclusionDelayForDay},
+		{"sync duties", exported.SyncDuties, WriteValidatorSyncDutiesForDay},
+		{"withdrawals / deposits", exported.WithdrawalsDeposits, WriteValidatorDepositWithdrawals},
+		{"withdrawal addresses", exported.WithdrawalAddresses, WriteValidatorWithdrawalAddressStatsForDay},
+		{"block stats", exported.BlockStats, WriteValidatorBlockStats},
+		{"slashing events", exported.SlashingEvents, WriteValidatorSlashingEventsForDay},
+		{"balances", exported.Balance, WriteValidatorBalances},
+		{"cl rewards", exported.ClRewards, WriteValidatorClIcome},
+		{"slashing income", exported.SlashingIncome, WriteValidatorSlashingIncome},
+		{"el rewards", exported.ElRewards, WriteValidatorElIcome},
+		{"relay stats", exported.RelayStats, WriteRelayStatsForDay},
+		{"total performance", exported.TotalPerformance, WriteValidatorTotalPerformance},
+		{"network stats", exported.NetworkStats, WriteNetworkStatsForDay},
 	}
-
-	if exported.SyncDuties {
-		logger.Infof("Skipping sync duties")
-	} else if err := WriteValidatorSyncDutiesForDay(day); err != nil {
-		return err
+	failed := &SubExportsError{Day: day}
+	for _, subExport := range subExports {
+		if subExport.exported {
+			logger.Infof("Skipping %v", subExport.name)
+			continue
+		}
+		if err := subExport.export(day); err != nil {
+			if !utils.Config.Statistics.BestEffort {
+				return false, err
+			}
+			// in best effort mode the independent sub-exports still make progress, the dependent ones fail as well
+			logger.Errorf("error exporting %v of day %v, continuing with the next sub-export: %v", subExport.name, day, err)
+			failed.Failures = append(failed.Failures, SubExportFailure{Export: subExport.name, Err: err})
+		}
 	}
-
-	if exported.WithdrawalsDeposits {
-		logger.Infof("Skipping withdrawals / deposits")
-	} else if err := WriteValidatorDepositWithdrawals(day); err != nil {
-		return err
+	if len(failed.Failures) > 0 {
+		return false, failed
 	}
 
-	if exported.BlockStats {
-		logger.Infof("Skipping block stats")
-	} else if err := WriteValidatorBlockStats(day); err != nil {
-		return err
+	if utils.Config.Statistics.DryRun {
+		logger.Infof("dry run of day %v completed, took %v", day, time.Since(exportStart))
+		return false, nil
 	}
 
-	if exported.Balance {
-		logger.Infof("Skipping balances")
-	} else if err := WriteValidatorBalances(day); err != nil {
-		return err
+	completed, err := WriteValidatorStatsExported(day)
+	if err != nil {
+		return false, err
 	}
 
-	if exported.ClRewards {
-		logger.Infof("Skipping cl rewards")
-	} else if err := WriteValidatorClIcome(day); err != nil {
-		return err
+	logger.Infof("statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	if completed {
+		// the finalized statistics replace the preview of the day
+		if _, err := WriterDb.Exec("DELETE FROM validator_stats_preview WHERE day <= $1", day); err != nil {
+			logger.Errorf("error deleting statistics preview up to day %v: %v", day, err)
+		}
 	}
+	return completed, nil
+}
 
-	if exported.ElRewards {
-		logger.Infof("Skipping el rewards")
-	} else if err := WriteValidatorElIcome(day); err != nil {
-		return err
+const validatorStatsStatusColumns = `
+	day,
+	status,
+	failed_attestations_exported,
+	sync_duties_exported,
+	withdrawals_deposits_exported,
+	balance_exported,
+	cl_rewards_exported,
+	el_rewards_exported,
+	total_performance_exported,
+	block_stats_exported,
+	inclusion_distance_exported,
+	inclusion_delay_exported,
+	slashing_events_exported,
+	slashing_income_exported,
+	relay_stats_exported,
+	withdrawal_address_stats_exported,
+	network_stats_exported,
+	attestation_components_exported
+`
+
+// GetValidatorStatsStatus returns the export state of the statistics of the day. A day without a row in the validator_stats_status
+// table has not been exported at all, all of its flags are false.
+func GetValidatorStatsStatus(day uint64) (*types.ValidatorStatsStatus, error) {
+	status := &types.ValidatorStatsStatus{}
+	err := ReaderDb.Get(status, fmt.Sprintf("SELECT %s FROM validator_stats_status WHERE day = $1", validatorStatsStatusColumns), day)
+	if err == sql.ErrNoRows {
+		return &types.ValidatorStatsStatus{Day: day}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving exported state of day %v: %w", day, err)
 	}
+	return status, nil
+}
 
-	if exported.TotalPerformance {
-		logger.Infof("Skipping total performance")
-	} else if err := WriteValidatorTotalPerformance(day); err != nil {
-		return err
+// GetValidatorStatsStatusRange returns the export state of all days between fromDay and toDay (inclusive) that have a row in the
+// validator_stats_status table, ordered by day
+func GetValidatorStatsStatusRange(fromDay, toDay uint64) ([]*types.ValidatorStatsStatus, error) {
+	status := []*types.ValidatorStatsStatus{}
+	err := ReaderDb.Select(&status, fmt.Sprintf("SELECT %s FROM validator_stats_status WHERE day BETWEEN $1 AND $2 ORDER BY day", validatorStatsStatusColumns), fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving exported state of days %v - %v: %w", fromDay, toDay, err)
 	}
+	return status, nil
+}
 
-	if err := WriteValidatorStatsExported(day); err != nil {
-		return err
+// SubExportFailure is a sub-export that failed in best effort mode
+type SubExportFailure struct {
+	Export string
+	Err    error
+}
+
+// SubExportsError is returned by WriteValidatorStatisticsForDay in best effort mode if any of the sub-exports of the day failed.
+// The other sub-exports have been run and marked as exported. errors.Is matches the errors of all failed sub-exports.
+type SubExportsError struct {
+	Day      uint64
+	Failures []SubExportFailure
+}
+
+func (e *SubExportsError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		failures[i] = fmt.Sprintf("%v: %v", failure.Export, failure.Err)
 	}
+	return fmt.Sprintf("%v sub-exports of day %v failed: %v", len(e.Failures), e.Day, strings.Join(failures, "; "))
+}
 
-	logger.Infof("statistics export of day %v completed, took %v", day, time.Since(exportStart))
-	return nil
+func (e *SubExportsError) Is(target error) bool {
+	for _, failure := range e.Failures {
+		if errors.Is(failure.Err, target) {
+			return true
+		}
+	}
+	return false
 }
 
-func WriteValidatorStatsExported(day uint64) error {
+// WriteValidatorStatsExported marks the day as exported if all its sub-exports completed, completed reports if it has been marked.
+// The status row of the day is locked for the check, so sub-exports marking their column at the same time wait for it and a
+// reset of the day can't interleave with the completion.
+func WriteValidatorStatsExported(day uint64) (bool, error) {
+	if validatorFilterActive() {
+		logger.Infof("validator filter: not marking the export of day %v as completed", day)
+		return false, nil
+	}
+
 	tx, err := WriterDb.Beginx()
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer tx.Rollback()
 
 	start := time.Now()
 
+	_, err = tx.Exec("SELECT day FROM validator_stats_status WHERE day = $1 FOR UPDATE", day)
+	if err != nil {
+		return false, fmt.Errorf("error locking validator_stats_status of day %v: %w", day, err)
+	}
+
 	logger.Infof("marking day export as completed in the status table")
-	_, err = tx.Exec(`
+	res, err := tx.Exec(`
 		UPDATE validator_stats_status
-		SET status = true
+		SET status = true, completed_ts = NOW()
 		WHERE day=$1
 		AND failed_attestations_exported = true
 		AND sync_duties_exported = true
@@ -153,28 +263,243 @@ func WriteValidatorStatsExported(day uint64) error {
 		AND cl_rewards_exported = true
 		AND el_rewards_exported = true
 		AND total_performance_exported = true
-		AND block_stats_exported = true;
+		AND block_stats_exported = true
+		AND inclusion_distance_exported = true
+		AND inclusion_delay_exported = true
+		AND slashing_events_exported = true
+		AND slashing_income_exported = true
+		AND relay_stats_exported = true
+		AND withdrawal_address_stats_exported = true
+		AND network_stats_exported = true;
 		`, day)
 	if err != nil {
-		return err
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
 	}
 	logger.Infof("marking completed, took %v", time.Since(start))
 
 	err = tx.Commit()
 	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		metrics.StatsLastExportedDay.Set(float64(day))
+	}
+	return rows > 0, nil
+}
+
+// GetValidatorStatsTableMissingDays returns the sorted list of finalized days up to (and including) uptoDay whose
+// validator statistics have not been fully exported, either because the validator_stats_status row is missing or its status is false
+func GetValidatorStatsTableMissingDays(uptoDay uint64) ([]uint64, error) {
+	var lastFinalizedEpoch sql.NullInt64
+	err := ReaderDb.Get(&lastFinalizedEpoch, "SELECT MAX(epoch) FROM epochs WHERE finalized")
+	if err != nil {
+		return nil, fmt.Errorf("error getting last finalized epoch: %w", err)
+	}
+	if !lastFinalizedEpoch.Valid {
+		return []uint64{}, nil
+	}
+	finalizedDays := utils.EpochToDay(uint64(lastFinalizedEpoch.Int64) + 1)
+	if finalizedDays == 0 {
+		return []uint64{}, nil
+	}
+	if uptoDay > finalizedDays-1 {
+		uptoDay = finalizedDays - 1
+	}
+
+	exportedDays := []uint64{}
+	err = ReaderDb.Select(&exportedDays, "SELECT day FROM validator_stats_status WHERE status AND day <= $1", uptoDay)
+	if err != nil {
+		return nil, fmt.Errorf("error getting exported statistics days up to day %v: %w", uptoDay, err)
+	}
+	return missingStatisticsDays(uptoDay, exportedDays), nil
+}
+
+// missingStatisticsDays returns all days from 0 to uptoDay (inclusive) that are not contained in exportedDays
+func missingStatisticsDays(uptoDay uint64, exportedDays []uint64) []uint64 {
+	exported := make(map[uint64]bool, len(exportedDays))
+	for _, day := range exportedDays {
+		exported[day] = true
+	}
+	missing := []uint64{}
+	for day := uint64(0); day <= uptoDay; day++ {
+		if !exported[day] {
+			missing = append(missing, day)
+		}
+	}
+	return missing
+}
+
+// DeleteValidatorStatsForDay removes all statistics of the given day within a single transaction and resets its export flags so
+// the day can be re-exported from scratch. The genesis deposits stored at day -1 are never touched and a day that has never been
+// exported is skipped with a warning. The running totals of the following day are derived from the deleted day: if they have
+// already been exported, resetFollowingTotals has to be set to mark them as not exported, otherwise the deletion is refused.
+func DeleteValidatorStatsForDay(day uint64, resetFollowingTotals bool) error {
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	return deleteValidatorStatsForDay(tx, day, resetFollowingTotals)
+}
+
+type statisticsTx interface {
+	Get(dest interface{}, query string, args ...interface{}) error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Commit() error
+	Rollback() error
+}
+
+// totalPerformanceExportedForUpdate returns whether the day has a status row and whether its total performance is exported. The status
+// row is locked until the transaction ends, so the checked state can't change before the deletion is committed.
+func totalPerformanceExportedForUpdate(tx statisticsTx, day uint64) (found, exported bool, err error) {
+	err = tx.Get(&exported, "SELECT total_performance_exported FROM validator_stats_status WHERE day = $1 FOR UPDATE", day)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("error checking exported state of day %v: %w", day, err)
+	}
+	return true, exported, nil
+}
+
+func deleteValidatorStatsForDay(tx statisticsTx, day uint64, resetFollowingTotals bool) error {
+	defer tx.Rollback()
+
+	start := time.Now()
+
+	found, _, err := totalPerformanceExportedForUpdate(tx, day)
+	if err != nil {
+		return err
+	}
+	if !found {
+		logger.Warnf("not deleting statistics of day %v as it has never been exported", day)
+		return nil
+	}
+
+	_, followingTotalsExported, err := totalPerformanceExportedForUpdate(tx, day+1)
+	if err != nil {
+		return err
+	}
+	if followingTotalsExported && !resetFollowingTotals {
+		return fmt.Errorf("refusing to delete statistics of day %v as the total performance of day %v has already been exported, reset it as well", day, day+1)
+	}
+
+	logger.Infof("deleting validator_stats of day %v", day)
+	// day is unsigned and compared for equality so the genesis deposits at day -1 can't be removed by accident
+	_, err = tx.Exec("DELETE FROM validator_stats WHERE day = $1", day)
+	if err != nil {
+		return fmt.Errorf("error deleting validator_stats of day %v: %w", day, err)
+	}
+
+	_, err = tx.Exec("DELETE FROM validator_slashing_events WHERE day = $1", day)
+	if err != nil {
+		return fmt.Errorf("error deleting validator_slashing_events of day %v: %w", day, err)
+	}
+
+	_, err = tx.Exec("DELETE FROM relay_stats WHERE day = $1", day)
+	if err != nil {
+		return fmt.Errorf("error deleting relay_stats of day %v: %w", day, err)
+	}
+
+	_, err = tx.Exec("DELETE FROM validator_withdrawal_address_stats WHERE day = $1", day)
+	if err != nil {
+		return fmt.Errorf("error deleting validator_withdrawal_address_stats of day %v: %w", day, err)
+	}
+
+	_, err = tx.Exec("DELETE FROM network_stats_per_day WHERE day = $1", day)
+	if err != nil {
+		return fmt.Errorf("error deleting network_stats_per_day of day %v: %w", day, err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE validator_stats_status
+		SET
+			status = false,
+			failed_attestations_exported = false,
+			sync_duties_exported = false,
+			withdrawals_deposits_exported = false,
+			balance_exported = false,
+			cl_rewards_exported = false,
+			el_rewards_exported = false,
+			total_performance_exported = false,
+			block_stats_exported = false,
+			inclusion_distance_exported = false,
+			inclusion_delay_exported = false,
+			slashing_events_exported = false,
+			slashing_income_exported = false,
+			relay_stats_exported = false,
+			withdrawal_address_stats_exported = false,
+			network_stats_exported = false,
+			attestation_components_exported = false,
+			balance_checkpoint = NULL
+		WHERE day = $1;
+		`, day)
+	if err != nil {
+		return fmt.Errorf("error resetting validator_stats_status of day %v: %w", day, err)
+	}
+
+	if followingTotalsExported {
+		logger.Infof("resetting total performance of day %v", day+1)
+		_, err = tx.Exec("UPDATE validator_stats_status SET status = false, total_performance_exported = false WHERE day = $1", day+1)
+		if err != nil {
+			return fmt.Errorf("error resetting validator_stats_status of day %v: %w", day+1, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
 		return err
 	}
+
+	logger.Infof("deleting statistics of day %v completed, took %v", day, time.Since(start))
 	return nil
 }
 
+// WriteValidatorTotalPerformance rolls up the cumulative reward totals of the day and rebuilds the validator_performance
+// table from them, see WriteValidatorCumulativeTotals and WriteValidatorPerformanceTable.
 func WriteValidatorTotalPerformance(day uint64) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*10))
-	defer cancel()
 	exportStart := time.Now()
 	defer func() {
 		metrics.TaskDuration.WithLabelValues("db_update_validator_total_performance_stats").Observe(time.Since(exportStart).Seconds())
 	}()
 
+	unlock, err := lockStatisticsExport(day, "total_performance")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := WriteValidatorCumulativeTotals(day); err != nil {
+		return err
+	}
+
+	if err := WriteValidatorPerformanceTable(day); err != nil {
+		return err
+	}
+
+	if utils.Config.Statistics.DryRun {
+		return nil
+	}
+
+	if err := markColumnExported(day, "total_performance_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("total performance statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// WriteValidatorCumulativeTotals sets the cl_rewards_gwei_total, cl_proposer_rewards_gwei_total, el_rewards_wei_total and
+// mev_rewards_wei_total columns of the day by adding the rewards of the day to the totals of the previous day. It requires
+// the cl and el rewards of the day and of the previous day to be exported and does not touch the validator_performance table.
+func WriteValidatorCumulativeTotals(day uint64) error {
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_cumulative_totals_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
 	if err := checkIfDayIsFinalized(day); err != nil {
 		return err
 	}
@@ -201,1113 +526,5191 @@ func WriteValidatorTotalPerformance(day uint64) error {
 	`, day)
 
 	if err != nil {
-		return fmt.Errorf("error retrieving required data: %v", err)
-	} else if !exported.CurrentCLRewards || !exported.CurrentElRewards || !exported.LastClRewards || !exported.LastElRewards {
-		return fmt.Errorf("missing required export: cur cl rewards: %v, cur el rewards: %v, last cl rewards: %v, last el rewards: %v", !exported.CurrentCLRewards, !exported.CurrentElRewards, !exported.LastClRewards, !exported.LastElRewards)
+		err = fmt.Errorf("error retrieving required data: %v", err)
+	} else {
+		err = missingDependencyError(day, map[string]bool{
+			"cur_cl_rewards_exported":  exported.CurrentCLRewards,
+			"cur_el_rewards_exported":  exported.CurrentElRewards,
+			"last_cl_rewards_exported": exported.LastClRewards,
+			"last_el_rewards_exported": exported.LastElRewards,
+		})
 	}
-	logger.Infof("validating completed, took %v", time.Since(start))
-
-	start = time.Now()
-
-	logger.Infof("exporting total income stats")
-	maxValidatorIndex, err := GetTotalValidatorsCount()
-	if err != nil {
+	if err = ignoreInDryRun(err); err != nil {
 		return err
 	}
-	g, gCtx := errgroup.WithContext(ctx)
-	batchSize := 1000
-	for b := 0; b <= int(maxValidatorIndex); b += batchSize {
-		start := b
-		end := b + batchSize
-		if int(maxValidatorIndex) < end {
-			end = int(maxValidatorIndex)
-		}
-		g.Go(func() error {
-			select {
-			case <-gCtx.Done():
-				return nil
-			default:
-			}
-			_, err = WriterDb.Exec(`
-				INSERT INTO validator_stats (validatorindex, day, cl_rewards_gwei_total, cl_proposer_rewards_gwei_total, el_rewards_wei_total, mev_rewards_wei_total) (
-					SELECT 
-						vs1.validatorindex, 
-						vs1.day, 
-						COALESCE(vs1.cl_rewards_gwei, 0) + COALESCE(vs2.cl_rewards_gwei_total, 0) AS cl_rewards_gwei_total_new, 
-						COALESCE(vs1.cl_proposer_rewards_gwei, 0) + COALESCE(vs2.cl_proposer_rewards_gwei_total, 0) AS cl_proposer_rewards_gwei_total_new, 
-						COALESCE(vs1.el_rewards_wei, 0) + COALESCE(vs2.el_rewards_wei_total, 0) AS el_rewards_wei_total_new, 
-						COALESCE(vs1.mev_rewards_wei, 0) + COALESCE(vs2.mev_rewards_wei_total, 0) AS mev_rewards_wei_total_new 
-					FROM validator_stats vs1 LEFT JOIN validator_stats vs2 ON vs2.day = vs1.day - 1 AND vs2.validatorindex = vs1.validatorindex WHERE vs1.day = $1 AND vs1.validatorindex >= $2 AND vs1.validatorindex < $3
-				) ON CONFLICT (validatorindex, day) DO UPDATE SET 
-					cl_rewards_gwei_total = excluded.cl_rewards_gwei_total,
-					cl_proposer_rewards_gwei_total = excluded.cl_proposer_rewards_gwei_total,
-					el_rewards_wei_total = excluded.el_rewards_wei_total,
-					mev_rewards_wei_total = excluded.mev_rewards_wei_total;
-				`, day, start, end)
-			if err != nil {
-				return err
-			}
+	logger.Infof("validating completed, took %v", time.Since(start))
 
-			_, err = WriterDb.Exec(`insert into validator_performance (
-				validatorindex,
-				balance,
-				performance1d,
-				performance7d,
-				performance31d,
-				performance365d,
-
-				rank7d,
-
-				cl_performance_1d,
-				cl_performance_7d,
-				cl_performance_31d,
-				cl_performance_365d,
-				cl_performance_total,
-				cl_proposer_performance_total,
-
-				el_performance_1d,
-				el_performance_7d,
-				el_performance_31d,
-				el_performance_365d,
-				el_performance_total,
-
-				mev_performance_1d,
-				mev_performance_7d,
-				mev_performance_31d,
-				mev_performance_365d,
-				mev_performance_total
-				) (
-					select 
-					vs_now.validatorindex, 
-						COALESCE(vs_now.end_balance, 0) as balance, 
-						0 as performance1d, 
-						0 as performance7d, 
-						0 as performance31d, 
-						0 as performance365d, 
-						0 as rank7d,
-
-						coalesce(vs_now.cl_rewards_gwei_total, 0) - coalesce(vs_1d.cl_rewards_gwei_total, 0) as cl_performance_1d, 
-						coalesce(vs_now.cl_rewards_gwei_total, 0) - coalesce(vs_7d.cl_rewards_gwei_total, 0) as cl_performance_7d, 
-						coalesce(vs_now.cl_rewards_gwei_total, 0) - coalesce(vs_31d.cl_rewards_gwei_total, 0) as cl_performance_31d, 
-						coalesce(vs_now.cl_rewards_gwei_total, 0) - coalesce(vs_365d.cl_rewards_gwei_total, 0) as cl_performance_365d,
-						coalesce(vs_now.cl_rewards_gwei_total, 0) as cl_performance_total, 
-						coalesce(vs_now.cl_proposer_rewards_gwei_total, 0) as cl_proposer_performance_total, 
-						
-						coalesce(vs_now.el_rewards_wei_total, 0) - coalesce(vs_1d.el_rewards_wei_total, 0) as el_performance_1d, 
-						coalesce(vs_now.el_rewards_wei_total, 0) - coalesce(vs_7d.el_rewards_wei_total, 0) as el_performance_7d, 
-						coalesce(vs_now.el_rewards_wei_total, 0) - coalesce(vs_31d.el_rewards_wei_total, 0) as el_performance_31d, 
-						coalesce(vs_now.el_rewards_wei_total, 0) - coalesce(vs_365d.el_rewards_wei_total, 0) as el_performance_365d,
-						coalesce(vs_now.el_rewards_wei_total, 0) as el_performance_total, 
-						
-						coalesce(vs_now.mev_rewards_wei_total, 0) - coalesce(vs_1d.mev_rewards_wei_total, 0) as mev_performance_1d, 
-						coalesce(vs_now.mev_rewards_wei_total, 0) - coalesce(vs_7d.mev_rewards_wei_total, 0) as mev_performance_7d, 
-						coalesce(vs_now.mev_rewards_wei_total, 0) - coalesce(vs_31d.mev_rewards_wei_total, 0) as mev_performance_31d, 
-						coalesce(vs_now.mev_rewards_wei_total, 0) - coalesce(vs_365d.mev_rewards_wei_total, 0) as mev_performance_365d,
-						coalesce(vs_now.mev_rewards_wei_total, 0) as mev_performance_total
-					from validator_stats vs_now
-					left join validator_stats vs_1d on vs_1d.validatorindex = vs_now.validatorindex and vs_1d.day = $2
-					left join validator_stats vs_7d on vs_7d.validatorindex = vs_now.validatorindex and vs_7d.day = $3
-					left join validator_stats vs_31d on vs_31d.validatorindex = vs_now.validatorindex and vs_31d.day = $4
-					left join validator_stats vs_365d on vs_365d.validatorindex = vs_now.validatorindex and vs_365d.day = $5
-					where vs_now.day = $1 AND vs_now.validatorindex >= $6 AND vs_now.validatorindex < $7
-				) 
-				on conflict (validatorindex) do update set 
-					balance = excluded.balance, 
-					performance1d=excluded.performance1d,
-					performance7d=excluded.performance7d,
-					performance31d=excluded.performance31d,
-					performance365d=excluded.performance365d,
-
-					rank7d=excluded.rank7d,
-
-					cl_performance_1d=excluded.cl_performance_1d,
-					cl_performance_7d=excluded.cl_performance_7d,
-					cl_performance_31d=excluded.cl_performance_31d,
-					cl_performance_365d=excluded.cl_performance_365d,
-					cl_performance_total=excluded.cl_performance_total,
-					cl_proposer_performance_total=excluded.cl_proposer_performance_total,
-
-					el_performance_1d=excluded.el_performance_1d,
-					el_performance_7d=excluded.el_performance_7d,
-					el_performance_31d=excluded.el_performance_31d,
-					el_performance_365d=excluded.el_performance_365d,
-					el_performance_total=excluded.el_performance_total,
-
-					mev_performance_1d=excluded.mev_performance_1d,
-					mev_performance_7d=excluded.mev_performance_7d,
-					mev_performance_31d=excluded.mev_performance_31d,
-					mev_performance_365d=excluded.mev_performance_365d,
-					mev_performance_total=excluded.mev_performance_total
-			;`, day, int64(day)-1, int64(day)-7, int64(day)-31, int64(day)-365, start, end)
-
-			logger.Infof("populate validator_performance table done for batch %v", start)
-			return err
-		})
-	}
-	if err = g.Wait(); err != nil {
-		logrus.Error(err)
-		return err
+	if skipDryRunWrites("cumulative_totals", day, -1) {
+		return nil
 	}
-	logger.Infof("export completed, took %v", time.Since(start))
-	start = time.Now()
-	logger.Infof("populate validator_performance rank7d")
 
-	_, err = WriterDb.Exec(`
-		insert into validator_performance (                                                                                                 
-			validatorindex,          
-			balance,             
-			performance1d,
-			performance7d,
-			performance31d,  
-			performance365d,                                                                                             
-			rank7d
-		) (
-			select validatorindex, 0, 0, 0, 0, 0, row_number() over(order by validator_performance.cl_performance_7d desc) as rank7d from validator_performance
-		) 
-			on conflict (validatorindex) do update set 
-				rank7d=excluded.rank7d
-		;
-		`)
+	start = time.Now()
+	logger.Infof("exporting total income stats")
+	err = forEachValidatorBatch(day, "cumulative_totals", func(start, end int) error {
+		return writeCumulativeTotalsBatch(WriterDb, day, start, end)
+	})
 	if err != nil {
 		return err
 	}
-
 	logger.Infof("export completed, took %v", time.Since(start))
 
-	if err = markColumnExported(day, "total_performance_exported"); err != nil {
-		return err
-	}
-
-	logger.Infof("total performance statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	logger.Infof("cumulative totals export of day %v completed, took %v", day, time.Since(exportStart))
 	return nil
 }
 
-func WriteValidatorBlockStats(day uint64) error {
+// WriteValidatorPerformanceTable rebuilds the validator_performance table including the rank7d column from the cumulative
+// total columns of the day and of the days 1, 7, 31 and 365 days before. It expects WriteValidatorCumulativeTotals to have
+// run for those days and can be used to rebuild the table without recomputing the totals.
+func WriteValidatorPerformanceTable(day uint64) error {
 	exportStart := time.Now()
 	defer func() {
-		metrics.TaskDuration.WithLabelValues("db_update_validator_block_stats").Observe(time.Since(exportStart).Seconds())
+		metrics.TaskDuration.WithLabelValues("db_update_validator_performance_table").Observe(time.Since(exportStart).Seconds())
 	}()
 
 	if err := checkIfDayIsFinalized(day); err != nil {
 		return err
 	}
 
-	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
-
-	tx, err := WriterDb.Beginx()
-	if err != nil {
-		return err
+	if skipDryRunWrites("total_performance", day, -1) {
+		return nil
 	}
-	defer tx.Rollback()
 
 	start := time.Now()
-
-	logger.Infof("exporting proposed_blocks, missed_blocks and orphaned_blocks statistics")
-	_, err = tx.Exec(`
-		insert into validator_stats (validatorindex, day, proposed_blocks, missed_blocks, orphaned_blocks) 
-		(
-			select proposer, $3, sum(case when status = '1' then 1 else 0 end), sum(case when status = '2' then 1 else 0 end), sum(case when status = '3' then 1 else 0 end)
-			from blocks
-			where epoch >= $1 and epoch <= $2
-			group by proposer
-		) 
-		on conflict (validatorindex, day) do update set proposed_blocks = excluded.proposed_blocks, missed_blocks = excluded.missed_blocks, orphaned_blocks = excluded.orphaned_blocks;`,
-		firstEpoch, lastEpoch, day)
+	logger.Infof("populate validator_performance table")
+	err := forEachValidatorBatch(day, "total_performance", func(start, end int) error {
+		return writePerformanceTableBatch(WriterDb, day, start, end)
+	})
 	if err != nil {
 		return err
 	}
 	logger.Infof("export completed, took %v", time.Since(start))
 
-	start = time.Now()
-	logger.Infof("exporting attester_slashings and proposer_slashings statistics")
-	_, err = tx.Exec(`
-		insert into validator_stats (validatorindex, day, attester_slashings, proposer_slashings) 
-		(
-			select proposer, $3, sum(attesterslashingscount), sum(proposerslashingscount)
-			from blocks
-			where epoch >= $1 and epoch <= $2 and status = '1'
-			group by proposer
-		) 
-		on conflict (validatorindex, day) do update set attester_slashings = excluded.attester_slashings, proposer_slashings = excluded.proposer_slashings;`,
-		firstEpoch, lastEpoch, day)
-	if err != nil {
-		return err
+	if computeRanks() {
+		start = time.Now()
+		logger.Infof("populate validator_performance rank7d")
+		updated, err := writePerformanceRank7d(WriterDb)
+		if err != nil {
+			return err
+		}
+		observeRowsExported("rank7d", int(updated))
+		logger.Infof("export completed, updated the rank of %v validators, took %v", updated, time.Since(start))
+	} else {
+		logger.Infof("skipping validator_performance rank7d as computing ranks is disabled")
 	}
 
-	if err = tx.Commit(); err != nil {
-		return err
+	logger.Infof("validator_performance export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// skipDryRunWrites reports whether the writes of a sub-export have to be skipped because the exporter runs in dry run mode,
+// the planned writes are logged instead. rows is the number of validator rows that would be written, -1 if they are computed by the database.
+func skipDryRunWrites(export string, day uint64, rows int) bool {
+	if !utils.Config.Statistics.DryRun {
+		return false
 	}
-	logger.Infof("export completed, took %v", time.Since(start))
+	if rows < 0 {
+		logger.Infof("dry run: skipping %v export of day %v, rows are computed by the database", export, day)
+	} else {
+		logger.Infof("dry run: skipping %v export of day %v, would write %v rows", export, day, rows)
+	}
+	return true
+}
 
-	if err = markColumnExported(day, "block_stats_exported"); err != nil {
-		return err
+// noDataForDay reports whether the data a sub-export read from Bigtable for a day is empty. This is legitimate e.g. on small
+// testnets or days without sync committee duties, the sub-export then skips its writes but still marks its column as exported
+// so the day can complete.
+func noDataForDay(export string, day uint64, rows int) bool {
+	if rows > 0 {
+		return false
 	}
+	logger.Infof("no %v data for day %v", export, day)
+	return true
+}
 
-	logger.Infof("block statistics export of day %v completed, took %v", day, time.Since(exportStart))
-	return nil
+// ignoreInDryRun logs and drops a failed check of the required exports in dry run mode, as they are usually
+// written by the preceding sub-exports of the same run which did not write anything. The same applies with a validator filter,
+// which never marks anything as exported.
+func ignoreInDryRun(err error) error {
+	if err != nil && (utils.Config.Statistics.DryRun || validatorFilterActive()) {
+		logger.Warnf("dry run: ignoring %v", err)
+		return nil
+	}
+	return err
 }
 
-func WriteValidatorElIcome(day uint64) error {
-	exportStart := time.Now()
-	defer func() {
-		metrics.TaskDuration.WithLabelValues("db_update_validator_el_income_stats").Observe(time.Since(exportStart).Seconds())
-	}()
+// validatorFilterActive reports whether only the statistics of the validators of the validator filter are exported. The day is not
+// completely exported then, so like in dry run mode neither its export state nor the network wide statistics are written.
+func validatorFilterActive() bool {
+	return len(utils.Config.Statistics.ValidatorFilter) > 0
+}
 
-	if err := checkIfDayIsFinalized(day); err != nil {
-		return err
+// exportedValidators returns the validators the sub-exports read from Bigtable, an empty slice (all validators) unless
+// a validator filter is configured to export only a subset of the validators for testing
+func exportedValidators() []uint64 {
+	if len(utils.Config.Statistics.ValidatorFilter) == 0 {
+		return []uint64{}
 	}
+	return utils.Config.Statistics.ValidatorFilter
+}
 
-	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
-
-	tx, err := WriterDb.Beginx()
-	if err != nil {
-		return err
+// isExportedValidator reports whether the statistics of a validator are exported, which is true for all validators unless a validator filter is configured
+func isExportedValidator(validatorIndex uint64) bool {
+	if len(utils.Config.Statistics.ValidatorFilter) == 0 {
+		return true
 	}
-	defer tx.Rollback()
+	for _, v := range utils.Config.Statistics.ValidatorFilter {
+		if v == validatorIndex {
+			return true
+		}
+	}
+	return false
+}
 
-	start := time.Now()
+// validatorFilterCondition returns the sql condition (starting with AND) and its argument that restricts a query to the filtered
+// validators, column is the validator index column and argIndex the position of the argument in the query. Both are empty if no
+// validator filter is configured.
+func validatorFilterCondition(column string, argIndex int) (string, []interface{}) {
+	if len(utils.Config.Statistics.ValidatorFilter) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(" AND %s = ANY($%d)", column, argIndex), []interface{}{pq.Array(utils.Config.Statistics.ValidatorFilter)}
+}
 
-	logger.Infof("exporting mev & el rewards")
+const defaultExportDeadline = time.Minute * 10
 
-	type Container struct {
-		Slot            uint64 `db:"slot"`
-		ExecBlockNumber uint64 `db:"exec_block_number"`
-		Proposer        uint64 `db:"proposer"`
-		TxFeeReward     *big.Int
-		MevReward       *big.Int
+// exportDeadline returns the configured time limit of a sub-export. Exports exceeding it return an error and are not marked as exported.
+func exportDeadline(export string) time.Duration {
+	if deadline := utils.Config.Statistics.ExportDeadlines[export]; deadline > 0 {
+		return deadline
 	}
+	return defaultExportDeadline
+}
 
-	blocks := make([]*Container, 0)
-	blocksMap := make(map[uint64]*Container)
+// maxQueryParameters is the maximum number of parameters postgres accepts in a single statement
+const maxQueryParameters = 65535
 
-	err = tx.Select(&blocks, "SELECT slot, exec_block_number, proposer FROM blocks WHERE epoch >= $1 AND epoch <= $2 AND exec_block_number > 0 AND status = '1'", firstEpoch, lastEpoch)
-	if err != nil {
-		return fmt.Errorf("error retrieving blocks data: %v", err)
+// insertBatchSize returns the configured number of rows per insert statement of a sub-export, defaultSize if none is configured.
+// Batch sizes that would exceed the parameter limit with numArgs parameters per row are clamped.
+func insertBatchSize(export string, configured, defaultSize, numArgs int) int {
+	batchSize := configured
+	if batchSize <= 0 {
+		batchSize = defaultSize
+	}
+	if maxBatchSize := maxQueryParameters / numArgs; batchSize > maxBatchSize {
+		logger.Warnf("%v batch size of %v exceeds the parameter limit with %v parameters per row, clamping it to %v", export, batchSize, numArgs, maxBatchSize)
+		batchSize = maxBatchSize
 	}
+	return batchSize
+}
 
-	numbers := make([]uint64, 0, len(blocks))
+// forEachValidatorBatch calls fn concurrently for consecutive validator index ranges [start, end) covering all validators.
+// If a validator filter is configured fn is called once per filtered validator instead.
+func forEachValidatorBatch(day uint64, export string, fn func(start, end int) error) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline(export)))
+	defer cancel()
 
-	for _, b := range blocks {
-		numbers = append(numbers, b.ExecBlockNumber)
-		blocksMap[b.ExecBlockNumber] = b
+	if len(utils.Config.Statistics.ValidatorFilter) > 0 {
+		for _, validatorIndex := range utils.Config.Statistics.ValidatorFilter {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(int(validatorIndex), int(validatorIndex)+1); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	blocksData, err := BigtableClient.GetBlocksIndexedMultiple(numbers, uint64(len(numbers)))
+	maxValidatorIndex, err := GetTotalValidatorsCount()
 	if err != nil {
-		return fmt.Errorf("error in GetBlocksIndexedMultiple: %v", err)
+		return err
 	}
+	return runValidatorBatches(ctx, day, export, maxValidatorIndex, fn)
+}
 
-	relaysData, err := GetRelayDataForIndexedBlocks(blocksData)
+// runValidatorBatches calls fn concurrently for batches of validators up to maxValidatorIndex, 1000 validators per batch unless
+// configured otherwise. It returns an error if ctx is done before all batches ran, so callers never mark a partially
+// exported column as exported.
+func runValidatorBatches(ctx context.Context, day uint64, export string, maxValidatorIndex uint64, fn func(start, end int) error) error {
+	progress := newExportProgress(export)
+	g, gCtx := errgroup.WithContext(ctx)
+	// the batches copy rows within the database, so they are not bound by the parameter limit
+	batchSize := utils.Config.Statistics.TotalPerformanceBatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	skipped := int64(0)
+	for b := 0; b <= int(maxValidatorIndex); b += batchSize {
+		start := b
+		end := b + batchSize
+		if int(maxValidatorIndex) < end {
+			end = int(maxValidatorIndex)
+		}
+		progress.batchScheduled()
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				atomic.AddInt64(&skipped, 1)
+				return gCtx.Err()
+			default:
+			}
+			if err := fn(start, end); err != nil {
+				return err
+			}
+			progress.batchCompleted()
+			logger.Infof("%v batch %v done", export, start)
+			return nil
+		})
+	}
+	err := g.Wait()
+	// batches that already passed the cancellation check still complete, a cancellation only fails the export if batches were skipped
+	if skipped := atomic.LoadInt64(&skipped); skipped > 0 && ctx.Err() != nil {
+		err = fmt.Errorf("%v export of day %v cancelled, %v batches skipped: %w", export, day, skipped, ctx.Err())
+	}
 	if err != nil {
-		return fmt.Errorf("error in GetRelayDataForIndexedBlocks: %v", err)
+		logrus.Error(err)
+		return err
 	}
+	return nil
+}
 
-	proposerRewards := make(map[uint64]*Container)
-	for _, b := range blocksData {
-		proposer := blocksMap[b.Number].Proposer
+func writeCumulativeTotalsBatch(db sqlx.Execer, day uint64, start, end int) error {
+	_, err := db.Exec(`
+		INSERT INTO validator_stats (validatorindex, day, cl_rewards_gwei_total, cl_proposer_rewards_gwei_total, el_rewards_wei_total, mev_rewards_wei_total) (
+			SELECT 
+				vs1.validatorindex, 
+				vs1.day, 
+				COALESCE(vs1.cl_rewards_gwei, 0) + COALESCE(vs2.cl_rewards_gwei_total, 0) AS cl_rewards_gwei_total_new, 
+				COALESCE(vs1.cl_proposer_rewards_gwei, 0) + COALESCE(vs2.cl_proposer_rewards_gwei_total, 0) AS cl_proposer_rewards_gwei_total_new, 
+				COALESCE(vs1.el_rewards_wei, 0) + COALESCE(vs2.el_rewards_wei_total, 0) AS el_rewards_wei_total_new, 
+				COALESCE(vs1.mev_rewards_wei, 0) + COALESCE(vs2.mev_rewards_wei_total, 0) AS mev_rewards_wei_total_new 
+			FROM validator_stats vs1 LEFT JOIN validator_stats vs2 ON vs2.day = vs1.day - 1 AND vs2.validatorindex = vs1.validatorindex WHERE vs1.day = $1 AND vs1.validatorindex >= $2 AND vs1.validatorindex < $3
+		) ON CONFLICT (validatorindex, day) DO UPDATE SET 
+			cl_rewards_gwei_total = excluded.cl_rewards_gwei_total,
+			cl_proposer_rewards_gwei_total = excluded.cl_proposer_rewards_gwei_total,
+			el_rewards_wei_total = excluded.el_rewards_wei_total,
+			mev_rewards_wei_total = excluded.mev_rewards_wei_total;
+		`, day, start, end)
+	return err
+}
 
-		if proposerRewards[proposer] == nil {
-			proposerRewards[proposer] = &Container{
-				MevReward:   big.NewInt(0),
-				TxFeeReward: big.NewInt(0),
-			}
-		}
+func writePerformanceTableBatch(db sqlx.Execer, day uint64, start, end int) error {
+	_, err := db.Exec(`insert into validator_performance (
+		validatorindex,
+		balance,
+		performance1d,
+		performance7d,
+		performance31d,
+		performance365d,
+
+		rank7d,
+
+		cl_performance_1d,
+		cl_performance_7d,
+		cl_performance_31d,
+		cl_performance_365d,
+		cl_performance_total,
+		cl_proposer_performance_total,
+
+		el_performance_1d,
+		el_performance_7d,
+		el_performance_31d,
+		el_performance_365d,
+		el_performance_total,
+
+		mev_performance_1d,
+		mev_performance_7d,
+		mev_performance_31d,
+		mev_performance_365d,
+		mev_performance_total
+		) (
+			select 
+			vs_now.validatorindex, 
+				COALESCE(vs_now.end_balance, 0) as balance, 
+				0 as performance1d, 
+				0 as performance7d, 
+				0 as performance31d, 
+				0 as performance365d, 
+				0 as rank7d,
+
+				coalesce(vs_now.cl_rewards_gwei_total, 0) - coalesce(vs_1d.cl_rewards_gwei_total, 0) as cl_performance_1d, 
+				coalesce(vs_now.cl_rewards_gwei_total, 0) - coalesce(vs_7d.cl_rewards_gwei_total, 0) as cl_performance_7d, 
+				coalesce(vs_now.cl_rewards_gwei_total, 0) - coalesce(vs_31d.cl_rewards_gwei_total, 0) as cl_performance_31d, 
+				coalesce(vs_now.cl_rewards_gwei_total, 0) - coalesce(vs_365d.cl_rewards_gwei_total, 0) as cl_performance_365d,
+				coalesce(vs_now.cl_rewards_gwei_total, 0) as cl_performance_total, 
+				coalesce(vs_now.cl_proposer_rewards_gwei_total, 0) as cl_proposer_performance_total, 
+				
+				coalesce(vs_now.el_rewards_wei_total, 0) - coalesce(vs_1d.el_rewards_wei_total, 0) as el_performance_1d, 
+				coalesce(vs_now.el_rewards_wei_total, 0) - coalesce(vs_7d.el_rewards_wei_total, 0) as el_performance_7d, 
+				coalesce(vs_now.el_rewards_wei_total, 0) - coalesce(vs_31d.el_rewards_wei_total, 0) as el_performance_31d, 
+				coalesce(vs_now.el_rewards_wei_total, 0) - coalesce(vs_365d.el_rewards_wei_total, 0) as el_performance_365d,
+				coalesce(vs_now.el_rewards_wei_total, 0) as el_performance_total, 
+				
+				coalesce(vs_now.mev_rewards_wei_total, 0) - coalesce(vs_1d.mev_rewards_wei_total, 0) as mev_performance_1d, 
+				coalesce(vs_now.mev_rewards_wei_total, 0) - coalesce(vs_7d.mev_rewards_wei_total, 0) as mev_performance_7d, 
+				coalesce(vs_now.mev_rewards_wei_total, 0) - coalesce(vs_31d.mev_rewards_wei_total, 0) as mev_performance_31d, 
+				coalesce(vs_now.mev_rewards_wei_total, 0) - coalesce(vs_365d.mev_rewards_wei_total, 0) as mev_performance_365d,
+				coalesce(vs_now.mev_rewards_wei_total, 0) as mev_performance_total
+			from validator_stats vs_now
+			left join validator_stats vs_1d on vs_1d.validatorindex = vs_now.validatorindex and vs_1d.day = $2
+			left join validator_stats vs_7d on vs_7d.validatorindex = vs_now.validatorindex and vs_7d.day = $3
+			left join validator_stats vs_31d on vs_31d.validatorindex = vs_now.validatorindex and vs_31d.day = $4
+			left join validator_stats vs_365d on vs_365d.validatorindex = vs_now.validatorindex and vs_365d.day = $5
+			where vs_now.day = $1 AND vs_now.validatorindex >= $6 AND vs_now.validatorindex < $7
+		) 
+		on conflict (validatorindex) do update set 
+			balance = excluded.balance, 
+			performance1d=excluded.performance1d,
+			performance7d=excluded.performance7d,
+			performance31d=excluded.performance31d,
+			performance365d=excluded.performance365d,
+
+			cl_performance_1d=excluded.cl_performance_1d,
+			cl_performance_7d=excluded.cl_performance_7d,
+			cl_performance_31d=excluded.cl_performance_31d,
+			cl_performance_365d=excluded.cl_performance_365d,
+			cl_performance_total=excluded.cl_performance_total,
+			cl_proposer_performance_total=excluded.cl_proposer_performance_total,
+
+			el_performance_1d=excluded.el_performance_1d,
+			el_performance_7d=excluded.el_performance_7d,
+			el_performance_31d=excluded.el_performance_31d,
+			el_performance_365d=excluded.el_performance_365d,
+			el_performance_total=excluded.el_performance_total,
+
+			mev_performance_1d=excluded.mev_performance_1d,
+			mev_performance_7d=excluded.mev_performance_7d,
+			mev_performance_31d=excluded.mev_performance_31d,
+			mev_performance_365d=excluded.mev_performance_365d,
+			mev_performance_total=excluded.mev_performance_total
+	;`, day, int64(day)-1, int64(day)-7, int64(day)-31, int64(day)-365, start, end)
+	return err
+}
 
-		txFeeReward := new(big.Int).SetBytes(b.TxReward)
-		proposerRewards[proposer].TxFeeReward = new(big.Int).Add(txFeeReward, proposerRewards[proposer].TxFeeReward)
+// computeRanks reports whether the rank7d column of the validator_performance table is updated, ranking all validators requires
+// sorting the whole table, which operators that don't show the ranks can disable. Ranks are computed unless disabled in the config.
+func computeRanks() bool {
+	return utils.Config.Statistics.ComputeRanks == nil || *utils.Config.Statistics.ComputeRanks
+}
 
-		mevReward, ok := relaysData[common.BytesToHash(b.Hash)]
+// writePerformanceRank7d ranks all validators by their cl performance of the last 7 days. Only the rows whose rank changed
+// are written, as most ranks stay the same from one day to the next. Ties are ranked by validator index to keep the ranks
+// stable. It returns the number of updated rows.
+func writePerformanceRank7d(db sqlx.Execer) (int64, error) {
+	res, err := db.Exec(`
+		update validator_performance set
+			rank7d = ranked.rank7d
+		from (
+			select validatorindex, row_number() over(order by cl_performance_7d desc, validatorindex) as rank7d from validator_performance
+		) ranked
+		where validator_performance.validatorindex = ranked.validatorindex and validator_performance.rank7d is distinct from ranked.rank7d
+		;
+		`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
 
-		if ok {
-			proposerRewards[proposer].MevReward = new(big.Int).Add(mevReward.MevBribe.BigInt(), proposerRewards[proposer].MevReward)
-		} else {
-			proposerRewards[proposer].MevReward = new(big.Int).Add(txFeeReward, proposerRewards[proposer].MevReward)
-		}
+// ErrValidatorPerformanceNotFound is returned if no validator_performance row exists for a validator yet (e.g. it has just been activated)
+var ErrValidatorPerformanceNotFound = errors.New("validator performance not found")
+
+const validatorPerformanceColumns = `
+	validatorindex,
+	COALESCE(balance, 0) AS balance,
+	COALESCE(performance1d, 0) AS performance1d,
+	COALESCE(performance7d, 0) AS performance7d,
+	COALESCE(performance31d, 0) AS performance31d,
+	COALESCE(performance365d, 0) AS performance365d,
+	COALESCE(rank7d, 0) AS rank7d,
+	COALESCE(cl_performance_1d, 0) AS cl_performance_1d,
+	COALESCE(cl_performance_7d, 0) AS cl_performance_7d,
+	COALESCE(cl_performance_31d, 0) AS cl_performance_31d,
+	COALESCE(cl_performance_365d, 0) AS cl_performance_365d,
+	COALESCE(cl_performance_total, 0) AS cl_performance_total,
+	COALESCE(cl_proposer_performance_total, 0) AS cl_proposer_performance_total,
+	COALESCE(el_performance_1d, 0) AS el_performance_1d,
+	COALESCE(el_performance_7d, 0) AS el_performance_7d,
+	COALESCE(el_performance_31d, 0) AS el_performance_31d,
+	COALESCE(el_performance_365d, 0) AS el_performance_365d,
+	COALESCE(el_performance_total, 0) AS el_performance_total,
+	COALESCE(mev_performance_1d, 0) AS mev_performance_1d,
+	COALESCE(mev_performance_7d, 0) AS mev_performance_7d,
+	COALESCE(mev_performance_31d, 0) AS mev_performance_31d,
+	COALESCE(mev_performance_365d, 0) AS mev_performance_365d,
+	COALESCE(mev_performance_total, 0) AS mev_performance_total`
+
+// GetValidatorPerformance returns every column of the validator_performance row of a validator as populated by WriteValidatorTotalPerformance
+func GetValidatorPerformance(validatorIndex uint64) (*types.ValidatorPerformance, error) {
+	performance := &types.ValidatorPerformance{}
+	err := ReaderDb.Get(performance, fmt.Sprintf("SELECT %s FROM validator_performance WHERE validatorindex = $1", validatorPerformanceColumns), validatorIndex)
+	if err == sql.ErrNoRows {
+		return nil, ErrValidatorPerformanceNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving validator performance of validator %v: %w", validatorIndex, err)
 	}
-	logrus.Infof("retrieved mev / el rewards data for %v proposer", len(proposerRewards))
+	return performance, nil
+}
 
-	if len(proposerRewards) > 0 {
-		numArgs := 4
-		valueStrings := make([]string, 0, len(proposerRewards))
-		valueArgs := make([]interface{}, 0, len(proposerRewards)*numArgs)
-		i := 0
-		for proposer, rewards := range proposerRewards {
+// GetValidatorPerformanceMultiple returns the validator_performance rows of the given validators ordered by index. Validators without a row are omitted.
+func GetValidatorPerformanceMultiple(validatorIndices []uint64) ([]*types.ValidatorPerformance, error) {
+	performance := []*types.ValidatorPerformance{}
+	if len(validatorIndices) == 0 {
+		return performance, nil
+	}
 
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3, i*numArgs+4))
-			valueArgs = append(valueArgs, proposer)
-			valueArgs = append(valueArgs, day)
-			valueArgs = append(valueArgs, rewards.TxFeeReward.String())
-			valueArgs = append(valueArgs, rewards.MevReward.String())
+	err := ReaderDb.Select(&performance, fmt.Sprintf("SELECT %s FROM validator_performance WHERE validatorindex = ANY($1) ORDER BY validatorindex", validatorPerformanceColumns), pq.Array(validatorIndices))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving validator performance of %v validators: %w", len(validatorIndices), err)
+	}
+	return performance, nil
+}
 
-			i++
-		}
-		stmt := fmt.Sprintf(`
-				INSERT INTO validator_stats (validatorindex, day, el_rewards_wei, mev_rewards_wei) VALUES
-				%s
-				ON CONFLICT(validatorindex, day) DO UPDATE SET el_rewards_wei = excluded.el_rewards_wei, mev_rewards_wei = excluded.mev_rewards_wei;`,
-			strings.Join(valueStrings, ","))
-		_, err = tx.Exec(stmt, valueArgs...)
-		if err != nil {
-			return err
-		}
+// GetAggregatedValidatorPerformance returns the sum of the validator_performance rows of the given validators. Validators without a
+// row are not counted. The result is cached per set of validators until the next epoch.
+func GetAggregatedValidatorPerformance(validatorIndices []uint64) (types.AggregatedValidatorPerformance, error) {
+	performance := types.AggregatedValidatorPerformance{}
+	if len(validatorIndices) == 0 {
+		return performance, nil
 	}
 
-	if err = tx.Commit(); err != nil {
-		return err
+	validatorIndices = utils.SortedUniqueUint64(validatorIndices)
+	cacheDur := time.Second * time.Duration(utils.Config.Chain.Config.SecondsPerSlot*utils.Config.Chain.Config.SlotsPerEpoch+10) // updates every epoch, keep 10sec longer
+	cacheKey := aggregatedValidatorPerformanceCacheKey(validatorIndices)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, cacheDur, types.AggregatedValidatorPerformance{}); err == nil {
+		return cached.(types.AggregatedValidatorPerformance), nil
 	}
-	logger.Infof("export completed, took %v", time.Since(start))
 
-	if err = markColumnExported(day, "el_rewards_exported"); err != nil {
-		return err
+	err := ReaderDb.Get(&performance, `
+		SELECT
+			COUNT(*) AS validator_count,
+			COALESCE(SUM(balance), 0) AS balance,
+			COALESCE(SUM(performance1d), 0) AS performance1d,
+			COALESCE(SUM(performance7d), 0) AS performance7d,
+			COALESCE(SUM(performance31d), 0) AS performance31d,
+			COALESCE(SUM(performance365d), 0) AS performance365d,
+			COALESCE(SUM(cl_performance_1d), 0) AS cl_performance_1d,
+			COALESCE(SUM(cl_performance_7d), 0) AS cl_performance_7d,
+			COALESCE(SUM(cl_performance_31d), 0) AS cl_performance_31d,
+			COALESCE(SUM(cl_performance_365d), 0) AS cl_performance_365d,
+			COALESCE(SUM(cl_performance_total), 0) AS cl_performance_total,
+			COALESCE(SUM(el_performance_1d), 0) AS el_performance_1d,
+			COALESCE(SUM(el_performance_7d), 0) AS el_performance_7d,
+			COALESCE(SUM(el_performance_31d), 0) AS el_performance_31d,
+			COALESCE(SUM(el_performance_365d), 0) AS el_performance_365d,
+			COALESCE(SUM(el_performance_total), 0) AS el_performance_total,
+			COALESCE(SUM(mev_performance_1d), 0) AS mev_performance_1d,
+			COALESCE(SUM(mev_performance_7d), 0) AS mev_performance_7d,
+			COALESCE(SUM(mev_performance_31d), 0) AS mev_performance_31d,
+			COALESCE(SUM(mev_performance_365d), 0) AS mev_performance_365d,
+			COALESCE(SUM(mev_performance_total), 0) AS mev_performance_total
+		FROM validator_performance
+		WHERE validatorindex = ANY($1)`, pq.Array(validatorIndices))
+	if err != nil {
+		return performance, fmt.Errorf("error retrieving aggregated validator performance of %v validators: %w", len(validatorIndices), err)
 	}
 
-	logger.Infof("el rewards statistics export of day %v completed, took %v", day, time.Since(exportStart))
-	return nil
+	go func(performance types.AggregatedValidatorPerformance) {
+		err := cache.TieredCache.Set(cacheKey, performance, cacheDur)
+		if err != nil {
+			utils.LogError(err, fmt.Errorf("error setting tieredCache for GetAggregatedValidatorPerformance with key %v", cacheKey), 0)
+		}
+	}(performance)
+
+	return performance, nil
 }
 
-func WriteValidatorClIcome(day uint64) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*10))
-	defer cancel()
-	exportStart := time.Now()
-	defer func() {
-		metrics.TaskDuration.WithLabelValues("db_update_validator_cl_income_stats").Observe(time.Since(exportStart).Seconds())
-	}()
+// aggregatedValidatorPerformanceCacheKey returns the cache key of the aggregated performance of the sorted and deduplicated validator indices
+func aggregatedValidatorPerformanceCacheKey(validatorIndices []uint64) string {
+	validatorIndicesStr := make([]string, len(validatorIndices))
+	for i, v := range validatorIndices {
+		validatorIndicesStr[i] = fmt.Sprintf("%d", v)
+	}
+	return fmt.Sprintf("%d:aggregatedValidatorPerformance:%s", utils.Config.Chain.Config.DepositChainID, strings.Join(validatorIndicesStr, ","))
+}
 
-	if err := checkIfDayIsFinalized(day); err != nil {
-		return err
+// validatorStatsColumns selects all columns of validator_stats as scanned into types.ValidatorStatsRow
+const validatorStatsColumns = `
+	validatorindex,
+	day,
+	COALESCE(start_balance, 0) AS start_balance,
+	COALESCE(end_balance, 0) AS end_balance,
+	COALESCE(min_balance, 0) AS min_balance,
+	COALESCE(max_balance, 0) AS max_balance,
+	COALESCE(start_effective_balance, 0) AS start_effective_balance,
+	COALESCE(end_effective_balance, 0) AS end_effective_balance,
+	COALESCE(min_effective_balance, 0) AS min_effective_balance,
+	COALESCE(max_effective_balance, 0) AS max_effective_balance,
+	COALESCE(missed_attestations, 0) AS missed_attestations,
+	COALESCE(orphaned_attestations, 0) AS orphaned_attestations,
+	COALESCE(missed_source, 0) AS missed_source,
+	COALESCE(missed_target, 0) AS missed_target,
+	COALESCE(missed_head, 0) AS missed_head,
+	COALESCE(avg_inclusion_distance, 0) AS avg_inclusion_distance,
+	COALESCE(max_inclusion_distance, 0) AS max_inclusion_distance,
+	COALESCE(participated_sync, 0) AS participated_sync,
+	COALESCE(missed_sync, 0) AS missed_sync,
+	COALESCE(orphaned_sync, 0) AS orphaned_sync,
+	COALESCE(sync_participation_rate, 0) AS sync_participation_rate,
+	COALESCE(proposed_blocks, 0) AS proposed_blocks,
+	COALESCE(missed_blocks, 0) AS missed_blocks,
+	COALESCE(orphaned_blocks, 0) AS orphaned_blocks,
+	COALESCE(late_blocks, 0) AS late_blocks,
+	COALESCE(mev_blocks, 0) AS mev_blocks,
+	COALESCE(local_blocks, 0) AS local_blocks,
+	COALESCE(attester_slashings, 0) AS attester_slashings,
+	COALESCE(proposer_slashings, 0) AS proposer_slashings,
+	COALESCE(deposits, 0) AS deposits,
+	COALESCE(deposits_amount, 0) AS deposits_amount,
+	COALESCE(withdrawals, 0) AS withdrawals,
+	COALESCE(withdrawals_amount, 0) AS withdrawals_amount,
+	COALESCE(cl_rewards_gwei, 0) AS cl_rewards_gwei,
+	COALESCE(cl_rewards_gwei_total, 0) AS cl_rewards_gwei_total,
+	COALESCE(cl_rewards_gwei_net, 0) AS cl_rewards_gwei_net,
+	COALESCE(cl_proposer_rewards_gwei, 0) AS cl_proposer_rewards_gwei,
+	COALESCE(cl_proposer_rewards_gwei_total, 0) AS cl_proposer_rewards_gwei_total,
+	COALESCE(cl_proposer_attestation_inclusion_rewards_gwei, 0) AS cl_proposer_attestation_inclusion_rewards_gwei,
+	COALESCE(cl_proposer_sync_inclusion_rewards_gwei, 0) AS cl_proposer_sync_inclusion_rewards_gwei,
+	COALESCE(cl_proposer_slashing_inclusion_rewards_gwei, 0) AS cl_proposer_slashing_inclusion_rewards_gwei,
+	COALESCE(slashing_reward_gwei, 0) AS slashing_reward_gwei,
+	COALESCE(el_rewards_wei, 0) AS el_rewards_wei,
+	COALESCE(el_rewards_wei_total, 0) AS el_rewards_wei_total,
+	COALESCE(mev_rewards_wei, 0) AS mev_rewards_wei,
+	COALESCE(mev_rewards_wei_total, 0) AS mev_rewards_wei_total,
+	COALESCE(had_relay_data, false) AS had_relay_data,
+	COALESCE(attestation_effectiveness, 0) AS attestation_effectiveness
+`
+
+// GetValidatorStatsForDay returns the validator_stats rows of the given validators for a single day, ordered by validator index.
+// Validators without a row for the day are left out. The result is cached per set of validators and day until the next epoch.
+func GetValidatorStatsForDay(validatorIndices []uint64, day uint64) ([]types.ValidatorStatsRow, error) {
+	rows := []types.ValidatorStatsRow{}
+	if len(validatorIndices) == 0 {
+		return rows, nil
 	}
 
-	start := time.Now()
-	logger.Infof("validating if required data has been exported for cl rewards")
-	type Exported struct {
-		LastBalanceExported                bool `db:"last_balance_exported"`
-		CurrentBalanceExported             bool `db:"cur_balance_exported"`
-		CurrentWithdrawalsDepositsExported bool `db:"cur_withdrawals_deposits_exported"`
+	validatorIndices = utils.SortedUniqueUint64(validatorIndices)
+	cacheDur := time.Second * time.Duration(utils.Config.Chain.Config.SecondsPerSlot*utils.Config.Chain.Config.SlotsPerEpoch+10) // updates every epoch, keep 10sec longer
+	cacheKey := validatorStatsForDayCacheKey(validatorIndices, day)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, cacheDur, []types.ValidatorStatsRow{}); err == nil {
+		return cached.([]types.ValidatorStatsRow), nil
 	}
-	exported := Exported{}
-	err := ReaderDb.Get(&exported, `
-		SELECT last.balance_exported as last_balance_exported, cur.balance_exported as cur_balance_exported, cur.withdrawals_deposits_exported as cur_withdrawals_deposits_exported
-		FROM validator_stats_status cur
-		INNER JOIN validator_stats_status last 
-				ON last.day = GREATEST(cur.day - 1, 0)
-		WHERE cur.day = $1;
-	`, day)
 
+	err := ReaderDb.Select(&rows, fmt.Sprintf(`
+		SELECT %s
+		FROM validator_stats
+		WHERE validatorindex = ANY($1) AND day = $2
+		ORDER BY validatorindex`, validatorStatsColumns), pq.Array(validatorIndices), day)
 	if err != nil {
-		return fmt.Errorf("error retrieving required data: %v", err)
-	} else if !exported.CurrentBalanceExported || !exported.CurrentWithdrawalsDepositsExported || !exported.LastBalanceExported {
-		return fmt.Errorf("missing required export: cur balance: %v, cur withdrwals/deposits: %v, last balance: %v", !exported.CurrentBalanceExported, !exported.CurrentWithdrawalsDepositsExported, !exported.LastBalanceExported)
+		return nil, fmt.Errorf("error retrieving validator stats of %v validators for day %v: %w", len(validatorIndices), day, err)
 	}
-	logger.Infof("validating took %v", time.Since(start))
 
-	start = time.Now()
-	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+	go func(rows []types.ValidatorStatsRow) {
+		err := cache.TieredCache.Set(cacheKey, rows, cacheDur)
+		if err != nil {
+			utils.LogError(err, fmt.Errorf("error setting tieredCache for GetValidatorStatsForDay with key %v", cacheKey), 0)
+		}
+	}(rows)
 
-	logger.Infof("exporting cl_rewards_wei statistics")
-	incomeStats, err := BigtableClient.GetAggregatedValidatorIncomeDetailsHistory([]uint64{}, firstEpoch, lastEpoch)
+	return rows, nil
+}
+
+// GetValidatorAttestationEffectiveness returns the daily attestation effectiveness of a validator between fromDay and toDay
+// (inclusive), ordered by day. Days on which the validator had no attestation duties are left out.
+func GetValidatorAttestationEffectiveness(validatorIndex, fromDay, toDay uint64) ([]types.ValidatorAttestationEffectiveness, error) {
+	effectiveness := []types.ValidatorAttestationEffectiveness{}
+	err := ReaderDb.Select(&effectiveness, `
+		SELECT day, attestation_effectiveness
+		FROM validator_stats
+		WHERE validatorindex = $1 AND day >= $2 AND day <= $3 AND attestation_effectiveness IS NOT NULL
+		ORDER BY day`, validatorIndex, fromDay, toDay)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error retrieving attestation effectiveness of validator %v for days %v - %v: %w", validatorIndex, fromDay, toDay, err)
 	}
-	logrus.Infof("getting cl income done in %v, now we export them to the db", time.Since(start))
-	start = time.Now()
-
-	maxValidatorIndex := uint64(0)
+	return effectiveness, nil
+}
 
-	for validator := range incomeStats {
-		if validator > maxValidatorIndex {
-			maxValidatorIndex = validator
-		}
+// StreamValidatorStatsForDay calls cb with the validator_stats rows of all validators of a day in chunks of chunkSize rows,
+// ordered by validator index. Only one chunk is kept in memory at a time, so it can be used to export days with millions of
+// validators. Iteration stops at the first error returned by cb.
+func StreamValidatorStatsForDay(day uint64, chunkSize int, cb func(rows []types.ValidatorStatsRow) error) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("invalid chunk size %v", chunkSize)
 	}
-	maxValidatorIndex++
-
-	g, gCtx := errgroup.WithContext(ctx)
 
-	numArgs := 3
-	batchSize := 100 // max parameters: 65535 / 3, but it's faster in smaller batches
-	for b := 0; b <= int(maxValidatorIndex); b += batchSize {
-		start := b
-		end := b + batchSize
-		if int(maxValidatorIndex) < end {
-			end = int(maxValidatorIndex)
+	// keyset pagination by validator index, offsets get slower the further the export progresses
+	nextValidatorIndex := int64(0)
+	for {
+		rows := make([]types.ValidatorStatsRow, 0, chunkSize)
+		err := ReaderDb.Select(&rows, fmt.Sprintf(`
+			SELECT %s
+			FROM validator_stats
+			WHERE day = $1 AND validatorindex >= $2
+			ORDER BY validatorindex
+			LIMIT $3`, validatorStatsColumns), day, nextValidatorIndex, chunkSize)
+		if err != nil {
+			return fmt.Errorf("error retrieving validator stats of day %v from validator %v: %w", day, nextValidatorIndex, err)
 		}
-
-		logrus.Info(start, end)
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*numArgs)
-		for i := start; i < end; i++ {
-			clProposerRewards := uint64(0)
-
-			if incomeStats[uint64(i)] != nil {
-				clProposerRewards = incomeStats[uint64(i)].ProposerAttestationInclusionReward + incomeStats[uint64(i)].ProposerSlashingInclusionReward + incomeStats[uint64(i)].ProposerSyncInclusionReward
-			}
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", (i-start)*numArgs+1, (i-start)*numArgs+2, (i-start)*numArgs+3))
-			valueArgs = append(valueArgs, i)
-			valueArgs = append(valueArgs, day)
-			valueArgs = append(valueArgs, clProposerRewards)
+		if len(rows) == 0 {
+			return nil
 		}
-		stmt := fmt.Sprintf(`
-		insert into validator_stats (validatorindex, day, cl_proposer_rewards_gwei) VALUES
-		%s
-		on conflict (validatorindex, day) do update set cl_proposer_rewards_gwei = excluded.cl_proposer_rewards_gwei;`,
-			strings.Join(valueStrings, ","))
-
-		g.Go(func() error {
-			select {
-			case <-gCtx.Done():
-				return nil
-			default:
-			}
-			_, err := WriterDb.Exec(stmt, valueArgs...)
-			if err != nil {
-				return err
-			}
-			logrus.Infof("saving validator proposer rewards gwei batch %v completed", start)
-			stmt = `
-				INSERT INTO validator_stats (validatorindex, day, cl_rewards_gwei) 
-				(
-					SELECT cur.validatorindex, cur.day, COALESCE(cur.end_balance, 0) - COALESCE(last.end_balance, 0) + COALESCE(cur.withdrawals_amount, 0) - COALESCE(cur.deposits_amount, 0) AS cl_rewards_gwei
-					FROM validator_stats cur
-					INNER JOIN validator_stats last 
-						ON cur.validatorindex = last.validatorindex AND last.day = GREATEST(cur.day - 1, 0)
-					WHERE cur.day = $1 AND cur.validatorindex >= $2 AND cur.validatorindex < $3
-				)
-				ON CONFLICT (validatorindex, day) DO
-					UPDATE SET cl_rewards_gwei = excluded.cl_rewards_gwei;`
-			if day == 0 {
-				stmt = `
-					INSERT INTO validator_stats (validatorindex, day, cl_rewards_gwei) 
-					(
-						SELECT cur.validatorindex, cur.day, COALESCE(cur.end_balance, 0) - COALESCE(cur.start_balance,0) + COALESCE(cur.withdrawals_amount, 0) - COALESCE(cur.deposits_amount, 0) AS cl_rewards_gwei
-						FROM validator_stats cur
-						WHERE cur.day = $1 AND cur.validatorindex >= $2 AND cur.validatorindex < $3
-					)
-					ON CONFLICT (validatorindex, day) DO
-						UPDATE SET cl_rewards_gwei = excluded.cl_rewards_gwei;`
-			}
-			_, err = WriterDb.Exec(stmt, day, start, end)
-			if err != nil {
-				return err
-			}
-			logrus.Infof("saving validator cl rewards gwei batch %v completed", start)
+		if err := cb(rows); err != nil {
+			return err
+		}
+		if len(rows) < chunkSize {
 			return nil
-		})
-	}
-
-	if err = g.Wait(); err != nil {
-		logrus.Error(err)
-		return err
+		}
+		nextValidatorIndex = int64(rows[len(rows)-1].ValidatorIndex) + 1
 	}
+}
 
-	logger.Infof("export completed, took %v", time.Since(start))
+// validatorStatsParquetChunkSize is the number of validator_stats rows written as one row group by ExportValidatorStatsParquet
+const validatorStatsParquetChunkSize = 10000
+
+// validatorStatsParquetRow is the schema of the parquet export of validator_stats. Every column of types.ValidatorStatsRow is
+// exported under its validator_stats column name, balances and cl rewards are in gwei. The el and mev rewards in wei can exceed an
+// int64, so they are exported as decimal strings. Columns that have not been exported for the day are zero.
+type validatorStatsParquetRow struct {
+	ValidatorIndex                        int64   `parquet:"name=validatorindex, type=INT64"`
+	Day                                   int64   `parquet:"name=day, type=INT64"`
+	StartBalance                          int64   `parquet:"name=start_balance, type=INT64"`
+	EndBalance                            int64   `parquet:"name=end_balance, type=INT64"`
+	MinBalance                            int64   `parquet:"name=min_balance, type=INT64"`
+	MaxBalance                            int64   `parquet:"name=max_balance, type=INT64"`
+	StartEffectiveBalance                 int64   `parquet:"name=start_effective_balance, type=INT64"`
+	EndEffectiveBalance                   int64   `parquet:"name=end_effective_balance, type=INT64"`
+	MinEffectiveBalance                   int64   `parquet:"name=min_effective_balance, type=INT64"`
+	MaxEffectiveBalance                   int64   `parquet:"name=max_effective_balance, type=INT64"`
+	MissedAttestations                    int64   `parquet:"name=missed_attestations, type=INT64"`
+	OrphanedAttestations                  int64   `parquet:"name=orphaned_attestations, type=INT64"`
+	MissedSource                          int64   `parquet:"name=missed_source, type=INT64"`
+	MissedTarget                          int64   `parquet:"name=missed_target, type=INT64"`
+	MissedHead                            int64   `parquet:"name=missed_head, type=INT64"`
+	AvgInclusionDistance                  float64 `parquet:"name=avg_inclusion_distance, type=DOUBLE"`
+	MaxInclusionDistance                  int64   `parquet:"name=max_inclusion_distance, type=INT64"`
+	ParticipatedSync                      int64   `parquet:"name=participated_sync, type=INT64"`
+	MissedSync                            int64   `parquet:"name=missed_sync, type=INT64"`
+	OrphanedSync                          int64   `parquet:"name=orphaned_sync, type=INT64"`
+	SyncParticipationRate                 float64 `parquet:"name=sync_participation_rate, type=DOUBLE"`
+	ProposedBlocks                        int64   `parquet:"name=proposed_blocks, type=INT64"`
+	MissedBlocks                          int64   `parquet:"name=missed_blocks, type=INT64"`
+	OrphanedBlocks                        int64   `parquet:"name=orphaned_blocks, type=INT64"`
+	LateBlocks                            int64   `parquet:"name=late_blocks, type=INT64"`
+	MevBlocks                             int64   `parquet:"name=mev_blocks, type=INT64"`
+	LocalBlocks                           int64   `parquet:"name=local_blocks, type=INT64"`
+	AttesterSlashings                     int64   `parquet:"name=attester_slashings, type=INT64"`
+	ProposerSlashings                     int64   `parquet:"name=proposer_slashings, type=INT64"`
+	Deposits                              int64   `parquet:"name=deposits, type=INT64"`
+	DepositsAmount                        int64   `parquet:"name=deposits_amount, type=INT64"`
+	Withdrawals                           int64   `parquet:"name=withdrawals, type=INT64"`
+	WithdrawalsAmount                     int64   `parquet:"name=withdrawals_amount, type=INT64"`
+	ClRewards                             int64   `parquet:"name=cl_rewards_gwei, type=INT64"`
+	ClRewardsTotal                        int64   `parquet:"name=cl_rewards_gwei_total, type=INT64"`
+	ClRewardsNet                          int64   `parquet:"name=cl_rewards_gwei_net, type=INT64"`
+	ClProposerRewards                     int64   `parquet:"name=cl_proposer_rewards_gwei, type=INT64"`
+	ClProposerRewardsTotal                int64   `parquet:"name=cl_proposer_rewards_gwei_total, type=INT64"`
+	ClProposerAttestationInclusionRewards int64   `parquet:"name=cl_proposer_attestation_inclusion_rewards_gwei, type=INT64"`
+	ClProposerSyncInclusionRewards        int64   `parquet:"name=cl_proposer_sync_inclusion_rewards_gwei, type=INT64"`
+	ClProposerSlashingInclusionRewards    int64   `parquet:"name=cl_proposer_slashing_inclusion_rewards_gwei, type=INT64"`
+	SlashingReward                        int64   `parquet:"name=slashing_reward_gwei, type=INT64"`
+	ElRewards                             string  `parquet:"name=el_rewards_wei, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ElRewardsTotal                        string  `parquet:"name=el_rewards_wei_total, type=BYTE_ARRAY, convertedtype=UTF8"`
+	MevRewards                            string  `parquet:"name=mev_rewards_wei, type=BYTE_ARRAY, convertedtype=UTF8"`
+	MevRewardsTotal                       string  `parquet:"name=mev_rewards_wei_total, type=BYTE_ARRAY, convertedtype=UTF8"`
+	HadRelayData                          bool    `parquet:"name=had_relay_data, type=BOOLEAN"`
+	AttestationEffectiveness              float64 `parquet:"name=attestation_effectiveness, type=DOUBLE"`
+}
 
-	if err = markColumnExported(day, "cl_rewards_exported"); err != nil {
-		return err
+func newValidatorStatsParquetRow(row *types.ValidatorStatsRow) *validatorStatsParquetRow {
+	return &validatorStatsParquetRow{
+		ValidatorIndex:                        int64(row.ValidatorIndex),
+		Day:                                   row.Day,
+		StartBalance:                          row.StartBalance,
+		EndBalance:                            row.EndBalance,
+		MinBalance:                            row.MinBalance,
+		MaxBalance:                            row.MaxBalance,
+		StartEffectiveBalance:                 row.StartEffectiveBalance,
+		EndEffectiveBalance:                   row.EndEffectiveBalance,
+		MinEffectiveBalance:                   row.MinEffectiveBalance,
+		MaxEffectiveBalance:                   row.MaxEffectiveBalance,
+		MissedAttestations:                    row.MissedAttestations,
+		OrphanedAttestations:                  row.OrphanedAttestations,
+		MissedSource:                          row.MissedSource,
+		MissedTarget:                          row.MissedTarget,
+		MissedHead:                            row.MissedHead,
+		AvgInclusionDistance:                  row.AvgInclusionDistance,
+		MaxInclusionDistance:                  row.MaxInclusionDistance,
+		ParticipatedSync:                      row.ParticipatedSync,
+		MissedSync:                            row.MissedSync,
+		OrphanedSync:                          row.OrphanedSync,
+		SyncParticipationRate:                 row.SyncParticipationRate,
+		ProposedBlocks:                        row.ProposedBlocks,
+		MissedBlocks:                          row.MissedBlocks,
+		OrphanedBlocks:                        row.OrphanedBlocks,
+		LateBlocks:                            row.LateBlocks,
+		MevBlocks:                             row.MevBlocks,
+		LocalBlocks:                           row.LocalBlocks,
+		AttesterSlashings:                     row.AttesterSlashings,
+		ProposerSlashings:                     row.ProposerSlashings,
+		Deposits:                              row.Deposits,
+		DepositsAmount:                        row.DepositsAmount,
+		Withdrawals:                           row.Withdrawals,
+		WithdrawalsAmount:                     row.WithdrawalsAmount,
+		ClRewards:                             row.ClRewards,
+		ClRewardsTotal:                        row.ClRewardsTotal,
+		ClRewardsNet:                          row.ClRewardsNet,
+		ClProposerRewards:                     row.ClProposerRewards,
+		ClProposerRewardsTotal:                row.ClProposerRewardsTotal,
+		ClProposerAttestationInclusionRewards: row.ClProposerAttestationInclusionRewards,
+		ClProposerSyncInclusionRewards:        row.ClProposerSyncInclusionRewards,
+		ClProposerSlashingInclusionRewards:    row.ClProposerSlashingInclusionRewards,
+		SlashingReward:                        row.SlashingReward,
+		ElRewards:                             row.ElRewards.String(),
+		ElRewardsTotal:                        row.ElRewardsTotal.String(),
+		MevRewards:                            row.MevRewards.String(),
+		MevRewardsTotal:                       row.MevRewardsTotal.String(),
+		HadRelayData:                          row.HadRelayData,
+		AttestationEffectiveness:              row.AttestationEffectiveness,
 	}
+}
 
-	logger.Infof("cl rewards statistics export of day %v completed, took %v", day, time.Since(exportStart))
+// ExportValidatorStatsParquet writes the validator_stats rows of all validators of a day as parquet to w, see validatorStatsParquetRow
+// for the schema. The rows are streamed in chunks which are written as one row group each, so the whole day is never held in memory.
+func ExportValidatorStatsParquet(w io.Writer, day uint64) error {
+	err := writeValidatorStatsParquet(w, func(cb func(rows []types.ValidatorStatsRow) error) error {
+		return StreamValidatorStatsForDay(day, validatorStatsParquetChunkSize, cb)
+	})
+	if err != nil {
+		return fmt.Errorf("error exporting validator stats of day %v as parquet: %w", day, err)
+	}
 	return nil
 }
 
-func WriteValidatorBalances(day uint64) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*10))
-	defer cancel()
-
-	exportStart := time.Now()
-	defer func() {
-		metrics.TaskDuration.WithLabelValues("db_update_validator_balances_stats").Observe(time.Since(exportStart).Seconds())
-	}()
+// writeValidatorStatsParquet writes the rows passed to the callback of stream as parquet to w, one row group per chunk
+func writeValidatorStatsParquet(w io.Writer, stream func(cb func(rows []types.ValidatorStatsRow) error) error) error {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(validatorStatsParquetRow), 4)
+	if err != nil {
+		return fmt.Errorf("error creating parquet writer: %w", err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
 
-	if err := checkIfDayIsFinalized(day); err != nil {
+	err = stream(func(rows []types.ValidatorStatsRow) error {
+		for i := range rows {
+			if err := pw.Write(newValidatorStatsParquetRow(&rows[i])); err != nil {
+				return fmt.Errorf("error writing validator stats of validator %v to parquet: %w", rows[i].ValidatorIndex, err)
+			}
+		}
+		return pw.Flush(true)
+	})
+	if err != nil {
 		return err
 	}
+	return pw.WriteStop()
+}
+
+// validatorStatsForDayCacheKey returns the cache key of the stats of the sorted and deduplicated validator indices for a day
+func validatorStatsForDayCacheKey(validatorIndices []uint64, day uint64) string {
+	validatorIndicesStr := make([]string, len(validatorIndices))
+	for i, v := range validatorIndices {
+		validatorIndicesStr[i] = fmt.Sprintf("%d", v)
+	}
+	return fmt.Sprintf("%d:validatorStatsForDay:%d:%s", utils.Config.Chain.Config.DepositChainID, day, strings.Join(validatorIndicesStr, ","))
+}
+
+// lateBlockThreshold returns the delay after the start of its slot after which a proposed block is counted as late. It is configured
+// in slots and defaults to a third of a slot, the deadline for attesting to the block of the slot. Blocks that have not been
+// received through the head event stream have no seen delay and are never counted as late.
+func lateBlockThreshold() time.Duration {
+	slots := utils.Config.Statistics.LateBlockSlotThreshold
+	if slots <= 0 {
+		slots = 1.0 / 3
+	}
+	return time.Duration(slots * float64(utils.Config.Chain.Config.SecondsPerSlot) * float64(time.Second))
+}
 
+// VerifyValidatorBlockStats recounts the proposed, missed and orphaned blocks of the day from the blocks table and returns all
+// validators whose counts stored in validator_stats differ, ordered by validator index
+func VerifyValidatorBlockStats(day uint64) ([]types.ValidatorBlockStatsDiscrepancy, error) {
 	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
 
-	start := time.Now()
+	type blockStatsRow struct {
+		ValidatorIndex uint64 `db:"validatorindex"`
+		types.ValidatorBlockStats
+	}
 
-	logger.Infof("exporting min_balance, max_balance, min_effective_balance, max_effective_balance, start_balance, start_effective_balance, end_balance and end_effective_balance statistics")
-	balanceStatistics, err := BigtableClient.GetValidatorBalanceStatistics(firstEpoch, lastEpoch)
+	countedFilterCondition, filterArgs := validatorFilterCondition("proposer", 3)
+	countedRows := []blockStatsRow{}
+	err := ReaderDb.Select(&countedRows, `
+		SELECT 
+			proposer AS validatorindex, 
+			SUM(CASE WHEN status = '1' THEN 1 ELSE 0 END) AS proposed_blocks, 
+			SUM(CASE WHEN status = '2' THEN 1 ELSE 0 END) AS missed_blocks, 
+			SUM(CASE WHEN status = '3' THEN 1 ELSE 0 END) AS orphaned_blocks
+		FROM blocks
+		WHERE epoch >= $1 AND epoch <= $2`+countedFilterCondition+`
+		GROUP BY proposer`, append([]interface{}{firstEpoch, lastEpoch}, filterArgs...)...)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error counting blocks of day %v: %w", day, err)
 	}
 
-	balanceStatsArr := make([]*types.ValidatorBalanceStatistic, 0, len(balanceStatistics))
-	for _, stat := range balanceStatistics {
-		balanceStatsArr = append(balanceStatsArr, stat)
+	storedFilterCondition, _ := validatorFilterCondition("validatorindex", 2)
+	storedRows := []blockStatsRow{}
+	err = ReaderDb.Select(&storedRows, `
+		SELECT 
+			validatorindex, 
+			COALESCE(proposed_blocks, 0) AS proposed_blocks, 
+			COALESCE(missed_blocks, 0) AS missed_blocks, 
+			COALESCE(orphaned_blocks, 0) AS orphaned_blocks
+		FROM validator_stats
+		WHERE day = $1 AND (proposed_blocks > 0 OR missed_blocks > 0 OR orphaned_blocks > 0)`+storedFilterCondition, append([]interface{}{day}, filterArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting stored block stats of day %v: %w", day, err)
 	}
-	logger.Infof("fetching balance completed, took %v, now we save it", time.Since(start))
-	start = time.Now()
 
-	g, gCtx := errgroup.WithContext(ctx)
+	counted := make(map[uint64]types.ValidatorBlockStats, len(countedRows))
+	for _, row := range countedRows {
+		counted[row.ValidatorIndex] = row.ValidatorBlockStats
+	}
+	stored := make(map[uint64]types.ValidatorBlockStats, len(storedRows))
+	for _, row := range storedRows {
+		stored[row.ValidatorIndex] = row.ValidatorBlockStats
+	}
+	return compareValidatorBlockStats(stored, counted), nil
+}
 
-	batchSize := 100 // max parameters: 65535 / 10, but we are faster with smaller batch sizes
-	for b := 0; b < len(balanceStatsArr); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(balanceStatsArr) < end {
-			end = len(balanceStatsArr)
+// VerifyBlockStatsForDay returns the indices of the validators whose block stats stored in validator_stats no longer match the
+// blocks table, e.g. because a block was orphaned by a reorg after the day was exported. Their block stats can be re-exported
+// by running WriteValidatorBlockStats for the day with these validators as validator filter.
+func VerifyBlockStatsForDay(day uint64) ([]uint64, error) {
+	discrepancies, err := VerifyValidatorBlockStats(day)
+	if err != nil {
+		return nil, err
+	}
+	validators := make([]uint64, 0, len(discrepancies))
+	for _, discrepancy := range discrepancies {
+		validators = append(validators, discrepancy.ValidatorIndex)
+	}
+	return validators, nil
+}
+
+// compareValidatorBlockStats returns the validators whose stored block stats differ from the counted ones, validators missing on one side count as zero
+func compareValidatorBlockStats(stored, counted map[uint64]types.ValidatorBlockStats) []types.ValidatorBlockStatsDiscrepancy {
+	discrepancies := []types.ValidatorBlockStatsDiscrepancy{}
+	for validator, s := range stored {
+		if c := counted[validator]; c != s {
+			discrepancies = append(discrepancies, types.ValidatorBlockStatsDiscrepancy{ValidatorIndex: validator, Stored: s, Counted: c})
+		}
+	}
+	for validator, c := range counted {
+		if _, ok := stored[validator]; !ok && c != (types.ValidatorBlockStats{}) {
+			discrepancies = append(discrepancies, types.ValidatorBlockStatsDiscrepancy{ValidatorIndex: validator, Counted: c})
 		}
+	}
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return discrepancies[i].ValidatorIndex < discrepancies[j].ValidatorIndex
+	})
+	return discrepancies
+}
 
-		numArgs := 10
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*numArgs)
+// clRewardsReconciliationTolerance is the difference in gwei between the stored and the recomputed cl rewards of a validator
+// that is still accepted by VerifyClRewardsReconciliation
+const clRewardsReconciliationTolerance = 1
+
+// clRewardsReconciliationRow holds the stored statistics of a validator required to recompute its cl rewards of a day
+type clRewardsReconciliationRow struct {
+	ValidatorIndex    uint64 `db:"validatorindex"`
+	ClRewards         int64  `db:"cl_rewards_gwei"`
+	EndBalance        int64  `db:"end_balance"`
+	LastEndBalance    int64  `db:"last_end_balance"`
+	WithdrawalsAmount int64  `db:"withdrawals_amount"`
+	DepositsAmount    int64  `db:"deposits_amount"`
+}
 
-		g.Go(func() error {
-			select {
-			case <-gCtx.Done():
-				return nil
-			default:
-			}
-			defer logger.Infof("saving validator balance batch %v completed", start)
-			for i, stat := range balanceStatsArr[start:end] {
-				valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3, i*numArgs+4, i*numArgs+5, i*numArgs+6, i*numArgs+7, i*numArgs+8, i*numArgs+9, i*numArgs+10))
-				valueArgs = append(valueArgs, stat.Index)
-				valueArgs = append(valueArgs, day)
-				valueArgs = append(valueArgs, stat.MinBalance)
-				valueArgs = append(valueArgs, stat.MaxBalance)
-				valueArgs = append(valueArgs, stat.MinEffectiveBalance)
-				valueArgs = append(valueArgs, stat.MaxEffectiveBalance)
-				valueArgs = append(valueArgs, stat.StartBalance)
-				valueArgs = append(valueArgs, stat.StartEffectiveBalance)
-				valueArgs = append(valueArgs, stat.EndBalance)
-				valueArgs = append(valueArgs, stat.EndEffectiveBalance)
-			}
-			stmt := fmt.Sprintf(`
-				insert into validator_stats (validatorindex, day, min_balance, max_balance, min_effective_balance, max_effective_balance, start_balance, start_effective_balance, end_balance, end_effective_balance) VALUES
-				%s
-				on conflict (validatorindex, day) do update set min_balance = excluded.min_balance, max_balance = excluded.max_balance, min_effective_balance = excluded.min_effective_balance, max_effective_balance = excluded.max_effective_balance, start_balance = excluded.start_balance, start_effective_balance = excluded.start_effective_balance, end_balance = excluded.end_balance, end_effective_balance = excluded.end_effective_balance;`,
-				strings.Join(valueStrings, ","))
-			_, err := WriterDb.Exec(stmt, valueArgs...)
+// VerifyClRewardsReconciliation recomputes the cl rewards of every validator of the day from the stored balance, withdrawal and
+// deposit columns and returns the validators whose stored cl_rewards_gwei disagrees. It only reads and is meant to audit
+// already exported days, e.g. for deposits or withdrawals attributed to the wrong day.
+func VerifyClRewardsReconciliation(day uint64) ([]uint64, error) {
+	rows := []clRewardsReconciliationRow{}
+	var err error
+	if day == 0 {
+		// genesis validators have no previous day, their genesis deposits are stored at day -1
+		err = ReaderDb.Select(&rows, `
+			SELECT
+				cur.validatorindex,
+				cur.cl_rewards_gwei,
+				COALESCE(cur.end_balance, 0) AS end_balance,
+				0 AS last_end_balance,
+				COALESCE(cur.withdrawals_amount, 0) AS withdrawals_amount,
+				COALESCE(cur.deposits_amount, 0) + COALESCE(genesis.deposits_amount, 0) AS deposits_amount
+			FROM validator_stats cur
+			LEFT JOIN validator_stats genesis
+				ON genesis.validatorindex = cur.validatorindex AND genesis.day = -1
+			WHERE cur.day = 0 AND cur.cl_rewards_gwei IS NOT NULL`)
+	} else {
+		err = ReaderDb.Select(&rows, `
+			SELECT
+				cur.validatorindex,
+				cur.cl_rewards_gwei,
+				COALESCE(cur.end_balance, 0) AS end_balance,
+				COALESCE(last.end_balance, 0) AS last_end_balance,
+				COALESCE(cur.withdrawals_amount, 0) AS withdrawals_amount,
+				COALESCE(cur.deposits_amount, 0) AS deposits_amount
+			FROM validator_stats cur
+			INNER JOIN validator_stats last
+				ON last.validatorindex = cur.validatorindex AND last.day = cur.day - 1
+			WHERE cur.day = $1 AND cur.cl_rewards_gwei IS NOT NULL`, day)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving cl rewards and balances of day %v: %w", day, err)
+	}
+	return unreconciledClRewards(rows, clRewardsReconciliationTolerance), nil
+}
 
-			return err
-		})
+// unreconciledClRewards returns the sorted indices of the validators whose stored cl rewards differ by more than tolerance
+// from end_balance - last end_balance + withdrawals - deposits
+func unreconciledClRewards(rows []clRewardsReconciliationRow, tolerance int64) []uint64 {
+	validators := []uint64{}
+	for _, row := range rows {
+		expected := row.EndBalance - row.LastEndBalance + row.WithdrawalsAmount - row.DepositsAmount
+		diff := row.ClRewards - expected
+		if diff > tolerance || diff < -tolerance {
+			validators = append(validators, row.ValidatorIndex)
+		}
 	}
+	sort.Slice(validators, func(i, j int) bool { return validators[i] < validators[j] })
+	return validators
+}
 
-	if err = g.Wait(); err != nil {
-		logrus.Error(err)
-		return err
+// validatorPerformanceOrderColumns maps the supported leaderboard periods to the validator_performance column they are ordered by.
+// Only columns of this allowlist are ever interpolated into the leaderboard query.
+var validatorPerformanceOrderColumns = map[string]string{
+	"1d":    "cl_performance_1d",
+	"7d":    "cl_performance_7d",
+	"31d":   "cl_performance_31d",
+	"365d":  "cl_performance_365d",
+	"total": "cl_performance_total",
+}
+
+const maxValidatorPerformanceLeaderboardLimit = 1000
+
+// GetTopValidatorsByPerformance returns the validators with the highest consensus layer performance of the period ("1d", "7d", "31d",
+// "365d" or "total"). Rank is the position of the validator in the leaderboard, ties are ordered by validator index.
+func GetTopValidatorsByPerformance(period string, limit, offset int) ([]types.ValidatorPerformance, error) {
+	query, err := validatorPerformanceLeaderboardQuery(period, limit, offset)
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Infof("export completed, took %v", time.Since(start))
+	performance := []types.ValidatorPerformance{}
+	err = ReaderDb.Select(&performance, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving top validators by %v performance: %w", period, err)
+	}
+	for i := range performance {
+		performance[i].Rank = uint64(offset + i + 1)
+	}
+	return performance, nil
+}
 
-	if err = markColumnExported(day, "balance_exported"); err != nil {
-		return err
+func validatorPerformanceLeaderboardQuery(period string, limit, offset int) (string, error) {
+	column, ok := validatorPerformanceOrderColumns[period]
+	if !ok {
+		return "", fmt.Errorf("invalid performance period %q", period)
+	}
+	if limit <= 0 || limit > maxValidatorPerformanceLeaderboardLimit {
+		return "", fmt.Errorf("invalid limit %v, must be between 1 and %v", limit, maxValidatorPerformanceLeaderboardLimit)
+	}
+	if offset < 0 {
+		return "", fmt.Errorf("invalid offset %v", offset)
+	}
+	return fmt.Sprintf("SELECT %s FROM validator_performance ORDER BY %s DESC NULLS LAST, validatorindex LIMIT $1 OFFSET $2", validatorPerformanceColumns, column), nil
+}
+
+// GetValidatorPerformanceExtremes returns the n best and worst days of a validator by cl_rewards_gwei and by missed_attestations.
+// Days on which a statistic has not been exported are excluded from its ranking instead of being counted as zero.
+func GetValidatorPerformanceExtremes(validatorIndex uint64, n int) (*types.ValidatorPerformanceExtremes, error) {
+	if n <= 0 || n > maxValidatorPerformanceLeaderboardLimit {
+		return nil, fmt.Errorf("invalid number of days %v, must be between 1 and %v", n, maxValidatorPerformanceLeaderboardLimit)
 	}
 
-	logger.Infof("balance statistics export of day %v completed, took %v", day, time.Since(exportStart))
-	return nil
+	extremes := &types.ValidatorPerformanceExtremes{}
+	for _, q := range []struct {
+		column    string
+		ascending bool
+		dst       *[]types.ValidatorDayMetric
+	}{
+		{"cl_rewards_gwei", false, &extremes.BestClRewardsDays},
+		{"cl_rewards_gwei", true, &extremes.WorstClRewardsDays},
+		{"missed_attestations", true, &extremes.FewestMissedAttestationsDays},
+		{"missed_attestations", false, &extremes.MostMissedAttestationsDays},
+	} {
+		*q.dst = []types.ValidatorDayMetric{}
+		err := ReaderDb.Select(q.dst, validatorDayExtremesQuery(q.column, q.ascending), validatorIndex, n)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving %v extremes of validator %v: %w", q.column, validatorIndex, err)
+		}
+	}
+	return extremes, nil
 }
 
-func WriteValidatorDepositWithdrawals(day uint64) error {
+// validatorDayExtremesQuery returns the query for the days of a validator ordered by a validator_stats column. Ties are
+// ordered by the most recent day first.
+func validatorDayExtremesQuery(column string, ascending bool) string {
+	direction := "DESC"
+	if ascending {
+		direction = "ASC"
+	}
+	return fmt.Sprintf(`
+		SELECT day, %[1]s AS value
+		FROM validator_stats
+		WHERE validatorindex = $1 AND day >= 0 AND %[1]s IS NOT NULL
+		ORDER BY %[1]s %[2]s, day DESC
+		LIMIT $2`, column, direction)
+}
+
+func WriteValidatorBlockStats(day uint64) error {
 	exportStart := time.Now()
 	defer func() {
-		metrics.TaskDuration.WithLabelValues("db_update_validator_deposit_withdrawal_stats").Observe(time.Since(exportStart).Seconds())
+		metrics.TaskDuration.WithLabelValues("db_update_validator_block_stats").Observe(time.Since(exportStart).Seconds())
 	}()
 
 	if err := checkIfDayIsFinalized(day); err != nil {
 		return err
 	}
 
+	unlock, err := lockStatisticsExport(day, "block_stats")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
-	// for getting the withrawals / deposits for the current day we have to go 1 epoch in the past as they affect the balance one epoch after they have happend
-	if firstEpoch > 0 {
-		firstEpoch--
+
+	var epochsWithBlocks uint64
+	err = WriterDb.Get(&epochsWithBlocks, "SELECT COUNT(DISTINCT epoch) FROM blocks WHERE epoch >= $1 AND epoch <= $2", firstEpoch, lastEpoch)
+	if err != nil {
+		return err
+	}
+	if epochsInDay := lastEpoch - firstEpoch + 1; epochsWithBlocks < epochsInDay {
+		logger.Warnf("blocks table only contains %v of %v epochs of day %v, block statistics will be undercounted", epochsWithBlocks, epochsInDay, day)
+	}
+
+	if skipDryRunWrites("block_stats", day, -1) {
+		return nil
 	}
-	lastEpoch--
 
 	tx, err := WriterDb.Beginx()
 	if err != nil {
-		logrus.Errorf("error WriterDb.Beginx %v", err)
 		return err
 	}
 	defer tx.Rollback()
 
 	start := time.Now()
-	logrus.Infof("Update Withdrawals + Deposits for day [%v] epoch %v -> %v", day, firstEpoch, lastEpoch)
 
-	logger.Infof("exporting deposits and deposits_amount statistics")
-	depositsQry := `
-		insert into validator_stats (validatorindex, day, deposits, deposits_amount) 
+	logger.Infof("exporting proposed_blocks, missed_blocks, orphaned_blocks and late_blocks statistics")
+	filterCondition, filterArgs := validatorFilterCondition("proposer", 5)
+	_, err = tx.Exec(`
+		insert into validator_stats (validatorindex, day, proposed_blocks, missed_blocks, orphaned_blocks, late_blocks) 
 		(
-			select validators.validatorindex, $3, count(*), sum(amount)
-			from blocks_deposits
-			inner join validators on blocks_deposits.publickey = validators.pubkey
-			inner join blocks on blocks_deposits.block_root = blocks.blockroot
-			where blocks.epoch >= $1 and blocks.epoch <= $2 and blocks.status = '1' and blocks_deposits.valid_signature
-			group by validators.validatorindex
+			select proposer, $3, sum(case when status = '1' then 1 else 0 end), sum(case when status = '2' then 1 else 0 end), sum(case when status = '3' then 1 else 0 end), sum(case when status = '1' and seen_delay_ms > $4 then 1 else 0 end)
+			from blocks
+			where epoch >= $1 and epoch <= $2`+filterCondition+`
+			group by proposer
 		) 
-		on conflict (validatorindex, day) do
-			update set deposits = excluded.deposits, 
-			deposits_amount = excluded.deposits_amount;`
-	if day == 0 {
-		// genesis-deposits will be added to block 0 by the exporter which is technically not 100% correct
-		// since deposits will be added to the validator-balance only after the block which includes the deposits.
-		// to ease the calculation of validator-income (considering deposits) we set the day of genesis-deposits to -1.
-		depositsQry = `
-			insert into validator_stats (validatorindex, day, deposits, deposits_amount)
-			(
-				select validators.validatorindex, case when block_slot = 0 then -1 else $3 end as day, count(*), sum(amount)
-				from blocks_deposits
-				inner join validators on blocks_deposits.publickey = validators.pubkey
-				inner join blocks on blocks_deposits.block_root = blocks.blockroot
-				where blocks.epoch >= $1 and blocks.epoch <= $2 and blocks.status = '1'
-				group by validators.validatorindex, day
-			) 
-			on conflict (validatorindex, day) do
-				update set deposits = excluded.deposits, 
-				deposits_amount = excluded.deposits_amount;`
-	}
-
-	_, err = tx.Exec(depositsQry, firstEpoch, lastEpoch, day)
+		on conflict (validatorindex, day) do update set proposed_blocks = excluded.proposed_blocks, missed_blocks = excluded.missed_blocks, orphaned_blocks = excluded.orphaned_blocks, late_blocks = excluded.late_blocks;`,
+		append([]interface{}{firstEpoch, lastEpoch, day, lateBlockThreshold().Milliseconds()}, filterArgs...)...)
 	if err != nil {
 		return err
 	}
 	logger.Infof("export completed, took %v", time.Since(start))
 
 	start = time.Now()
-	logger.Infof("exporting withdrawals and withdrawals_amount statistics")
-	withdrawalsQuery := `
-		insert into validator_stats (validatorindex, day, withdrawals, withdrawals_amount) 
+	logger.Infof("exporting attester_slashings and proposer_slashings statistics")
+	filterCondition, filterArgs = validatorFilterCondition("proposer", 4)
+	_, err = tx.Exec(`
+		insert into validator_stats (validatorindex, day, attester_slashings, proposer_slashings) 
 		(
-			select validatorindex, $3, count(*), sum(amount)
-			from blocks_withdrawals
-			inner join blocks on blocks_withdrawals.block_root = blocks.blockroot
-			where block_slot >= $1 and block_slot < $2 and blocks.status = '1'
-			group by validatorindex
+			select proposer, $3, sum(attesterslashingscount), sum(proposerslashingscount)
+			from blocks
+			where epoch >= $1 and epoch <= $2 and status = '1'`+filterCondition+`
+			group by proposer
 		) 
-		on conflict (validatorindex, day) do
-			update set withdrawals = excluded.withdrawals, 
-			withdrawals_amount = excluded.withdrawals_amount;`
-	_, err = tx.Exec(withdrawalsQuery, firstEpoch*utils.Config.Chain.Config.SlotsPerEpoch, (lastEpoch+1)*utils.Config.Chain.Config.SlotsPerEpoch, day)
+		on conflict (validatorindex, day) do update set attester_slashings = excluded.attester_slashings, proposer_slashings = excluded.proposer_slashings;`,
+		append([]interface{}{firstEpoch, lastEpoch, day}, filterArgs...)...)
 	if err != nil {
 		return err
 	}
+
 	if err = tx.Commit(); err != nil {
 		return err
 	}
-
 	logger.Infof("export completed, took %v", time.Since(start))
 
-	if err = markColumnExported(day, "withdrawals_deposits_exported"); err != nil {
+	if err = markColumnExported(day, "block_stats_exported", time.Since(exportStart)); err != nil {
 		return err
 	}
 
-	logger.Infof("deposits and withdrawals statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	logger.Infof("block statistics export of day %v completed, took %v", day, time.Since(exportStart))
 	return nil
 }
 
-func WriteValidatorSyncDutiesForDay(day uint64) error {
+// WriteValidatorSlashingEventsForDay records every slashing included in the canonical blocks of the day together with the
+// slashed validator and the proposer that included it
+func WriteValidatorSlashingEventsForDay(day uint64) error {
 	exportStart := time.Now()
 	defer func() {
-		metrics.TaskDuration.WithLabelValues("db_update_validator_sync_stats").Observe(time.Since(exportStart).Seconds())
+		metrics.TaskDuration.WithLabelValues("db_update_validator_slashing_events").Observe(time.Since(exportStart).Seconds())
 	}()
 
 	if err := checkIfDayIsFinalized(day); err != nil {
 		return err
 	}
 
-	startEpoch, endEpoch := utils.GetFirstAndLastEpochForDay(day)
-
-	start := time.Now()
-	logrus.Infof("Update Sync duties for day [%v] epoch %v -> %v", day, startEpoch, endEpoch)
-
-	syncStats, err := BigtableClient.GetValidatorSyncDutiesStatistics([]uint64{}, startEpoch, endEpoch)
+	unlock, err := lockStatisticsExport(day, "slashing_events")
 	if err != nil {
 		return err
 	}
-	logrus.Infof("getting sync duties done in %v, now we export them to the db", time.Since(start))
-	start = time.Now()
+	defer unlock()
 
-	syncStatsArr := make([]*types.ValidatorSyncDutiesStatistic, 0, len(syncStats))
-	for _, stat := range syncStats {
-		syncStatsArr = append(syncStatsArr, stat)
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	if skipDryRunWrites("slashing_events", day, -1) {
+		return nil
 	}
 
 	tx, err := WriterDb.Beginx()
 	if err != nil {
-		logrus.Errorf("error WriterDb.Beginx %v", err)
 		return err
 	}
 	defer tx.Rollback()
 
-	batchSize := 13000 // max parameters: 65535
-	for b := 0; b < len(syncStatsArr); b += batchSize {
-		start := b
-		end := b + batchSize
-		if len(syncStatsArr) < end {
-			end = len(syncStatsArr)
-		}
+	start := time.Now()
 
-		numArgs := 5
-		valueStrings := make([]string, 0, batchSize)
-		valueArgs := make([]interface{}, 0, batchSize*numArgs)
-		for i, stat := range syncStatsArr[start:end] {
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3, i*numArgs+4, i*numArgs+5))
-			valueArgs = append(valueArgs, stat.Index)
-			valueArgs = append(valueArgs, day)
-			valueArgs = append(valueArgs, stat.ParticipatedSync)
-			valueArgs = append(valueArgs, stat.MissedSync)
-			valueArgs = append(valueArgs, stat.OrphanedSync)
-		}
-		stmt := fmt.Sprintf(`
-			insert into validator_stats (validatorindex, day, participated_sync, missed_sync, orphaned_sync)  VALUES
-			%s
-			on conflict (validatorindex, day) do update set participated_sync = excluded.participated_sync, missed_sync = excluded.missed_sync, orphaned_sync = excluded.orphaned_sync;`,
-			strings.Join(valueStrings, ","))
-		_, err := tx.Exec(stmt, valueArgs...)
-		if err != nil {
-			return err
-		}
+	_, err = tx.Exec("DELETE FROM validator_slashing_events WHERE day = $1", day)
+	if err != nil {
+		return err
+	}
 
-		logrus.Infof("saving sync statistics batch %v completed", b)
+	logger.Infof("exporting attester slashing events")
+	_, err = tx.Exec(`
+		insert into validator_slashing_events (validatorindex, day, slot, block_index, slasher, type)
+		(
+			select slashed.validatorindex, $3, blocks.slot, blocks_attesterslashings.block_index, blocks.proposer, 'attester'
+			from blocks_attesterslashings
+			inner join blocks on blocks_attesterslashings.block_root = blocks.blockroot
+			cross join lateral (
+				select unnest(blocks_attesterslashings.attestation1_indices) intersect select unnest(blocks_attesterslashings.attestation2_indices)
+			) as slashed(validatorindex)
+			where blocks.epoch >= $1 and blocks.epoch <= $2 and blocks.status = '1'
+		)
+		on conflict (slot, block_index, validatorindex, type) do nothing;`,
+		firstEpoch, lastEpoch, day)
+	if err != nil {
+		return err
 	}
 
-	if err = tx.Commit(); err != nil {
+	logger.Infof("exporting proposer slashing events")
+	_, err = tx.Exec(`
+		insert into validator_slashing_events (validatorindex, day, slot, block_index, slasher, type)
+		(
+			select blocks_proposerslashings.proposerindex, $3, blocks.slot, blocks_proposerslashings.block_index, blocks.proposer, 'proposer'
+			from blocks_proposerslashings
+			inner join blocks on blocks_proposerslashings.block_root = blocks.blockroot
+			where blocks.epoch >= $1 and blocks.epoch <= $2 and blocks.status = '1'
+		)
+		on conflict (slot, block_index, validatorindex, type) do nothing;`,
+		firstEpoch, lastEpoch, day)
+	if err != nil {
 		return err
 	}
 
+	if err = tx.Commit(); err != nil {
+		return err
+	}
 	logger.Infof("export completed, took %v", time.Since(start))
 
-	if err = markColumnExported(day, "sync_duties_exported"); err != nil {
+	if err = markColumnExported(day, "slashing_events_exported", time.Since(exportStart)); err != nil {
 		return err
 	}
 
-	logger.Infof("sync duties and statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	logger.Infof("slashing events export of day %v completed, took %v", day, time.Since(exportStart))
 	return nil
 }
 
-func WriteValidatorFailedAttestationsStatisticsForDay(day uint64) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*10))
-	defer cancel()
-	exportStart := time.Now()
-	defer func() {
-		metrics.TaskDuration.WithLabelValues("db_update_validator_failed_att_stats").Observe(time.Since(exportStart).Seconds())
-	}()
+// GetValidatorSlashingEvents returns all recorded slashings of a validator, latest first
+func GetValidatorSlashingEvents(validatorIndex uint64) ([]*types.ValidatorSlashingEvent, error) {
+	events := []*types.ValidatorSlashingEvent{}
+	err := ReaderDb.Select(&events, `
+		SELECT validatorindex, day, slot, block_index, slasher, type
+		FROM validator_slashing_events
+		WHERE validatorindex = $1
+		ORDER BY slot DESC, block_index DESC`, validatorIndex)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving slashing events of validator %v: %w", validatorIndex, err)
+	}
+	return events, nil
+}
 
-	if err := checkIfDayIsFinalized(day); err != nil {
-		return err
+// GetValidatorDailyPerformanceTable returns a page of the exported daily statistics of a validator, newest day first. The page
+// starts at offsetDay, or at the last day finalized at lastFinalizedEpoch if offsetDay is later, and contains up to limit days.
+func GetValidatorDailyPerformanceTable(validatorIndex uint64, offsetDay uint64, limit uint64, lastFinalizedEpoch uint64) ([]*types.ValidatorDailyPerformance, error) {
+	cacheDur := time.Second * time.Duration(utils.Config.Chain.Config.SecondsPerSlot*utils.Config.Chain.Config.SlotsPerEpoch+10) // updates every epoch, keep 10sec longer
+	cacheKey := fmt.Sprintf("%d:validatorDailyPerformanceTable:%d:%d:%d:%d", utils.Config.Chain.Config.DepositChainID, validatorIndex, offsetDay, limit, lastFinalizedEpoch)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, cacheDur, []*types.ValidatorDailyPerformance{}); err == nil {
+		return cached.([]*types.ValidatorDailyPerformance), nil
 	}
 
-	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+	rows, err := getValidatorDailyPerformance(validatorIndex, offsetDay, limit, lastFinalizedEpoch)
+	if err != nil {
+		return nil, err
+	}
 
-	start := time.Now()
+	go func(rows []*types.ValidatorDailyPerformance) {
+		err := cache.TieredCache.Set(cacheKey, rows, cacheDur)
+		if err != nil {
+			utils.LogError(err, fmt.Errorf("error setting tieredCache for GetValidatorDailyPerformanceTable with key %v", cacheKey), 0)
+		}
+	}(rows)
 
-	logrus.Infof("exporting 'failed attestations' statistics firstEpoch: %v lastEpoch: %v", firstEpoch, lastEpoch)
+	return rows, nil
+}
 
-	// first key is the batch start index and the second is the validator id
-	failed := map[uint64]map[uint64]*types.ValidatorFailedAttestationsStatistic{}
-	mux := sync.Mutex{}
-	g, gCtx := errgroup.WithContext(ctx)
-	epochBatchSize := uint64(2) // Fetching 2 Epochs per batch seems to be the fastest way to go
-	for i := firstEpoch; i < lastEpoch; i += epochBatchSize {
-		fromEpoch := i
-		toEpoch := fromEpoch + epochBatchSize
-		if toEpoch >= lastEpoch {
-			toEpoch = lastEpoch
-		} else {
-			toEpoch--
-		}
-		g.Go(func() error {
-			select {
-			case <-gCtx.Done():
-				return nil
-			default:
-			}
-			ma, err := BigtableClient.GetValidatorFailedAttestationsCount([]uint64{}, fromEpoch, toEpoch)
-			if err != nil {
-				logrus.Errorf("error getting 'failed attestations' %v", err)
-				return err
-			}
-			mux.Lock()
-			failed[fromEpoch] = ma
-			mux.Unlock()
-			return nil
-		})
-	}
+func getValidatorDailyPerformance(validatorIndex uint64, offsetDay uint64, limit uint64, lastFinalizedEpoch uint64) ([]*types.ValidatorDailyPerformance, error) {
+	rows := []*types.ValidatorDailyPerformance{}
 
-	if err := g.Wait(); err != nil {
-		return err
+	// only days whose last epoch is finalized are shown
+	finalizedDays := utils.EpochToDay(lastFinalizedEpoch + 1)
+	if finalizedDays == 0 {
+		return rows, nil
+	}
+	toDay := finalizedDays - 1
+	if offsetDay < toDay {
+		toDay = offsetDay
 	}
 
-	validatorMap := map[uint64]*types.ValidatorFailedAttestationsStatistic{}
-	for _, f := range failed {
-
-		for key, val := range f {
-			if validatorMap[key] == nil {
-				validatorMap[key] = val
-			} else {
-				validatorMap[key].MissedAttestations += val.MissedAttestations
-				validatorMap[key].OrphanedAttestations += val.OrphanedAttestations
-			}
-		}
+	// the day -1 row only holds the genesis balances
+	err := ReaderDb.Select(&rows, `
+		SELECT
+			day,
+			COALESCE(start_balance, 0) AS start_balance,
+			COALESCE(end_balance, 0) AS end_balance,
+			COALESCE(cl_rewards_gwei, 0) AS cl_rewards_gwei,
+			COALESCE(el_rewards_wei, 0) AS el_rewards_wei,
+			COALESCE(mev_rewards_wei, 0) AS mev_rewards_wei,
+			COALESCE(proposed_blocks, 0) AS proposed_blocks,
+			COALESCE(missed_blocks, 0) AS missed_blocks,
+			COALESCE(orphaned_blocks, 0) AS orphaned_blocks,
+			COALESCE(participated_sync, 0) AS participated_sync,
+			COALESCE(missed_sync, 0) AS missed_sync,
+			COALESCE(orphaned_sync, 0) AS orphaned_sync,
+			COALESCE(deposits, 0) AS deposits,
+			COALESCE(deposits_amount, 0) AS deposits_amount,
+			COALESCE(withdrawals, 0) AS withdrawals,
+			COALESCE(withdrawals_amount, 0) AS withdrawals_amount
+		FROM validator_stats
+		WHERE validatorindex = $1 AND day BETWEEN 0 AND $2
+		ORDER BY day DESC
+		LIMIT $3`, validatorIndex, toDay, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving daily performance of validator %v: %w", validatorIndex, err)
 	}
+	return rows, nil
+}
 
-	logrus.Infof("fetching 'failed attestations' done in %v, now we export them to the db", time.Since(start))
-	start = time.Now()
-	maArr := make([]*types.ValidatorFailedAttestationsStatistic, 0, len(validatorMap))
+// GetValidatorDailyPerformanceCount returns the number of days with exported statistics of a validator
+func GetValidatorDailyPerformanceCount(validatorIndex uint64) (uint64, error) {
+	var count uint64
+	err := ReaderDb.Get(&count, "SELECT COUNT(*) FROM validator_stats WHERE validatorindex = $1 AND day >= 0", validatorIndex)
+	if err != nil {
+		return 0, fmt.Errorf("error counting daily performance rows of validator %v: %w", validatorIndex, err)
+	}
+	return count, nil
+}
 
-	for _, stat := range validatorMap {
-		maArr = append(maArr, stat)
+// GetValidatorSyncParticipation returns the average daily sync committee participation rate of a validator between fromDay and toDay (inclusive).
+// Days without sync duties are ignored, nil is returned if the validator had no sync duties in the range.
+func GetValidatorSyncParticipation(validatorIndex, fromDay, toDay uint64) (*float64, error) {
+	var rate sql.NullFloat64
+	err := ReaderDb.Get(&rate, `
+		SELECT AVG(sync_participation_rate) 
+		FROM validator_stats 
+		WHERE validatorindex = $1 AND day >= $2 AND day <= $3`, validatorIndex, fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sync participation of validator %v for days %v - %v: %w", validatorIndex, fromDay, toDay, err)
+	}
+	if !rate.Valid {
+		return nil, nil
 	}
+	return &rate.Float64, nil
+}
 
-	g, gCtx = errgroup.WithContext(ctx)
+// GetValidatorSyncCommitteePeriods returns the sync committee periods the validator was a member of, ordered by period, with its sync
+// duties of each period. The membership is taken from the sync_committees table, the duties are read from Bigtable as the daily
+// validator_stats don't align with the periods.
+func GetValidatorSyncCommitteePeriods(validatorIndex uint64) ([]types.ValidatorSyncCommitteePeriod, error) {
+	periods := []uint64{}
+	err := ReaderDb.Select(&periods, "SELECT DISTINCT period FROM sync_committees WHERE validatorindex = $1 ORDER BY period", validatorIndex)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving sync committee periods of validator %v: %w", validatorIndex, err)
+	}
 
-	batchSize := 100 // max: 65535 / 4, but we are faster with smaller batches
-	for b := 0; b < len(maArr); b += batchSize {
+	result := make([]types.ValidatorSyncCommitteePeriod, 0, len(periods))
+	for _, period := range periods {
+		firstEpoch := utils.FirstEpochOfSyncPeriod(period)
+		lastEpoch := utils.FirstEpochOfSyncPeriod(period+1) - 1
 
-		start := b
-		end := b + batchSize
-		if len(maArr) < end {
-			end = len(maArr)
+		var syncStats map[uint64]*types.ValidatorSyncDutiesStatistic
+		err = retryBigtable("GetValidatorSyncDutiesStatistics", func() error {
+			var err error
+			syncStats, err = BigtableClient.GetValidatorSyncDutiesStatistics([]uint64{validatorIndex}, firstEpoch, lastEpoch)
+			return err
+		})
+		if err != nil {
+			return nil, err
 		}
+		result = append(result, validatorSyncCommitteePeriod(period, firstEpoch, lastEpoch, syncStats[validatorIndex]))
+	}
+	return result, nil
+}
 
-		g.Go(func() error {
-			select {
-			case <-gCtx.Done():
-				return nil
-			default:
-			}
-			return saveFailedAttestationBatch(maArr[start:end], day)
-		})
+// validatorSyncCommitteePeriod returns the sync committee period with the given sync duties, stats is nil if none were found (e.g. for the
+// current period before its first duty)
+func validatorSyncCommitteePeriod(period, firstEpoch, lastEpoch uint64, stats *types.ValidatorSyncDutiesStatistic) types.ValidatorSyncCommitteePeriod {
+	p := types.ValidatorSyncCommitteePeriod{Period: period, FirstEpoch: firstEpoch, LastEpoch: lastEpoch}
+	if stats != nil {
+		p.ParticipatedSync = stats.ParticipatedSync
+		p.MissedSync = stats.MissedSync
+		p.OrphanedSync = stats.OrphanedSync
+		p.ParticipationRate = stats.ParticipationRate()
 	}
+	return p
+}
 
-	if err := g.Wait(); err != nil {
-		logrus.Error(err)
+func WriteValidatorElIcome(day uint64) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline("el_rewards")))
+	defer cancel()
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_el_income_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
 		return err
 	}
-	logger.Infof("export completed, took %v", time.Since(start))
 
-	if err := markColumnExported(day, "failed_attestations_exported"); err != nil {
+	unlock, err := lockStatisticsExport(day, "el_rewards")
+	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	logger.Infof("'failed attestation' statistics export of day %v completed, took %v", day, time.Since(exportStart))
-	return nil
-}
-
-func saveFailedAttestationBatch(batch []*types.ValidatorFailedAttestationsStatistic, day uint64) error {
-	var failedAttestationBatchNumArgs int = 4
-	batchSize := len(batch)
-	valueStrings := make([]string, 0, failedAttestationBatchNumArgs)
-	valueArgs := make([]interface{}, 0, batchSize*failedAttestationBatchNumArgs)
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
 
-	for i, stat := range batch {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)", i*failedAttestationBatchNumArgs+1, i*failedAttestationBatchNumArgs+2, i*failedAttestationBatchNumArgs+3, i*failedAttestationBatchNumArgs+4))
-		valueArgs = append(valueArgs, stat.Index)
-		valueArgs = append(valueArgs, day)
-		valueArgs = append(valueArgs, stat.MissedAttestations)
-		valueArgs = append(valueArgs, stat.OrphanedAttestations)
-	}
-	stmt := fmt.Sprintf(`
-		insert into validator_stats (validatorindex, day, missed_attestations, orphaned_attestations) VALUES
-		%s
-		on conflict (validatorindex, day) do update set missed_attestations = excluded.missed_attestations, orphaned_attestations = excluded.orphaned_attestations;`,
-		strings.Join(valueStrings, ","))
-	_, err := WriterDb.Exec(stmt, valueArgs...)
+	tx, err := WriterDb.Beginx()
 	if err != nil {
-		logrus.Errorf("Error inserting 'failed attestations' %v", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
-}
-
-func markColumnExported(day uint64, column string) error {
 	start := time.Now()
-	logger.Infof("marking [%v] exported for day [%v] as completed in the status table", column, day)
 
-	_, err := WriterDb.Exec(fmt.Sprintf(`	
-		INSERT INTO validator_stats_status (day, status, %[1]v) 
-		VALUES ($1, false, true) 
-		ON CONFLICT (day) 
-			DO UPDATE SET %[1]v=EXCLUDED.%[1]v;
-			`, column), day)
-	if err != nil {
-		return err
+	logger.Infof("exporting mev & el rewards")
+
+	type Container struct {
+		Slot            uint64 `db:"slot"`
+		ExecBlockNumber uint64 `db:"exec_block_number"`
+		ExecBlockHash   []byte `db:"exec_block_hash"`
+		Proposer        uint64 `db:"proposer"`
+		Status          string `db:"status"`
 	}
-	logrus.Infof("Marking complete in %v", time.Since(start))
-	return nil
-}
 
-func GetValidatorIncomeHistoryChart(validatorIndices []uint64, currency string, lastFinalizedEpoch uint64) ([]*types.ChartDataPoint, error) {
-	incomeHistory, err := GetValidatorIncomeHistory(validatorIndices, 0, 0, lastFinalizedEpoch)
+	blocks := make([]*Container, 0)
+	blockProposers := make(map[uint64]uint64)
+	orphanedBlockProposers := make(map[common.Hash]uint64)
+
+	filterCondition, filterArgs := validatorFilterCondition("proposer", 3)
+	err = tx.Select(&blocks, "SELECT slot, exec_block_number, exec_block_hash, proposer, status FROM blocks WHERE epoch >= $1 AND epoch <= $2 AND exec_block_number > 0 AND status IN ('1', '3')"+filterCondition, append([]interface{}{firstEpoch, lastEpoch}, filterArgs...)...)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("error retrieving blocks data: %v", err)
 	}
-	var clRewardsSeries = make([]*types.ChartDataPoint, len(incomeHistory))
 
-	for i := 0; i < len(incomeHistory); i++ {
-		color := "#7cb5ec"
-		if incomeHistory[i].ClRewards < 0 {
-			color = "#f7a35c"
+	numbers := make([]uint64, 0, len(blocks))
+	orphanedNumbers := make([]uint64, 0)
+
+	for _, b := range blocks {
+		if b.Status == "3" {
+			orphanedNumbers = append(orphanedNumbers, b.ExecBlockNumber)
+			orphanedBlockProposers[common.BytesToHash(b.ExecBlockHash)] = b.Proposer
+			continue
 		}
-		balanceTs := utils.DayToTime(incomeHistory[i].Day)
-		clRewardsSeries[i] = &types.ChartDataPoint{X: float64(balanceTs.Unix() * 1000), Y: utils.ExchangeRateForCurrency(currency) * (float64(incomeHistory[i].ClRewards) / 1e9), Color: color}
+		numbers = append(numbers, b.ExecBlockNumber)
+		blockProposers[b.ExecBlockNumber] = b.Proposer
 	}
-	return clRewardsSeries, err
-}
 
-func GetValidatorIncomeHistory(validatorIndices []uint64, lowerBoundDay uint64, upperBoundDay uint64, lastFinalizedEpoch uint64) ([]types.ValidatorIncomeHistory, error) {
-	if len(validatorIndices) == 0 {
-		return []types.ValidatorIncomeHistory{}, nil
+	blocksData, err := getBlocksIndexedChunked(ctx, numbers)
+	if err != nil {
+		return fmt.Errorf("error in GetBlocksIndexedMultiple: %v", err)
 	}
 
-	if upperBoundDay == 0 {
-		upperBoundDay = 65536
+	relaysData, err := getRelayDataForIndexedBlocksCached(blocksData)
+	if err != nil {
+		return fmt.Errorf("error in GetRelayDataForIndexedBlocks: %v", err)
 	}
 
-	validatorIndices = utils.SortedUniqueUint64(validatorIndices)
-	validatorIndicesStr := make([]string, len(validatorIndices))
-	for i, v := range validatorIndices {
-		validatorIndicesStr[i] = fmt.Sprintf("%d", v)
+	overrides, err := mevBribeOverrides()
+	if err != nil {
+		return err
 	}
 
-	validatorIndicesPqArr := pq.Array(validatorIndices)
+	proposerRewards := aggregateProposerElRewards(blocksData, blockProposers, relaysData, overrides)
+	logrus.Infof("retrieved mev / el rewards data for %v proposer", len(proposerRewards))
 
-	cacheDur := time.Second * time.Duration(utils.Config.Chain.Config.SecondsPerSlot*utils.Config.Chain.Config.SlotsPerEpoch+10) // updates every epoch, keep 10sec longer
-	cacheKey := fmt.Sprintf("%d:validatorIncomeHistory:%d:%d:%d:%s", utils.Config.Chain.Config.DepositChainID, lowerBoundDay, upperBoundDay, lastFinalizedEpoch, strings.Join(validatorIndicesStr, ","))
-	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, cacheDur, []types.ValidatorIncomeHistory{}); err == nil {
-		return cached.([]types.ValidatorIncomeHistory), nil
+	orphanedBlocksData, err := getBlocksIndexedChunked(ctx, orphanedNumbers)
+	if err != nil {
+		return fmt.Errorf("error in GetBlocksIndexedMultiple: %v", err)
 	}
+	orphanedRewards := aggregateOrphanedElRewards(orphanedBlocksData, orphanedBlockProposers)
+	logrus.Infof("retrieved orphaned el rewards data for %v of %v orphaned blocks", len(orphanedRewards), len(orphanedBlockProposers))
 
-	var result []types.ValidatorIncomeHistory
-	err := ReaderDb.Select(&result, `
-		SELECT 
-			day, 
-			SUM(COALESCE(cl_rewards_gwei, 0)) AS cl_rewards_gwei,
-			SUM(COALESCE(end_balance, 0)) AS end_balance
-		FROM validator_stats 
-		WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3 
-		GROUP BY day 
-		ORDER BY day
-	;`, validatorIndicesPqArr, lowerBoundDay, upperBoundDay)
-	if err != nil {
-		return nil, err
+	if skipDryRunWrites("el_rewards", day, len(proposerRewards)) {
+		return nil
 	}
 
-	// retrieve rewards for epochs not yet in stats
-	if upperBoundDay == 65536 {
-		lastDay := uint64(0)
-		if len(result) > 0 {
-			lastDay = uint64(result[len(result)-1].Day)
-		} else {
-			lastDay, err = GetLastExportedStatisticDay()
-			if err != nil {
-				return nil, err
-			}
+	// the statements are split into batches to stay below the parameter limit
+	batchSize := insertBatchSize("el_rewards", utils.Config.Statistics.ElRewardsBatchSize, 1000, elRewardsNumArgs)
+	proposers := make([]uint64, 0, len(proposerRewards))
+	for proposer := range proposerRewards {
+		proposers = append(proposers, proposer)
+	}
+	sort.Slice(proposers, func(i, j int) bool { return proposers[i] < proposers[j] })
+	for b := 0; b < len(proposers); b += batchSize {
+		end := b + batchSize
+		if len(proposers) < end {
+			end = len(proposers)
 		}
+		if err = saveElRewardsBatch(tx, proposers[b:end], proposerRewards, day); err != nil {
+			return err
+		}
+	}
 
-		currentDay := lastDay + 1
-		firstEpoch := currentDay * utils.EpochsPerDay()
-
-		totalBalance := uint64(0)
-
-		g := errgroup.Group{}
-		g.Go(func() error {
-			latestBalances, err := BigtableClient.GetValidatorBalanceHistory(validatorIndices, lastFinalizedEpoch, lastFinalizedEpoch)
-			if err != nil {
-				logger.Errorf("error getting validator balance data in GetValidatorEarnings: %v", err)
-				return err
-			}
-
-			for _, balance := range latestBalances {
-				if len(balance) == 0 {
-					continue
-				}
-
-				totalBalance += balance[0].Balance
-			}
-			return nil
-		})
+	batchSize = insertBatchSize("orphaned_el_rewards", utils.Config.Statistics.ElRewardsBatchSize, 1000, orphanedElRewardsNumArgs)
+	orphanedProposers := make([]uint64, 0, len(orphanedRewards))
+	for proposer := range orphanedRewards {
+		orphanedProposers = append(orphanedProposers, proposer)
+	}
+	sort.Slice(orphanedProposers, func(i, j int) bool { return orphanedProposers[i] < orphanedProposers[j] })
+	for b := 0; b < len(orphanedProposers); b += batchSize {
+		end := b + batchSize
+		if len(orphanedProposers) < end {
+			end = len(orphanedProposers)
+		}
+		if err = saveOrphanedElRewardsBatch(tx, orphanedProposers[b:end], orphanedRewards, day); err != nil {
+			return err
+		}
+	}
 
-		var lastBalance uint64
-		g.Go(func() error {
-			return GetValidatorBalanceForDay(validatorIndices, lastDay, &lastBalance)
-		})
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
 
-		var lastDeposits uint64
-		g.Go(func() error {
-			return GetValidatorDepositsForEpochs(validatorIndices, firstEpoch, lastFinalizedEpoch, &lastDeposits)
-		})
+	if err = markColumnExported(day, "el_rewards_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
 
-		var lastWithdrawals uint64
-		g.Go(func() error {
-			return GetValidatorWithdrawalsForEpochs(validatorIndices, firstEpoch, lastFinalizedEpoch, &lastWithdrawals)
-		})
+	logger.Infof("el rewards statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
 
-		err = g.Wait()
-		if err != nil {
-			return nil, err
-		}
+const elRewardsNumArgs = 7
 
-		result = append(result, types.ValidatorIncomeHistory{
-			Day:       int64(currentDay),
-			ClRewards: int64(totalBalance - lastBalance - lastDeposits + lastWithdrawals),
-		})
+func saveElRewardsBatch(tx *sqlx.Tx, proposers []uint64, proposerRewards map[uint64]*proposerElRewards, day uint64) error {
+	valueStrings := make([]string, 0, len(proposers))
+	valueArgs := make([]interface{}, 0, len(proposers)*elRewardsNumArgs)
+	for i, proposer := range proposers {
+		n := i * elRewardsNumArgs
+		rewards := proposerRewards[proposer]
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7))
+		valueArgs = append(valueArgs, proposer, day, rewards.TxFeeReward.String(), rewards.MevReward.String(), rewards.HadRelayData, rewards.MevBlocks, rewards.LocalBlocks)
+	}
+	_, err := tx.Exec(fmt.Sprintf(`
+		INSERT INTO validator_stats (validatorindex, day, el_rewards_wei, mev_rewards_wei, had_relay_data, mev_blocks, local_blocks) VALUES
+		%s
+		ON CONFLICT(validatorindex, day) DO UPDATE SET el_rewards_wei = excluded.el_rewards_wei, mev_rewards_wei = excluded.mev_rewards_wei, had_relay_data = excluded.had_relay_data, mev_blocks = excluded.mev_blocks, local_blocks = excluded.local_blocks;`,
+		strings.Join(valueStrings, ",")), valueArgs...)
+	if err != nil {
+		return fmt.Errorf("error inserting el rewards of day %v: %w", day, err)
 	}
+	return nil
+}
 
-	go func() {
-		err := cache.TieredCache.Set(cacheKey, result, cacheDur)
-		if err != nil {
-			utils.LogError(err, fmt.Errorf("error setting tieredCache for GetValidatorIncomeHistory with key %v", cacheKey), 0)
-		}
-	}()
+const orphanedElRewardsNumArgs = 3
 
-	return result, nil
+func saveOrphanedElRewardsBatch(tx *sqlx.Tx, proposers []uint64, orphanedRewards map[uint64]*big.Int, day uint64) error {
+	valueStrings := make([]string, 0, len(proposers))
+	valueArgs := make([]interface{}, 0, len(proposers)*orphanedElRewardsNumArgs)
+	for i, proposer := range proposers {
+		n := i * orphanedElRewardsNumArgs
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", n+1, n+2, n+3))
+		valueArgs = append(valueArgs, proposer, day, orphanedRewards[proposer].String())
+	}
+	_, err := tx.Exec(fmt.Sprintf(`
+		INSERT INTO validator_stats (validatorindex, day, orphaned_el_rewards_wei) VALUES
+		%s
+		ON CONFLICT(validatorindex, day) DO UPDATE SET orphaned_el_rewards_wei = excluded.orphaned_el_rewards_wei;`,
+		strings.Join(valueStrings, ",")), valueArgs...)
+	if err != nil {
+		return fmt.Errorf("error inserting orphaned el rewards of day %v: %w", day, err)
+	}
+	return nil
 }
 
-func WriteChartSeriesForDay(day int64) error {
-	startTs := time.Now()
+// WriteRelayStatsForDay stores the number of blocks and the mev rewards delivered by each relay during the day in the relay_stats table
+func WriteRelayStatsForDay(day uint64) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline("relay_stats")))
+	defer cancel()
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_relay_stats").Observe(time.Since(exportStart).Seconds())
+	}()
 
-	if day < 0 {
-		// before the beaconchain
-		return fmt.Errorf("this function does not yet pre-beaconchain blocks")
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
 	}
 
-	epochsPerDay := utils.EpochsPerDay()
-	beaconchainDay := day * int64(epochsPerDay)
+	unlock, err := lockStatisticsExport(day, "relay_stats")
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	startDate := utils.EpochToTime(uint64(beaconchainDay))
-	dateTrunc := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
 
-	// inclusive slot
-	firstSlot := utils.TimeToSlot(uint64(dateTrunc.Unix()))
+	start := time.Now()
 
-	epochOffset := firstSlot % utils.Config.Chain.Config.SlotsPerEpoch
-	firstSlot = firstSlot - epochOffset
-	firstEpoch := firstSlot / utils.Config.Chain.Config.SlotsPerEpoch
-	// exclusive slot
-	lastSlot := int64(firstSlot) + int64(epochsPerDay*utils.Config.Chain.Config.SlotsPerEpoch)
-	lastEpoch := lastSlot / int64(utils.Config.Chain.Config.SlotsPerEpoch)
+	logger.Infof("exporting relay stats")
 
-	finalizedCount, err := CountFinalizedEpochs(firstEpoch, uint64(lastEpoch))
+	numbers := []uint64{}
+	err = ReaderDb.Select(&numbers, "SELECT exec_block_number FROM blocks WHERE epoch >= $1 AND epoch <= $2 AND exec_block_number > 0 AND status = '1'", firstEpoch, lastEpoch)
 	if err != nil {
-		return err
+		return fmt.Errorf("error retrieving blocks data: %v", err)
 	}
 
-	if finalizedCount < epochsPerDay {
-		return fmt.Errorf("delaying chart series export as not all epochs for day %v finalized. %v of %v", day, finalizedCount, epochsPerDay)
+	blocksData, err := getBlocksIndexedChunked(ctx, numbers)
+	if err != nil {
+		return fmt.Errorf("error in GetBlocksIndexedMultiple: %v", err)
 	}
 
-	firstBlock, err := GetBlockNumber(uint64(firstSlot))
+	relaysData, err := getRelayDataForIndexedBlocksCached(blocksData)
 	if err != nil {
-		return fmt.Errorf("error getting block number for slot: %v err: %w", firstSlot, err)
+		return fmt.Errorf("error in GetRelayDataForIndexedBlocks: %v", err)
 	}
 
-	if firstBlock <= 15537394 {
-		return fmt.Errorf("this function does not yet handle pre merge statistics")
+	relayStats := aggregateRelayStats(blocksData, relaysData)
+	logrus.Infof("retrieved relay stats for %v relays", len(relayStats))
+
+	if skipDryRunWrites("relay_stats", day, len(relayStats)) {
+		return nil
 	}
 
-	lastBlock, err := GetBlockNumber(uint64(lastSlot))
+	tx, err := WriterDb.Beginx()
 	if err != nil {
-		return fmt.Errorf("error getting block number for slot: %v err: %w", lastSlot, err)
+		return err
 	}
-	logger.Infof("exporting chart_series for day %v ts: %v (slot %v to %v, block %v to %v)", day, dateTrunc, firstSlot, lastSlot, firstBlock, lastBlock)
+	defer tx.Rollback()
 
-	blocksChan := make(chan *types.Eth1Block, 360)
-	batchSize := int64(360)
-	go func(stream chan *types.Eth1Block) {
-		logger.Infof("querying blocks from %v to %v", firstBlock, lastBlock)
+	_, err = tx.Exec("DELETE FROM relay_stats WHERE day = $1", day)
+	if err != nil {
+		return err
+	}
+
+	if len(relayStats) > 0 {
+		numArgs := 5
+		valueStrings := make([]string, 0, len(relayStats))
+		valueArgs := make([]interface{}, 0, len(relayStats)*numArgs)
+		i := 0
+		for relay, stats := range relayStats {
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3, i*numArgs+4, i*numArgs+5))
+			valueArgs = append(valueArgs, day)
+			valueArgs = append(valueArgs, relay)
+			valueArgs = append(valueArgs, stats.Blocks)
+			valueArgs = append(valueArgs, stats.MevReward.String())
+			valueArgs = append(valueArgs, stats.AverageBid().String())
+			i++
+		}
+		stmt := fmt.Sprintf(`
+			INSERT INTO relay_stats (day, relay, blocks, mev_rewards_wei, avg_bid_wei) VALUES
+			%s`,
+			strings.Join(valueStrings, ","))
+		_, err = tx.Exec(stmt, valueArgs...)
+		if err != nil {
+			return err
+		}
+	}
+	observeRowsExported("relay_stats", len(relayStats))
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err = markColumnExported(day, "relay_stats_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("relay stats export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// relayDayStats holds the blocks and the mev rewards (in wei) a relay delivered during a day
+type relayDayStats struct {
+	Blocks    uint64
+	MevReward *big.Int
+}
+
+// AverageBid returns the average mev reward of the blocks delivered by the relay
+func (r *relayDayStats) AverageBid() *big.Int {
+	if r == nil || r.Blocks == 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Div(r.MevReward, new(big.Int).SetUint64(r.Blocks))
+}
+
+// aggregateRelayStats sums up the blocks and mev rewards of the given blocks by the tag of the relay that delivered them.
+// Locally built blocks without relay data are not counted.
+func aggregateRelayStats(blocksData []*types.Eth1BlockIndexed, relaysData map[common.Hash]types.RelaysData) map[string]*relayDayStats {
+	relayStats := make(map[string]*relayDayStats)
+	for _, b := range blocksData {
+		relayData, ok := relaysData[common.BytesToHash(b.Hash)]
+		if !ok {
+			continue
+		}
+		if relayStats[relayData.TagID] == nil {
+			relayStats[relayData.TagID] = &relayDayStats{MevReward: big.NewInt(0)}
+		}
+		relayStats[relayData.TagID].Blocks++
+		relayStats[relayData.TagID].MevReward = new(big.Int).Add(relayData.MevBribe.BigInt(), relayStats[relayData.TagID].MevReward)
+	}
+	return relayStats
+}
+
+// aggregateOrphanedElRewards sums up the tx fee rewards of the orphaned blocks per proposer, they are stored separately in
+// orphaned_el_rewards_wei and never counted in the canonical el_rewards_wei. Bigtable indexes execution blocks by number, so
+// usually the canonical block that replaced an orphaned one is returned. Only indexed blocks whose hash matches the payload of
+// an orphaned block are counted, the rewards of other orphaned payloads are unknown.
+func aggregateOrphanedElRewards(blocksData []*types.Eth1BlockIndexed, orphanedBlockProposers map[common.Hash]uint64) map[uint64]*big.Int {
+	rewards := make(map[uint64]*big.Int)
+	for _, b := range blocksData {
+		proposer, ok := orphanedBlockProposers[common.BytesToHash(b.Hash)]
+		if !ok {
+			continue
+		}
+		if rewards[proposer] == nil {
+			rewards[proposer] = big.NewInt(0)
+		}
+		rewards[proposer].Add(rewards[proposer], new(big.Int).SetBytes(b.TxReward))
+	}
+	return rewards
+}
+
+// getBlocksIndexedChunked fetches the indexed blocks in chunks of the configured size with a bounded number of concurrent Bigtable reads
+func getBlocksIndexedChunked(ctx context.Context, numbers []uint64) ([]*types.Eth1BlockIndexed, error) {
+	batchSize := utils.Config.Statistics.ElBlocksBatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	concurrency := utils.Config.Statistics.ElBlocksConcurrency
+	if concurrency <= 0 {
+		concurrency = 5
+	}
+
+	blocks := make([]*types.Eth1BlockIndexed, 0, len(numbers))
+	mux := sync.Mutex{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for b := 0; b < len(numbers); b += batchSize {
+		start := b
+		end := b + batchSize
+		if len(numbers) < end {
+			end = len(numbers)
+		}
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
+			var batch []*types.Eth1BlockIndexed
+			err := retryBigtable("GetBlocksIndexedMultiple", func() error {
+				var err error
+				batch, err = BigtableClient.GetBlocksIndexedMultiple(numbers[start:end], uint64(end-start))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			mux.Lock()
+			blocks = append(blocks, batch...)
+			mux.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// proposerElRewards holds the el rewards of a proposer for a day. HadRelayData is false if the mev reward of at least one
+// of the proposed blocks fell back to the tx fee reward because the block was not found in the relays data. MevBlocks and
+// LocalBlocks count the proposed blocks that were built by a relay and the ones that were built locally.
+type proposerElRewards struct {
+	TxFeeReward  *big.Int
+	MevReward    *big.Int
+	HadRelayData bool
+	MevBlocks    uint64
+	LocalBlocks  uint64
+}
+
+// MevShare returns the share of the proposed blocks that were built by a relay, nil if the proposer had no blocks
+func (r *proposerElRewards) MevShare() *float64 {
+	if r == nil || r.MevBlocks+r.LocalBlocks == 0 {
+		return nil
+	}
+	share := float64(r.MevBlocks) / float64(r.MevBlocks+r.LocalBlocks)
+	return &share
+}
+
+// mevBribeOverrides returns the configured proposer payments (in wei) of blocks keyed by block hash. They are known out-of-band,
+// e.g. for new relays or private order flow, and used for blocks without relay data.
+func mevBribeOverrides() (map[common.Hash]*big.Int, error) {
+	overrides := make(map[common.Hash]*big.Int, len(utils.Config.Statistics.MevBribeOverrides))
+	for hash, amount := range utils.Config.Statistics.MevBribeOverrides {
+		hashBytes, err := hex.DecodeString(strings.TrimPrefix(hash, "0x"))
+		if err != nil || len(hashBytes) != common.HashLength {
+			return nil, fmt.Errorf("invalid block hash %q in mev bribe overrides", hash)
+		}
+		bribe, ok := new(big.Int).SetString(amount, 10)
+		if !ok || bribe.Sign() < 0 {
+			return nil, fmt.Errorf("invalid mev bribe %q of block %v in mev bribe overrides", amount, hash)
+		}
+		overrides[common.BytesToHash(hashBytes)] = bribe
+	}
+	return overrides, nil
+}
+
+// aggregateProposerElRewards sums up the el rewards of the blocks per proposer. The mev reward of a block is taken from the relay
+// data if present, otherwise from the configured overrides and if there is none it falls back to the tx fee reward.
+func aggregateProposerElRewards(blocksData []*types.Eth1BlockIndexed, blockProposers map[uint64]uint64, relaysData map[common.Hash]types.RelaysData, overrides map[common.Hash]*big.Int) map[uint64]*proposerElRewards {
+	proposerRewards := make(map[uint64]*proposerElRewards)
+	for _, b := range blocksData {
+		proposer := blockProposers[b.Number]
+
+		if proposerRewards[proposer] == nil {
+			proposerRewards[proposer] = &proposerElRewards{
+				MevReward:    big.NewInt(0),
+				TxFeeReward:  big.NewInt(0),
+				HadRelayData: true,
+			}
+		}
+
+		txFeeReward := new(big.Int).SetBytes(b.TxReward)
+		proposerRewards[proposer].TxFeeReward = new(big.Int).Add(txFeeReward, proposerRewards[proposer].TxFeeReward)
+
+		mevReward, ok := relaysData[common.BytesToHash(b.Hash)]
+		override, overridden := overrides[common.BytesToHash(b.Hash)]
+
+		if ok {
+			proposerRewards[proposer].MevReward = new(big.Int).Add(mevReward.MevBribe.BigInt(), proposerRewards[proposer].MevReward)
+			proposerRewards[proposer].MevBlocks++
+		} else if overridden {
+			proposerRewards[proposer].MevReward = new(big.Int).Add(override, proposerRewards[proposer].MevReward)
+			proposerRewards[proposer].MevBlocks++
+		} else {
+			proposerRewards[proposer].MevReward = new(big.Int).Add(txFeeReward, proposerRewards[proposer].MevReward)
+			proposerRewards[proposer].HadRelayData = false
+			proposerRewards[proposer].LocalBlocks++
+		}
+	}
+	return proposerRewards
+}
+
+// relayDataCache keeps the relay data of blocks by their hash so backfills covering several days don't fetch the same rows
+// again. Blocks without relay data are not cached as the relay data might still be imported later on.
+var relayDataCache, _ = lru.New(100000)
+
+func getRelayDataForIndexedBlocksCached(blocks []*types.Eth1BlockIndexed) (map[common.Hash]types.RelaysData, error) {
+	relaysData := make(map[common.Hash]types.RelaysData)
+	missing := make([]*types.Eth1BlockIndexed, 0, len(blocks))
+	for _, b := range blocks {
+		hash := common.BytesToHash(b.Hash)
+		if cached, ok := relayDataCache.Get(hash); ok {
+			relaysData[hash] = cached.(types.RelaysData)
+		} else {
+			missing = append(missing, b)
+		}
+	}
+
+	if len(missing) == 0 {
+		return relaysData, nil
+	}
+
+	fetched, err := GetRelayDataForIndexedBlocks(missing)
+	if err != nil {
+		return nil, err
+	}
+	for hash, data := range fetched {
+		relayDataCache.Add(hash, data)
+		relaysData[hash] = data
+	}
+	return relaysData, nil
+}
+
+func WriteValidatorClIcome(day uint64) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline("cl_rewards")))
+	defer cancel()
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_cl_income_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "cl_rewards")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	start := time.Now()
+	logger.Infof("validating if required data has been exported for cl rewards")
+	type Exported struct {
+		LastBalanceExported                bool `db:"last_balance_exported"`
+		CurrentBalanceExported             bool `db:"cur_balance_exported"`
+		CurrentWithdrawalsDepositsExported bool `db:"cur_withdrawals_deposits_exported"`
+	}
+	exported := Exported{}
+	err = ReaderDb.Get(&exported, `
+		SELECT last.balance_exported as last_balance_exported, cur.balance_exported as cur_balance_exported, cur.withdrawals_deposits_exported as cur_withdrawals_deposits_exported
+		FROM validator_stats_status cur
+		INNER JOIN validator_stats_status last 
+				ON last.day = GREATEST(cur.day - 1, 0)
+		WHERE cur.day = $1;
+	`, day)
+
+	if err != nil {
+		err = fmt.Errorf("error retrieving required data: %v", err)
+	} else {
+		err = missingDependencyError(day, map[string]bool{
+			"cur_balance_exported":              exported.CurrentBalanceExported,
+			"cur_withdrawals_deposits_exported": exported.CurrentWithdrawalsDepositsExported,
+			"last_balance_exported":             exported.LastBalanceExported,
+		})
+	}
+	if err = ignoreInDryRun(err); err != nil {
+		return err
+	}
+	logger.Infof("validating took %v", time.Since(start))
+
+	start = time.Now()
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	logger.Infof("exporting cl_rewards_wei statistics")
+	var incomeStats map[uint64]*itypes.ValidatorEpochIncome
+	err = retryBigtable("GetAggregatedValidatorIncomeDetailsHistory", func() error {
+		var err error
+		incomeStats, err = BigtableClient.GetAggregatedValidatorIncomeDetailsHistory(exportedValidators(), firstEpoch, lastEpoch)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	logrus.Infof("getting cl income done in %v, now we export them to the db", time.Since(start))
+	start = time.Now()
+
+	maxValidatorIndex := uint64(0)
+
+	for validator := range incomeStats {
+		if validator > maxValidatorIndex {
+			maxValidatorIndex = validator
+		}
+	}
+	maxValidatorIndex++
+
+	if skipDryRunWrites("cl_rewards", day, len(incomeStats)) {
+		return nil
+	}
+	if noDataForDay("cl_rewards", day, len(incomeStats)) {
+		// the cl rewards are derived from the balances, only the proposer and net rewards are read from the income of the day
+		err = forEachValidatorBatch(day, "cl_rewards", func(start, end int) error {
+			return writeClRewardsBatch(day, start, end)
+		})
+		if err != nil {
+			return err
+		}
+		logger.Infof("export completed, took %v", time.Since(start))
+		return markColumnExported(day, "cl_rewards_exported", time.Since(exportStart))
+	}
+
+	progress := newExportProgress("cl_rewards")
+	g, gCtx := errgroup.WithContext(ctx)
+
+	numArgs := 7
+	batchSize := insertBatchSize("cl_rewards", utils.Config.Statistics.ClRewardsBatchSize, 100, numArgs) // smaller batches are faster
+	for b := 0; b < int(maxValidatorIndex); b += batchSize {
+		start := b
+		end := b + batchSize
+		if int(maxValidatorIndex) < end {
+			end = int(maxValidatorIndex)
+		}
+
+		logrus.Info(start, end)
+		valueStrings := make([]string, 0, batchSize)
+		valueArgs := make([]interface{}, 0, batchSize*numArgs)
+		for i := start; i < end; i++ {
+			if !isExportedValidator(uint64(i)) {
+				continue
+			}
+			proposerRewards := newProposerRewardsBreakdown(incomeStats[uint64(i)])
+
+			n := len(valueStrings)
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)", n*numArgs+1, n*numArgs+2, n*numArgs+3, n*numArgs+4, n*numArgs+5, n*numArgs+6, n*numArgs+7))
+			valueArgs = append(valueArgs, i)
+			valueArgs = append(valueArgs, day)
+			valueArgs = append(valueArgs, proposerRewards.Total())
+			valueArgs = append(valueArgs, proposerRewards.AttestationInclusion)
+			valueArgs = append(valueArgs, proposerRewards.SyncInclusion)
+			valueArgs = append(valueArgs, proposerRewards.SlashingInclusion)
+			valueArgs = append(valueArgs, clRewardsNet(incomeStats[uint64(i)]))
+		}
+		stmt := fmt.Sprintf(`
+		insert into validator_stats (validatorindex, day, cl_proposer_rewards_gwei, cl_proposer_attestation_inclusion_rewards_gwei, cl_proposer_sync_inclusion_rewards_gwei, cl_proposer_slashing_inclusion_rewards_gwei, cl_rewards_gwei_net) VALUES
+		%s
+		on conflict (validatorindex, day) do update set 
+			cl_proposer_rewards_gwei = excluded.cl_proposer_rewards_gwei, 
+			cl_proposer_attestation_inclusion_rewards_gwei = excluded.cl_proposer_attestation_inclusion_rewards_gwei, 
+			cl_proposer_sync_inclusion_rewards_gwei = excluded.cl_proposer_sync_inclusion_rewards_gwei, 
+			cl_proposer_slashing_inclusion_rewards_gwei = excluded.cl_proposer_slashing_inclusion_rewards_gwei, 
+			cl_rewards_gwei_net = excluded.cl_rewards_gwei_net;`,
+			strings.Join(valueStrings, ","))
+		if len(valueStrings) == 0 {
+			continue
+		}
+
+		progress.batchScheduled()
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
+			_, err := WriterDb.Exec(stmt, valueArgs...)
+			if err != nil {
+				return err
+			}
+			logrus.Infof("saving validator proposer rewards and net cl rewards gwei batch %v completed", start)
+			if err := writeClRewardsBatch(day, start, end); err != nil {
+				return err
+			}
+			progress.batchCompleted()
+			logrus.Infof("saving validator cl rewards gwei batch %v completed", start)
+			return nil
+		})
+	}
+
+	if err = g.Wait(); err != nil {
+		logrus.Error(err)
+		return err
+	}
+
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err = markColumnExported(day, "cl_rewards_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("cl rewards statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// writeClRewardsBatch writes the cl rewards of the validators [start, end) of the day, which are derived from their end balances
+// of the day and the day before corrected by their withdrawals and deposits
+func writeClRewardsBatch(day uint64, start, end int) error {
+	var err error
+	if day == 0 {
+		// genesis validators have no previous day, their genesis deposits are stored at day -1 and have to be
+		// subtracted in addition to the deposits of day 0
+		err = writeGenesisDayClRewardsBatch(start, end)
+	} else {
+		_, err = WriterDb.Exec(`
+			INSERT INTO validator_stats (validatorindex, day, cl_rewards_gwei) 
+			(
+				SELECT cur.validatorindex, cur.day, COALESCE(cur.end_balance, 0) - COALESCE(last.end_balance, 0) + COALESCE(cur.withdrawals_amount, 0) - COALESCE(cur.deposits_amount, 0) AS cl_rewards_gwei
+				FROM validator_stats cur
+				INNER JOIN validator_stats last 
+					ON cur.validatorindex = last.validatorindex AND last.day = cur.day - 1
+				WHERE cur.day = $1 AND cur.validatorindex >= $2 AND cur.validatorindex < $3 AND last.end_balance IS NOT NULL
+			)
+			ON CONFLICT (validatorindex, day) DO
+				UPDATE SET cl_rewards_gwei = excluded.cl_rewards_gwei;`, day, start, end)
+		if err == nil {
+			// validators that did not exist at the end of the previous day have no balance to derive their rewards from
+			err = writeActivationDayClRewardsBatch(day, start, end)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	observeRowsExported("cl_rewards", end-start)
+	return nil
+}
+
+type genesisDayBalance struct {
+	ValidatorIndex        uint64 `db:"validatorindex"`
+	EndBalance            int64  `db:"end_balance"`
+	WithdrawalsAmount     int64  `db:"withdrawals_amount"`
+	DepositsAmount        int64  `db:"deposits_amount"`
+	GenesisDepositsAmount int64  `db:"genesis_deposits_amount"`
+}
+
+// genesisDayClRewards returns the cl rewards of day 0. As there is no end balance of a previous day all deposits, including
+// the genesis deposits stored at day -1, are subtracted from the end balance.
+func genesisDayClRewards(b *genesisDayBalance) int64 {
+	return b.EndBalance + b.WithdrawalsAmount - b.DepositsAmount - b.GenesisDepositsAmount
+}
+
+func writeGenesisDayClRewardsBatch(start, end int) error {
+	balances := []*genesisDayBalance{}
+	err := WriterDb.Select(&balances, `
+		SELECT
+			cur.validatorindex,
+			COALESCE(cur.end_balance, 0) AS end_balance,
+			COALESCE(cur.withdrawals_amount, 0) AS withdrawals_amount,
+			COALESCE(cur.deposits_amount, 0) AS deposits_amount,
+			COALESCE(genesis.deposits_amount, 0) AS genesis_deposits_amount
+		FROM validator_stats cur
+		LEFT JOIN validator_stats genesis
+			ON genesis.validatorindex = cur.validatorindex AND genesis.day = -1
+		WHERE cur.day = 0 AND cur.validatorindex >= $1 AND cur.validatorindex < $2`, start, end)
+	if err != nil {
+		return fmt.Errorf("error retrieving balances of day 0: %w", err)
+	}
+	if len(balances) == 0 {
+		return nil
+	}
+
+	numArgs := 3
+	valueStrings := make([]string, 0, len(balances))
+	valueArgs := make([]interface{}, 0, len(balances)*numArgs)
+	for i, b := range balances {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3))
+		valueArgs = append(valueArgs, b.ValidatorIndex)
+		valueArgs = append(valueArgs, 0)
+		valueArgs = append(valueArgs, genesisDayClRewards(b))
+	}
+	stmt := fmt.Sprintf(`
+		insert into validator_stats (validatorindex, day, cl_rewards_gwei) VALUES
+		%s
+		on conflict (validatorindex, day) do update set cl_rewards_gwei = excluded.cl_rewards_gwei;`,
+		strings.Join(valueStrings, ","))
+	_, err = WriterDb.Exec(stmt, valueArgs...)
+	return err
+}
+
+type activationDayBalance struct {
+	ValidatorIndex    uint64 `db:"validatorindex"`
+	StartBalance      int64  `db:"start_balance"`
+	EndBalance        int64  `db:"end_balance"`
+	WithdrawalsAmount int64  `db:"withdrawals_amount"`
+	DepositsAmount    int64  `db:"deposits_amount"`
+}
+
+// activationDayClRewards returns the cl rewards of the first day a validator has a balance. The balance it appeared with
+// is its initial deposit and not income, so the rewards are derived from the start balance instead of the (missing) end
+// balance of the previous day. If the initial deposit has been included during the day it is already part of the start
+// balance, so only the deposits exceeding the start balance are subtracted as top-ups.
+func activationDayClRewards(b *activationDayBalance) int64 {
+	topUps := b.DepositsAmount - b.StartBalance
+	if topUps < 0 {
+		topUps = 0
+	}
+	return b.EndBalance - b.StartBalance + b.WithdrawalsAmount - topUps
+}
+
+func writeActivationDayClRewardsBatch(day uint64, start, end int) error {
+	balances := []*activationDayBalance{}
+	err := WriterDb.Select(&balances, `
+		SELECT
+			cur.validatorindex,
+			COALESCE(cur.start_balance, 0) AS start_balance,
+			COALESCE(cur.end_balance, 0) AS end_balance,
+			COALESCE(cur.withdrawals_amount, 0) AS withdrawals_amount,
+			COALESCE(cur.deposits_amount, 0) AS deposits_amount
+		FROM validator_stats cur
+		LEFT JOIN validator_stats last
+			ON last.validatorindex = cur.validatorindex AND last.day = cur.day - 1
+		WHERE cur.day = $1 AND cur.validatorindex >= $2 AND cur.validatorindex < $3 AND cur.end_balance IS NOT NULL AND last.end_balance IS NULL`, day, start, end)
+	if err != nil {
+		return fmt.Errorf("error retrieving balances of validators activated on day %v: %w", day, err)
+	}
+	if len(balances) == 0 {
+		return nil
+	}
+
+	numArgs := 3
+	valueStrings := make([]string, 0, len(balances))
+	valueArgs := make([]interface{}, 0, len(balances)*numArgs)
+	for i, b := range balances {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3))
+		valueArgs = append(valueArgs, b.ValidatorIndex)
+		valueArgs = append(valueArgs, day)
+		valueArgs = append(valueArgs, activationDayClRewards(b))
+	}
+	stmt := fmt.Sprintf(`
+		insert into validator_stats (validatorindex, day, cl_rewards_gwei) VALUES
+		%s
+		on conflict (validatorindex, day) do update set cl_rewards_gwei = excluded.cl_rewards_gwei;`,
+		strings.Join(valueStrings, ","))
+	_, err = WriterDb.Exec(stmt, valueArgs...)
+	return err
+}
+
+// proposerRewardsBreakdown splits the consensus proposer rewards of a validator by what the included block contained
+type proposerRewardsBreakdown struct {
+	AttestationInclusion uint64
+	SyncInclusion        uint64
+	SlashingInclusion    uint64
+}
+
+func newProposerRewardsBreakdown(income *itypes.ValidatorEpochIncome) proposerRewardsBreakdown {
+	if income == nil {
+		return proposerRewardsBreakdown{}
+	}
+	return proposerRewardsBreakdown{
+		AttestationInclusion: income.ProposerAttestationInclusionReward,
+		SyncInclusion:        income.ProposerSyncInclusionReward,
+		SlashingInclusion:    income.ProposerSlashingInclusionReward,
+	}
+}
+
+// Total returns the combined proposer rewards as stored in cl_proposer_rewards_gwei
+func (b proposerRewardsBreakdown) Total() uint64 {
+	return b.AttestationInclusion + b.SyncInclusion + b.SlashingInclusion
+}
+
+// clRewardsNet returns the consensus rewards of a validator as the sum of its duty rewards and penalties. Other than
+// cl_rewards_gwei it is not derived from balance deltas, so activations and top-up deposits don't affect it.
+func clRewardsNet(income *itypes.ValidatorEpochIncome) int64 {
+	if income == nil {
+		return 0
+	}
+	return income.TotalClRewards()
+}
+
+// WriteValidatorSlashingIncome stores the slashing related consensus income of every validator of the day separately in
+// slashing_reward_gwei, consisting of the proposer reward for including slashings and the whistleblower reward
+func WriteValidatorSlashingIncome(day uint64) error {
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_slashing_income").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "slashing_income")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	start := time.Now()
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	logger.Infof("exporting slashing_reward_gwei statistics")
+	var incomeStats map[uint64]*itypes.ValidatorEpochIncome
+	err = retryBigtable("GetAggregatedValidatorIncomeDetailsHistory", func() error {
+		var err error
+		incomeStats, err = BigtableClient.GetAggregatedValidatorIncomeDetailsHistory(exportedValidators(), firstEpoch, lastEpoch)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	logger.Infof("getting slashing income done in %v, now we export them to the db", time.Since(start))
+
+	rewards := slashingIncome(incomeStats)
+
+	if skipDryRunWrites("slashing_income", day, len(rewards)) {
+		return nil
+	}
+	// without data the rewards of a previous export of the day are still reset below
+	noDataForDay("slashing_income", day, len(incomeStats))
+
+	start = time.Now()
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// a previous export of the day may have stored rewards for validators that no longer have any
+	_, err = tx.Exec("UPDATE validator_stats SET slashing_reward_gwei = NULL WHERE day = $1 AND slashing_reward_gwei IS NOT NULL", day)
+	if err != nil {
+		return err
+	}
+
+	validators := make([]uint64, 0, len(rewards))
+	for validator := range rewards {
+		validators = append(validators, validator)
+	}
+	sort.Slice(validators, func(i, j int) bool { return validators[i] < validators[j] })
+
+	numArgs := 3
+	batchSize := 10000 // max parameters: 65535 / 3
+	for b := 0; b < len(validators); b += batchSize {
+		end := b + batchSize
+		if len(validators) < end {
+			end = len(validators)
+		}
+
+		valueStrings := make([]string, 0, end-b)
+		valueArgs := make([]interface{}, 0, (end-b)*numArgs)
+		for i, validator := range validators[b:end] {
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3))
+			valueArgs = append(valueArgs, validator)
+			valueArgs = append(valueArgs, day)
+			valueArgs = append(valueArgs, rewards[validator])
+		}
+		stmt := fmt.Sprintf(`
+			insert into validator_stats (validatorindex, day, slashing_reward_gwei) VALUES
+			%s
+			on conflict (validatorindex, day) do update set slashing_reward_gwei = excluded.slashing_reward_gwei;`,
+			strings.Join(valueStrings, ","))
+		_, err = tx.Exec(stmt, valueArgs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	observeRowsExported("slashing_income", len(validators))
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err = markColumnExported(day, "slashing_income_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("slashing income statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// slashingIncome returns the slashing income of every validator that received any, being the sum of the proposer reward
+// for including slashings and the whistleblower reward
+func slashingIncome(incomeStats map[uint64]*itypes.ValidatorEpochIncome) map[uint64]uint64 {
+	rewards := make(map[uint64]uint64)
+	for validator, income := range incomeStats {
+		if income == nil {
+			continue
+		}
+		if reward := income.ProposerSlashingInclusionReward + income.SlashingReward; reward > 0 {
+			rewards[validator] = reward
+		}
+	}
+	return rewards
+}
+
+func WriteValidatorBalances(day uint64) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline("balance")))
+	defer cancel()
+
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_balances_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "balance")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	start := time.Now()
+
+	// with a checkpoint only the balances of the validators after it are read, the ones up to it have been written by a previous run
+	useCheckpoint := utils.Config.Statistics.BalancesCheckpoint && !validatorFilterActive()
+	var resumeAfter sql.NullInt64
+	if useCheckpoint {
+		resumeAfter, err = getBalanceCheckpoint(day)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("exporting min_balance, max_balance, min_effective_balance, max_effective_balance, start_balance, start_effective_balance, end_balance and end_effective_balance statistics")
+	var balanceStatistics map[uint64]*types.ValidatorBalanceStatistic
+	err = retryBigtable("GetValidatorBalanceStatistics", func() error {
+		var err error
+		if resumeAfter.Valid {
+			logger.Infof("resuming balance export of day %v after validator %v", day, resumeAfter.Int64)
+			balanceStatistics, err = BigtableClient.GetValidatorBalanceStatisticsAfterValidator(uint64(resumeAfter.Int64), firstEpoch, lastEpoch)
+			return err
+		}
+		balanceStatistics, err = BigtableClient.GetValidatorBalanceStatistics(firstEpoch, lastEpoch)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	balanceStatsArr := make([]*types.ValidatorBalanceStatistic, 0, len(balanceStatistics))
+	for validatorIndex, stat := range balanceStatistics {
+		// the balances of all validators are read at once, so the validator filter is applied here
+		if !isExportedValidator(validatorIndex) {
+			continue
+		}
+		balanceStatsArr = append(balanceStatsArr, stat)
+	}
+
+	if skipDryRunWrites("balances", day, len(balanceStatsArr)) {
+		return nil
+	}
+	if len(balanceStatsArr) == 0 && !resumeAfter.Valid {
+		// unlike the sparse sub-exports a day with activated validators always has balances, an empty read means they are missing
+		activated, err := countValidatorsActivatedUntil(lastEpoch)
+		if err != nil {
+			return err
+		}
+		if activated > 0 {
+			return fmt.Errorf("no balances of day %v found although %v validators have been activated, the balances might not have been exported to bigtable yet", day, activated)
+		}
+		logger.Infof("no balances data for day %v", day)
+		return markColumnExported(day, "balance_exported", time.Since(exportStart))
+	}
+	logger.Infof("fetching balance completed, took %v, now we save it", time.Since(start))
+	start = time.Now()
+
+	numArgs := 10
+	batchSize := insertBatchSize("balances", utils.Config.Statistics.BalancesBatchSize, 100, numArgs) // we are faster with smaller batch sizes
+
+	pending := balanceStatsArr
+	var checkpoint *balanceCheckpoint
+	if useCheckpoint {
+		// the checkpoint advances by validator index, so the batches are written in that order
+		sort.Slice(pending, func(i, j int) bool {
+			return pending[i].Index < pending[j].Index
+		})
+		checkpoint = newBalanceCheckpoint(day, pending, batchSize)
+	}
+
+	progress := newExportProgress("balances")
+	g, gCtx := errgroup.WithContext(ctx)
+
+	for b := 0; b < len(pending); b += batchSize {
+		start := b
+		end := b + batchSize
+		if len(pending) < end {
+			end = len(pending)
+		}
+
+		valueStrings := make([]string, 0, batchSize)
+		valueArgs := make([]interface{}, 0, batchSize*numArgs)
+
+		progress.batchScheduled()
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
+			defer logger.Infof("saving validator balance batch %v completed", start)
+			for i, stat := range pending[start:end] {
+				valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3, i*numArgs+4, i*numArgs+5, i*numArgs+6, i*numArgs+7, i*numArgs+8, i*numArgs+9, i*numArgs+10))
+				valueArgs = append(valueArgs, stat.Index)
+				valueArgs = append(valueArgs, day)
+				valueArgs = append(valueArgs, stat.MinBalance)
+				valueArgs = append(valueArgs, stat.MaxBalance)
+				valueArgs = append(valueArgs, stat.MinEffectiveBalance)
+				valueArgs = append(valueArgs, stat.MaxEffectiveBalance)
+				valueArgs = append(valueArgs, stat.StartBalance)
+				valueArgs = append(valueArgs, stat.StartEffectiveBalance)
+				valueArgs = append(valueArgs, stat.EndBalance)
+				valueArgs = append(valueArgs, stat.EndEffectiveBalance)
+			}
+			stmt := fmt.Sprintf(`
+				insert into validator_stats (validatorindex, day, min_balance, max_balance, min_effective_balance, max_effective_balance, start_balance, start_effective_balance, end_balance, end_effective_balance) VALUES
+				%s
+				on conflict (validatorindex, day) do update set min_balance = excluded.min_balance, max_balance = excluded.max_balance, min_effective_balance = excluded.min_effective_balance, max_effective_balance = excluded.max_effective_balance, start_balance = excluded.start_balance, start_effective_balance = excluded.start_effective_balance, end_balance = excluded.end_balance, end_effective_balance = excluded.end_effective_balance;`,
+				strings.Join(valueStrings, ","))
+			_, err := WriterDb.Exec(stmt, valueArgs...)
+			if err != nil {
+				return err
+			}
+			observeRowsExported("balances", end-start)
+			if checkpoint != nil {
+				if err := checkpoint.batchCompleted(start / batchSize); err != nil {
+					return err
+				}
+			}
+
+			progress.batchCompleted()
+			return nil
+		})
+	}
+
+	if err = g.Wait(); err != nil {
+		logrus.Error(err)
+		return err
+	}
+
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if resumeAfter.Valid {
+		// a resumed export only read the balances of some validators, the percentiles are computed from the written ones instead
+		err = writeStoredNetworkBalancePercentiles(day)
+	} else {
+		err = writeNetworkBalancePercentiles(day, balanceStatsArr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = markColumnExported(day, "balance_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+	if checkpoint != nil {
+		if _, err = WriterDb.Exec("UPDATE validator_stats_status SET balance_checkpoint = NULL WHERE day = $1", day); err != nil {
+			return fmt.Errorf("error clearing balance checkpoint of day %v: %w", day, err)
+		}
+	}
+
+	logger.Infof("balance statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// countValidatorsActivatedUntil returns the number of exported validators activated at or before the epoch
+func countValidatorsActivatedUntil(epoch uint64) (uint64, error) {
+	filterCondition, filterArgs := validatorFilterCondition("validatorindex", 2)
+	var count uint64
+	err := ReaderDb.Get(&count, "SELECT COUNT(*) FROM validators WHERE activationepoch <= $1"+filterCondition, append([]interface{}{epoch}, filterArgs...)...)
+	if err != nil {
+		return 0, fmt.Errorf("error counting the validators activated until epoch %v: %w", epoch, err)
+	}
+	return count, nil
+}
+
+// getBalanceCheckpoint returns the highest validator index up to which the balances of the day have been written by a previous run
+// of the export, NULL if there is none
+func getBalanceCheckpoint(day uint64) (sql.NullInt64, error) {
+	var checkpoint sql.NullInt64
+	err := WriterDb.Get(&checkpoint, "SELECT balance_checkpoint FROM validator_stats_status WHERE day = $1", day)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return checkpoint, fmt.Errorf("error retrieving balance checkpoint of day %v: %w", day, err)
+	}
+	return checkpoint, nil
+}
+
+// balanceCheckpoint stores the highest validator index up to which all balance batches of a day have been written. The batches
+// are written concurrently, so the checkpoint only advances once all batches before a completed one are completed as well.
+type balanceCheckpoint struct {
+	mu        sync.Mutex
+	day       uint64
+	lastIndex []uint64
+	completed []bool
+	next      int
+}
+
+// newBalanceCheckpoint returns the checkpoint of the batches of batchSize the sorted stats are written in
+func newBalanceCheckpoint(day uint64, stats []*types.ValidatorBalanceStatistic, batchSize int) *balanceCheckpoint {
+	c := &balanceCheckpoint{day: day}
+	for b := 0; b < len(stats); b += batchSize {
+		end := b + batchSize
+		if len(stats) < end {
+			end = len(stats)
+		}
+		c.lastIndex = append(c.lastIndex, stats[end-1].Index)
+	}
+	c.completed = make([]bool, len(c.lastIndex))
+	return c
+}
+
+// batchCompleted marks the batch as written and stores the checkpoint if it advanced. The checkpoint is stored while holding the lock,
+// so a checkpoint never overwrites a higher one.
+func (c *balanceCheckpoint) batchCompleted(batch int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.completed[batch] = true
+	advanced := false
+	for c.next < len(c.completed) && c.completed[c.next] {
+		c.next++
+		advanced = true
+	}
+	if !advanced {
+		return nil
+	}
+
+	_, err := WriterDb.Exec(`
+		INSERT INTO validator_stats_status (day, status, balance_checkpoint)
+		VALUES ($1, false, $2)
+		ON CONFLICT (day)
+			DO UPDATE SET balance_checkpoint = EXCLUDED.balance_checkpoint`, c.day, c.lastIndex[c.next-1])
+	if err != nil {
+		return fmt.Errorf("error storing balance checkpoint of day %v: %w", c.day, err)
+	}
+	return nil
+}
+
+// writeNetworkBalancePercentiles stores the 25th, 50th and 75th percentile of the end balances of all validators with a
+// non-zero balance into the network_stats_per_day table
+func writeNetworkBalancePercentiles(day uint64, balanceStats []*types.ValidatorBalanceStatistic) error {
+	if validatorFilterActive() {
+		logger.Infof("validator filter: skipping the balance percentiles of day %v", day)
+		return nil
+	}
+
+	start := time.Now()
+	logger.Infof("exporting balance percentiles for day %v", day)
+
+	balances := make([]uint64, 0, len(balanceStats))
+	for _, stat := range balanceStats {
+		if stat.EndBalance > 0 {
+			balances = append(balances, stat.EndBalance)
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i] < balances[j] })
+
+	_, err := WriterDb.Exec(`
+		INSERT INTO network_stats_per_day (day, balance_p25, balance_median, balance_p75)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (day) DO UPDATE SET
+			balance_p25 = excluded.balance_p25,
+			balance_median = excluded.balance_median,
+			balance_p75 = excluded.balance_p75;`,
+		day, int64(math.Round(percentile(balances, 0.25))), int64(math.Round(percentile(balances, 0.5))), int64(math.Round(percentile(balances, 0.75))))
+	if err != nil {
+		return fmt.Errorf("error saving balance percentiles of day %v: %w", day, err)
+	}
+
+	logger.Infof("export completed, took %v", time.Since(start))
+	return nil
+}
+
+// writeStoredNetworkBalancePercentiles stores the balance percentiles of the day like writeNetworkBalancePercentiles, computed from
+// the end balances already written to validator_stats
+func writeStoredNetworkBalancePercentiles(day uint64) error {
+	start := time.Now()
+	logger.Infof("exporting balance percentiles for day %v from the stored balances", day)
+
+	_, err := WriterDb.Exec(`
+		INSERT INTO network_stats_per_day (day, balance_p25, balance_median, balance_p75)
+		SELECT
+			$1,
+			COALESCE(ROUND(percentile_cont(0.25) WITHIN GROUP (ORDER BY end_balance)), 0),
+			COALESCE(ROUND(percentile_cont(0.5) WITHIN GROUP (ORDER BY end_balance)), 0),
+			COALESCE(ROUND(percentile_cont(0.75) WITHIN GROUP (ORDER BY end_balance)), 0)
+		FROM validator_stats
+		WHERE day = $1 AND end_balance > 0
+		ON CONFLICT (day) DO UPDATE SET
+			balance_p25 = excluded.balance_p25,
+			balance_median = excluded.balance_median,
+			balance_p75 = excluded.balance_p75;`, day)
+	if err != nil {
+		return fmt.Errorf("error saving balance percentiles of day %v: %w", day, err)
+	}
+
+	logger.Infof("export completed, took %v", time.Since(start))
+	return nil
+}
+
+// percentile returns the p-th percentile of the sorted values, interpolating linearly between the closest ranks like
+// the postgres percentile_cont function does
+func percentile(sorted []uint64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return float64(sorted[lower]) + (rank-float64(lower))*(float64(sorted[upper])-float64(sorted[lower]))
+}
+
+// GetNetworkBalancePercentiles returns the distribution of the validator end balances of the given day
+func GetNetworkBalancePercentiles(day uint64) (*types.NetworkBalancePercentiles, error) {
+	percentiles := &types.NetworkBalancePercentiles{}
+	err := ReaderDb.Get(percentiles, `
+		SELECT
+			day,
+			COALESCE(balance_p25, 0) AS balance_p25,
+			COALESCE(balance_median, 0) AS balance_median,
+			COALESCE(balance_p75, 0) AS balance_p75
+		FROM network_stats_per_day
+		WHERE day = $1`, day)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving balance percentiles of day %v: %w", day, err)
+	}
+	return percentiles, nil
+}
+
+// ErrStatisticsDayNotExported is returned if the statistics of the requested day have not been completely exported yet
+var ErrStatisticsDayNotExported = errors.New("statistics of day not exported")
+
+// networkStatsChunkSize is the number of validator_stats rows aggregated at a time by WriteNetworkStatsForDay
+const networkStatsChunkSize = 10000
+
+// WriteNetworkStatsForDay aggregates the validator_stats of all validators of the day into the network_stats_per_day table. It
+// requires all per-validator sub-exports of the day, so it runs last.
+func WriteNetworkStatsForDay(day uint64) error {
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_network_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if validatorFilterActive() {
+		logger.Infof("validator filter: skipping the network statistics of day %v", day)
+		return nil
+	}
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "network_stats")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	exported, err := GetValidatorStatsStatus(day)
+	if err == nil {
+		err = missingDependencyError(day, map[string]bool{
+			"failed_attestations_exported":      exported.FailedAttestations,
+			"sync_duties_exported":              exported.SyncDuties,
+			"withdrawals_deposits_exported":     exported.WithdrawalsDeposits,
+			"balance_exported":                  exported.Balance,
+			"cl_rewards_exported":               exported.ClRewards,
+			"el_rewards_exported":               exported.ElRewards,
+			"total_performance_exported":        exported.TotalPerformance,
+			"block_stats_exported":              exported.BlockStats,
+			"inclusion_distance_exported":       exported.InclusionDistance,
+			"inclusion_delay_exported":          exported.InclusionDelay,
+			"slashing_events_exported":          exported.SlashingEvents,
+			"slashing_income_exported":          exported.SlashingIncome,
+			"relay_stats_exported":              exported.RelayStats,
+			"withdrawal_address_stats_exported": exported.WithdrawalAddresses,
+		})
+	}
+	if err = ignoreInDryRun(err); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	logger.Infof("exporting network statistics of day %v", day)
+
+	stats := newNetworkStatsAggregate(day)
+	err = StreamValidatorStatsForDay(day, networkStatsChunkSize, func(rows []types.ValidatorStatsRow) error {
+		for i := range rows {
+			stats.add(&rows[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	networkStats := stats.result()
+
+	if skipDryRunWrites("network_stats", day, 1) {
+		return nil
+	}
+
+	_, err = WriterDb.Exec(`
+		INSERT INTO network_stats_per_day (day, active_validators, total_balance, total_effective_balance, avg_balance, cl_rewards_gwei, el_rewards_wei, mev_rewards_wei, deposits, deposits_amount, withdrawals, withdrawals_amount, proposed_blocks, missed_blocks, orphaned_blocks, sync_participation_rate)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (day) DO UPDATE SET
+			active_validators = excluded.active_validators,
+			total_balance = excluded.total_balance,
+			total_effective_balance = excluded.total_effective_balance,
+			avg_balance = excluded.avg_balance,
+			cl_rewards_gwei = excluded.cl_rewards_gwei,
+			el_rewards_wei = excluded.el_rewards_wei,
+			mev_rewards_wei = excluded.mev_rewards_wei,
+			deposits = excluded.deposits,
+			deposits_amount = excluded.deposits_amount,
+			withdrawals = excluded.withdrawals,
+			withdrawals_amount = excluded.withdrawals_amount,
+			proposed_blocks = excluded.proposed_blocks,
+			missed_blocks = excluded.missed_blocks,
+			orphaned_blocks = excluded.orphaned_blocks,
+			sync_participation_rate = excluded.sync_participation_rate`,
+		networkStats.Day, networkStats.ActiveValidators, networkStats.TotalBalance, networkStats.TotalStake, networkStats.AverageBalance, networkStats.ClRewards,
+		networkStats.ElRewards.String(), networkStats.MevRewards.String(), networkStats.Deposits, networkStats.DepositsAmount, networkStats.Withdrawals,
+		networkStats.WithdrawalsAmount, networkStats.ProposedBlocks, networkStats.MissedBlocks, networkStats.OrphanedBlocks, networkStats.SyncParticipationRate)
+	if err != nil {
+		return fmt.Errorf("error saving network stats of day %v: %w", day, err)
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err = markColumnExported(day, "network_stats_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("network statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// networkStatsAggregate sums up the validator_stats rows of a day. Validators with an effective balance at the end of the day are counted as active.
+type networkStatsAggregate struct {
+	stats            types.NetworkDayStats
+	activeBalance    int64
+	participatedSync int64
+	missedSync       int64
+}
+
+func newNetworkStatsAggregate(day uint64) *networkStatsAggregate {
+	return &networkStatsAggregate{stats: types.NetworkDayStats{Day: day, ElRewards: decimal.Zero, MevRewards: decimal.Zero}}
+}
+
+func (a *networkStatsAggregate) add(row *types.ValidatorStatsRow) {
+	if row.EndEffectiveBalance > 0 {
+		a.stats.ActiveValidators++
+		a.activeBalance += row.EndBalance
+	}
+	a.stats.TotalBalance += row.EndBalance
+	a.stats.TotalStake += row.EndEffectiveBalance
+	a.stats.ClRewards += row.ClRewards
+	a.stats.ElRewards = a.stats.ElRewards.Add(row.ElRewards)
+	a.stats.MevRewards = a.stats.MevRewards.Add(row.MevRewards)
+	a.stats.Deposits += row.Deposits
+	a.stats.DepositsAmount += row.DepositsAmount
+	a.stats.Withdrawals += row.Withdrawals
+	a.stats.WithdrawalsAmount += row.WithdrawalsAmount
+	a.stats.ProposedBlocks += row.ProposedBlocks
+	a.stats.MissedBlocks += row.MissedBlocks
+	a.stats.OrphanedBlocks += row.OrphanedBlocks
+	a.participatedSync += row.ParticipatedSync
+	a.missedSync += row.MissedSync
+}
+
+// result returns the aggregates, the average balance is taken over the active validators
+func (a *networkStatsAggregate) result() *types.NetworkDayStats {
+	stats := a.stats
+	if stats.ActiveValidators > 0 {
+		stats.AverageBalance = a.activeBalance / int64(stats.ActiveValidators)
+	}
+	if total := a.participatedSync + a.missedSync; total > 0 {
+		rate := float64(a.participatedSync) / float64(total)
+		stats.SyncParticipationRate = &rate
+	}
+	return &stats
+}
+
+// GetNetworkStatsForDay returns the aggregated validator_stats of all validators of an exported day as written by WriteNetworkStatsForDay.
+// The row of a day already exists once the balance percentiles have been written, so rows without aggregates count as not exported.
+func GetNetworkStatsForDay(day uint64) (*types.NetworkDayStats, error) {
+	cacheKey := fmt.Sprintf("%d:networkStatsForDay:%d", utils.Config.Chain.Config.DepositChainID, day)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Hour, new(types.NetworkDayStats)); err == nil {
+		return cached.(*types.NetworkDayStats), nil
+	}
+
+	stats := &types.NetworkDayStats{}
+	err := ReaderDb.Get(stats, `
+		SELECT
+			day,
+			active_validators,
+			total_balance,
+			total_effective_balance,
+			avg_balance,
+			cl_rewards_gwei,
+			el_rewards_wei,
+			mev_rewards_wei,
+			deposits,
+			deposits_amount,
+			withdrawals,
+			withdrawals_amount,
+			proposed_blocks,
+			missed_blocks,
+			orphaned_blocks,
+			sync_participation_rate
+		FROM network_stats_per_day
+		WHERE day = $1 AND active_validators IS NOT NULL`, day)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %v", ErrStatisticsDayNotExported, day)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving network stats of day %v: %w", day, err)
+	}
+
+	// the statistics of an exported day do not change anymore
+	err = cache.TieredCache.Set(cacheKey, stats, time.Hour*24)
+	if err != nil {
+		utils.LogError(err, fmt.Errorf("error setting tieredCache for GetNetworkStatsForDay with key %v", cacheKey), 0)
+	}
+
+	return stats, nil
+}
+
+// depositWithdrawalSlotRange returns the half-open slot range [fromSlot, toSlot) of the deposits and withdrawals attributed to the day.
+// The end_balance of a day is the balance recorded for its last epoch, which does not contain the deposits and withdrawals included
+// during that epoch yet, they only affect the balance one epoch later. So the range lags one epoch behind the epochs of the day: it
+// contains the last epoch of the previous day and excludes the last epoch of the day itself. Day 0 starts at genesis.
+func depositWithdrawalSlotRange(day uint64) (uint64, uint64) {
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+	if firstEpoch > 0 {
+		firstEpoch--
+	}
+	return firstEpoch * utils.Config.Chain.Config.SlotsPerEpoch, lastEpoch * utils.Config.Chain.Config.SlotsPerEpoch
+}
+
+func WriteValidatorDepositWithdrawals(day uint64) error {
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_deposit_withdrawal_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "withdrawals_deposits")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fromSlot, toSlot := depositWithdrawalSlotRange(day)
+
+	if skipDryRunWrites("withdrawals_deposits", day, -1) {
+		return nil
+	}
+
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		logrus.Errorf("error WriterDb.Beginx %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	start := time.Now()
+	logrus.Infof("Update Withdrawals + Deposits for day [%v] slot %v -> %v", day, fromSlot, toSlot)
+
+	logger.Infof("exporting deposits and deposits_amount statistics")
+	depositsFilterCondition, filterArgs := validatorFilterCondition("validators.validatorindex", 4)
+	depositsQry := `
+		insert into validator_stats (validatorindex, day, deposits, deposits_amount) 
+		(
+			select validators.validatorindex, $3, count(*), sum(amount)
+			from blocks_deposits
+			inner join validators on blocks_deposits.publickey = validators.pubkey
+			inner join blocks on blocks_deposits.block_root = blocks.blockroot
+			where blocks.slot >= $1 and blocks.slot < $2 and blocks.status = '1' and blocks_deposits.valid_signature` + depositsFilterCondition + `
+			group by validators.validatorindex
+		) 
+		on conflict (validatorindex, day) do
+			update set deposits = excluded.deposits, 
+			deposits_amount = excluded.deposits_amount;`
+	if day == 0 {
+		// genesis-deposits will be added to block 0 by the exporter which is technically not 100% correct
+		// since deposits will be added to the validator-balance only after the block which includes the deposits.
+		// to ease the calculation of validator-income (considering deposits) we set the day of genesis-deposits to -1.
+		depositsQry = `
+			insert into validator_stats (validatorindex, day, deposits, deposits_amount)
+			(
+				select validators.validatorindex, case when block_slot = 0 then -1 else $3 end as day, count(*), sum(amount)
+				from blocks_deposits
+				inner join validators on blocks_deposits.publickey = validators.pubkey
+				inner join blocks on blocks_deposits.block_root = blocks.blockroot
+				where blocks.slot >= $1 and blocks.slot < $2 and blocks.status = '1' and (block_slot = 0 or blocks_deposits.valid_signature)` + depositsFilterCondition + `
+				group by validators.validatorindex, day
+			) 
+			on conflict (validatorindex, day) do
+				update set deposits = excluded.deposits, 
+				deposits_amount = excluded.deposits_amount;`
+	}
+
+	if day == 0 {
+		// deposits of a previous export of day 0 (e.g. by a version that was not aware of genesis deposits) are reset, as validators
+		// without deposits in this export would otherwise keep them and have their genesis deposits counted twice
+		resetFilterCondition, resetFilterArgs := validatorFilterCondition("validatorindex", 1)
+		_, err = tx.Exec("UPDATE validator_stats SET deposits = NULL, deposits_amount = NULL WHERE day IN (-1, 0)"+resetFilterCondition, resetFilterArgs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(depositsQry, append([]interface{}{fromSlot, toSlot, day}, filterArgs...)...)
+	if err != nil {
+		return err
+	}
+
+	if day == 0 {
+		if err = verifyGenesisDeposits(tx, fromSlot, toSlot); err != nil {
+			return err
+		}
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	start = time.Now()
+	logger.Infof("exporting withdrawals and withdrawals_amount statistics")
+	withdrawalsFilterCondition, filterArgs := validatorFilterCondition("blocks_withdrawals.validatorindex", 4)
+	withdrawalsQuery := `
+		insert into validator_stats (validatorindex, day, withdrawals, withdrawals_amount) 
+		(
+			select validatorindex, $3, count(*), sum(amount)
+			from blocks_withdrawals
+			inner join blocks on blocks_withdrawals.block_root = blocks.blockroot
+			where blocks.slot >= $1 and blocks.slot < $2 and blocks.status = '1'` + withdrawalsFilterCondition + `
+			group by validatorindex
+		) 
+		on conflict (validatorindex, day) do
+			update set withdrawals = excluded.withdrawals, 
+			withdrawals_amount = excluded.withdrawals_amount;`
+	_, err = tx.Exec(withdrawalsQuery, append([]interface{}{fromSlot, toSlot, day}, filterArgs...)...)
+	if err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err = markColumnExported(day, "withdrawals_deposits_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("deposits and withdrawals statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// verifyGenesisDeposits returns an error if the deposits stored for day -1 and day 0 don't add up to the deposits of the slots of day 0,
+// which is the case if genesis deposits are counted on both days
+func verifyGenesisDeposits(tx *sqlx.Tx, fromSlot, toSlot uint64) error {
+	storedFilterCondition, filterArgs := validatorFilterCondition("validatorindex", 3)
+	countedFilterCondition, _ := validatorFilterCondition("validators.validatorindex", 3)
+	deposits := struct {
+		Stored  int64 `db:"stored"`
+		Counted int64 `db:"counted"`
+	}{}
+	err := tx.Get(&deposits, `
+		SELECT
+			(SELECT COALESCE(SUM(deposits), 0) FROM validator_stats WHERE day IN (-1, 0)`+storedFilterCondition+`) AS stored,
+			(
+				SELECT COUNT(*)
+				FROM blocks_deposits
+				inner join validators on blocks_deposits.publickey = validators.pubkey
+				inner join blocks on blocks_deposits.block_root = blocks.blockroot
+				where blocks.slot >= $1 and blocks.slot < $2 and blocks.status = '1' and (block_slot = 0 or blocks_deposits.valid_signature)`+countedFilterCondition+`
+			) AS counted`, append([]interface{}{fromSlot, toSlot}, filterArgs...)...)
+	if err != nil {
+		return fmt.Errorf("error verifying genesis deposits: %w", err)
+	}
+	if deposits.Stored != deposits.Counted {
+		return fmt.Errorf("%v deposits stored for day -1 and day 0 but %v deposits found, genesis deposits are counted twice", deposits.Stored, deposits.Counted)
+	}
+	return nil
+}
+
+// WriteValidatorWithdrawalAddressStatsForDay stores the number and the sum of the withdrawals of each validator per withdrawal
+// address of the day in the validator_withdrawal_address_stats table. It uses the same slot range as WriteValidatorDepositWithdrawals.
+func WriteValidatorWithdrawalAddressStatsForDay(day uint64) error {
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_withdrawal_address_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "withdrawal_address_stats")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fromSlot, toSlot := depositWithdrawalSlotRange(day)
+
+	if skipDryRunWrites("withdrawal_address_stats", day, -1) {
+		return nil
+	}
+
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	start := time.Now()
+	logger.Infof("exporting withdrawal address statistics for day [%v] slot %v -> %v", day, fromSlot, toSlot)
+
+	_, err = tx.Exec("DELETE FROM validator_withdrawal_address_stats WHERE day = $1", day)
+	if err != nil {
+		return err
+	}
+
+	filterCondition, filterArgs := validatorFilterCondition("blocks_withdrawals.validatorindex", 4)
+	res, err := tx.Exec(`
+		insert into validator_withdrawal_address_stats (validatorindex, address, day, withdrawals, withdrawals_amount)
+		(
+			select validatorindex, address, $3, count(*), sum(amount)
+			from blocks_withdrawals
+			inner join blocks on blocks_withdrawals.block_root = blocks.blockroot
+			where blocks.slot >= $1 and blocks.slot < $2 and blocks.status = '1'`+filterCondition+`
+			group by validatorindex, address
+		)`, append([]interface{}{fromSlot, toSlot, day}, filterArgs...)...)
+	if err != nil {
+		return err
+	}
+	if rows, err := res.RowsAffected(); err == nil {
+		observeRowsExported("withdrawal_address_stats", int(rows))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err = markColumnExported(day, "withdrawal_address_stats_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("withdrawal address statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// GetWithdrawalsByAddress returns the withdrawals to the given address per validator and day between fromDay and toDay (inclusive),
+// ordered by day and validator index
+func GetWithdrawalsByAddress(address []byte, fromDay, toDay uint64) ([]types.ValidatorWithdrawalAddressStats, error) {
+	stats := []types.ValidatorWithdrawalAddressStats{}
+	err := ReaderDb.Select(&stats, `
+		SELECT validatorindex, address, day, withdrawals, withdrawals_amount
+		FROM validator_withdrawal_address_stats
+		WHERE address = $1 AND day >= $2 AND day <= $3
+		ORDER BY day, validatorindex`, address, fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving withdrawals of address 0x%x for days %v - %v: %w", address, fromDay, toDay, err)
+	}
+	return stats, nil
+}
+
+func WriteValidatorSyncDutiesForDay(day uint64) error {
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_sync_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "sync_duties")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	startEpoch, endEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	start := time.Now()
+	logrus.Infof("Update Sync duties for day [%v] epoch %v -> %v", day, startEpoch, endEpoch)
+
+	var syncStats map[uint64]*types.ValidatorSyncDutiesStatistic
+	err = retryBigtable("GetValidatorSyncDutiesStatistics", func() error {
+		var err error
+		syncStats, err = BigtableClient.GetValidatorSyncDutiesStatistics(exportedValidators(), startEpoch, endEpoch)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	logrus.Infof("getting sync duties done in %v, now we export them to the db", time.Since(start))
+	start = time.Now()
+
+	syncStatsArr := make([]*types.ValidatorSyncDutiesStatistic, 0, len(syncStats))
+	for _, stat := range syncStats {
+		syncStatsArr = append(syncStatsArr, stat)
+	}
+
+	if skipDryRunWrites("sync_duties", day, len(syncStatsArr)) {
+		return nil
+	}
+	if noDataForDay("sync_duties", day, len(syncStatsArr)) {
+		return markColumnExported(day, "sync_duties_exported", time.Since(exportStart))
+	}
+
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		logrus.Errorf("error WriterDb.Beginx %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	numArgs := 6
+	batchSize := insertBatchSize("sync_duties", utils.Config.Statistics.SyncDutiesBatchSize, 10000, numArgs)
+	for b := 0; b < len(syncStatsArr); b += batchSize {
+		start := b
+		end := b + batchSize
+		if len(syncStatsArr) < end {
+			end = len(syncStatsArr)
+		}
+
+		valueStrings := make([]string, 0, batchSize)
+		valueArgs := make([]interface{}, 0, batchSize*numArgs)
+		for i, stat := range syncStatsArr[start:end] {
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3, i*numArgs+4, i*numArgs+5, i*numArgs+6))
+			valueArgs = append(valueArgs, stat.Index)
+			valueArgs = append(valueArgs, day)
+			valueArgs = append(valueArgs, stat.ParticipatedSync)
+			valueArgs = append(valueArgs, stat.MissedSync)
+			valueArgs = append(valueArgs, stat.OrphanedSync)
+			valueArgs = append(valueArgs, stat.ParticipationRate())
+		}
+		stmt := fmt.Sprintf(`
+			insert into validator_stats (validatorindex, day, participated_sync, missed_sync, orphaned_sync, sync_participation_rate)  VALUES
+			%s
+			on conflict (validatorindex, day) do update set participated_sync = excluded.participated_sync, missed_sync = excluded.missed_sync, orphaned_sync = excluded.orphaned_sync, sync_participation_rate = excluded.sync_participation_rate;`,
+			strings.Join(valueStrings, ","))
+		_, err := tx.Exec(stmt, valueArgs...)
+		if err != nil {
+			return err
+		}
+
+		logrus.Infof("saving sync statistics batch %v completed", b)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	observeRowsExported("sync_duties", len(syncStatsArr))
+
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err = markColumnExported(day, "sync_duties_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("sync duties and statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+func WriteValidatorFailedAttestationsStatisticsForDay(day uint64) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline("failed_attestations")))
+	defer cancel()
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_failed_att_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "failed_attestations")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	start := time.Now()
+
+	logrus.Infof("exporting 'failed attestations' statistics firstEpoch: %v lastEpoch: %v", firstEpoch, lastEpoch)
+
+	// first key is the batch start index and the second is the validator id
+	failed := map[uint64]map[uint64]*types.ValidatorFailedAttestationsStatistic{}
+	mux := sync.Mutex{}
+	fetchProgress := newExportProgress("failed_attestations_fetch")
+	g, gCtx := errgroup.WithContext(ctx)
+	epochBatchSize := newFailedAttestationsEpochBatchSize()
+	if epochBatchSize.adaptive {
+		// limit the concurrency so batches scheduled later can pick up a reduced batch size
+		g.SetLimit(adaptiveEpochBatchConcurrency)
+	}
+	for i := firstEpoch; i < lastEpoch; {
+		fromEpoch := i
+		toEpoch := fromEpoch + epochBatchSize.get()
+		i = toEpoch
+		if toEpoch >= lastEpoch {
+			toEpoch = lastEpoch
+		} else {
+			toEpoch--
+		}
+		fetchProgress.batchScheduled()
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
+			var ma map[uint64]*types.ValidatorFailedAttestationsStatistic
+			err := retryBigtable("GetValidatorFailedAttestationsCount", func() error {
+				var err error
+				callStart := time.Now()
+				ma, err = BigtableClient.GetValidatorFailedAttestationsCount(exportedValidators(), fromEpoch, toEpoch)
+				epochBatchSize.observe(time.Since(callStart), err)
+				return err
+			})
+			if err != nil {
+				logrus.Errorf("error getting 'failed attestations' %v", err)
+				return err
+			}
+			mux.Lock()
+			failed[fromEpoch] = ma
+			mux.Unlock()
+			fetchProgress.batchCompleted()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	validatorMap := map[uint64]*types.ValidatorFailedAttestationsStatistic{}
+	for _, f := range failed {
+
+		for key, val := range f {
+			if validatorMap[key] == nil {
+				validatorMap[key] = val
+			} else {
+				validatorMap[key].MissedAttestations += val.MissedAttestations
+				validatorMap[key].OrphanedAttestations += val.OrphanedAttestations
+			}
+		}
+	}
+
+	logrus.Infof("fetching 'failed attestations' done in %v, now we export them to the db", time.Since(start))
+	start = time.Now()
+	maArr := make([]*types.ValidatorFailedAttestationsStatistic, 0, len(validatorMap))
+
+	for _, stat := range validatorMap {
+		maArr = append(maArr, stat)
+	}
+
+	if skipDryRunWrites("failed_attestations", day, len(maArr)) {
+		return nil
+	}
+	if noDataForDay("failed_attestations", day, len(maArr)) {
+		return markColumnExported(day, "failed_attestations_exported", time.Since(exportStart))
+	}
+
+	batchSize := 100 // max: 65535 / 4, but we are faster with smaller batches
+	if len(maArr) > batchSize*failedAttestationsCopyMinBatches {
+		// copying all rows at once saves thousands of round trips for large validator sets
+		if err := copyFailedAttestations(maArr, day); err != nil {
+			return err
+		}
+	} else {
+		progress := newExportProgress("failed_attestations")
+		g, gCtx = errgroup.WithContext(ctx)
+
+		for b := 0; b < len(maArr); b += batchSize {
+
+			start := b
+			end := b + batchSize
+			if len(maArr) < end {
+				end = len(maArr)
+			}
+
+			progress.batchScheduled()
+			g.Go(func() error {
+				select {
+				case <-gCtx.Done():
+					return gCtx.Err()
+				default:
+				}
+				if err := saveFailedAttestationBatch(maArr[start:end], day); err != nil {
+					return err
+				}
+				progress.batchCompleted()
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			logrus.Error(err)
+			return err
+		}
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err := markColumnExported(day, "failed_attestations_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("'failed attestation' statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+// attestationComponentsEpochBatchSize is the number of epochs whose income details are read per Bigtable call of the attestation components export
+const attestationComponentsEpochBatchSize = 2
+
+// WriteValidatorAttestationComponentsForDay exports the missed source, target and head votes of the validators for a day. The votes are
+// derived from the income details of every epoch of the day, which are not read by the failed attestations export, so it is only run
+// if enabled by statistics.attestationComponents. Validators that did not miss any vote have no values.
+func WriteValidatorAttestationComponentsForDay(day uint64) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline("attestation_components")))
+	defer cancel()
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_attestation_components_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "attestation_components")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	start := time.Now()
+	logger.Infof("exporting 'attestation components' statistics firstEpoch: %v lastEpoch: %v", firstEpoch, lastEpoch)
+
+	components := map[uint64]*types.ValidatorAttestationComponentsStatistic{}
+	mux := sync.Mutex{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(adaptiveEpochBatchConcurrency)
+	for fromEpoch := firstEpoch; fromEpoch <= lastEpoch; fromEpoch += attestationComponentsEpochBatchSize {
+		fromEpoch := fromEpoch
+		toEpoch := fromEpoch + attestationComponentsEpochBatchSize - 1
+		if toEpoch > lastEpoch {
+			toEpoch = lastEpoch
+		}
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
+			var batch map[uint64]*types.ValidatorAttestationComponentsStatistic
+			err := retryBigtable("GetValidatorMissedAttestationComponents", func() error {
+				var err error
+				batch, err = BigtableClient.GetValidatorMissedAttestationComponents(exportedValidators(), fromEpoch, toEpoch)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			mux.Lock()
+			defer mux.Unlock()
+			for validator, stat := range batch {
+				if components[validator] == nil {
+					components[validator] = stat
+					continue
+				}
+				components[validator].MissedSource += stat.MissedSource
+				components[validator].MissedTarget += stat.MissedTarget
+				components[validator].MissedHead += stat.MissedHead
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	logger.Infof("fetching 'attestation components' done in %v, now we export them to the db", time.Since(start))
+	start = time.Now()
+
+	stats := make([]*types.ValidatorAttestationComponentsStatistic, 0, len(components))
+	for _, stat := range components {
+		stats = append(stats, stat)
+	}
+
+	if skipDryRunWrites("attestation_components", day, len(stats)) {
+		return nil
+	}
+	if !noDataForDay("attestation_components", day, len(stats)) {
+		batchSize := insertBatchSize("attestation_components", 0, 100, attestationComponentsNumArgs)
+		for b := 0; b < len(stats); b += batchSize {
+			end := b + batchSize
+			if len(stats) < end {
+				end = len(stats)
+			}
+			if err := saveAttestationComponentsBatch(stats[b:end], day); err != nil {
+				return err
+			}
+		}
+		logger.Infof("export completed, took %v", time.Since(start))
+	}
+
+	if err := markColumnExported(day, "attestation_components_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("'attestation components' statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+const attestationComponentsNumArgs = 5
+
+func saveAttestationComponentsBatch(batch []*types.ValidatorAttestationComponentsStatistic, day uint64) error {
+	valueStrings := make([]string, 0, len(batch))
+	valueArgs := make([]interface{}, 0, len(batch)*attestationComponentsNumArgs)
+	for i, stat := range batch {
+		n := i * attestationComponentsNumArgs
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		valueArgs = append(valueArgs, stat.Index, day, stat.MissedSource, stat.MissedTarget, stat.MissedHead)
+	}
+	_, err := WriterDb.Exec(fmt.Sprintf(`
+		insert into validator_stats (validatorindex, day, missed_source, missed_target, missed_head) VALUES
+		%s
+		on conflict (validatorindex, day) do update set missed_source = excluded.missed_source, missed_target = excluded.missed_target, missed_head = excluded.missed_head;`,
+		strings.Join(valueStrings, ",")), valueArgs...)
+	if err != nil {
+		return fmt.Errorf("error inserting 'attestation components': %w", err)
+	}
+	observeRowsExported("attestation_components", len(batch))
+	return nil
+}
+
+const adaptiveEpochBatchConcurrency = 10
+
+// epochBatchSize holds the number of epochs fetched per Bigtable call of an export. In adaptive mode the size is halved
+// (down to a single epoch) whenever a call errors or takes longer than the latency threshold.
+type epochBatchSize struct {
+	mux              sync.Mutex
+	size             uint64
+	adaptive         bool
+	latencyThreshold time.Duration
+}
+
+func newFailedAttestationsEpochBatchSize() *epochBatchSize {
+	b := &epochBatchSize{
+		size:             utils.Config.Statistics.FailedAttestationsEpochBatchSize,
+		adaptive:         utils.Config.Statistics.FailedAttestationsAdaptiveBatchSize,
+		latencyThreshold: utils.Config.Statistics.FailedAttestationsBatchLatencyThreshold,
+	}
+	if b.size == 0 {
+		b.size = 2 // Fetching 2 Epochs per batch seems to be the fastest way to go
+	}
+	if b.latencyThreshold == 0 {
+		b.latencyThreshold = time.Second * 30
+	}
+	return b
+}
+
+func (b *epochBatchSize) get() uint64 {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.size
+}
+
+func (b *epochBatchSize) observe(took time.Duration, err error) {
+	if !b.adaptive || (err == nil && took <= b.latencyThreshold) {
+		return
+	}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.size > 1 {
+		b.size /= 2
+		logger.Warnf("reducing epoch batch size to %v (call took %v, err: %v)", b.size, took, err)
+	}
+}
+
+// failedAttestationsCopyMinBatches is the number of insert batches above which the failed attestations are written with COPY instead
+const failedAttestationsCopyMinBatches = 50
+
+const failedAttestationsColumns = "validatorindex, day, missed_attestations, orphaned_attestations"
+
+const failedAttestationsOnConflict = `
+	on conflict (validatorindex, day) do update set missed_attestations = excluded.missed_attestations, orphaned_attestations = excluded.orphaned_attestations;`
+
+// failedAttestationValues returns the values of a validator_stats row in the order of failedAttestationsColumns
+func failedAttestationValues(stat *types.ValidatorFailedAttestationsStatistic, day uint64) []interface{} {
+	return []interface{}{stat.Index, day, stat.MissedAttestations, stat.OrphanedAttestations}
+}
+
+func saveFailedAttestationBatch(batch []*types.ValidatorFailedAttestationsStatistic, day uint64) error {
+	var failedAttestationBatchNumArgs int = 4
+	batchSize := len(batch)
+	valueStrings := make([]string, 0, failedAttestationBatchNumArgs)
+	valueArgs := make([]interface{}, 0, batchSize*failedAttestationBatchNumArgs)
+
+	for i, stat := range batch {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)", i*failedAttestationBatchNumArgs+1, i*failedAttestationBatchNumArgs+2, i*failedAttestationBatchNumArgs+3, i*failedAttestationBatchNumArgs+4))
+		valueArgs = append(valueArgs, failedAttestationValues(stat, day)...)
+	}
+	stmt := fmt.Sprintf(`
+		insert into validator_stats (%s) VALUES
+		%s
+		%s`,
+		failedAttestationsColumns, strings.Join(valueStrings, ","), failedAttestationsOnConflict)
+	_, err := WriterDb.Exec(stmt, valueArgs...)
+	if err != nil {
+		logrus.Errorf("Error inserting 'failed attestations' %v", err)
+		return err
+	}
+	observeRowsExported("failed_attestations", len(batch))
+
+	return nil
+}
+
+// copyFailedAttestations writes the failed attestations of all validators of the day within a single transaction. The rows are
+// streamed into a temporary table with COPY and upserted into validator_stats from there, as COPY itself can't handle conflicts.
+func copyFailedAttestations(stats []*types.ValidatorFailedAttestationsStatistic, day uint64) error {
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		create temp table failed_attestations_import (
+			validatorindex int, day int, missed_attestations int, orphaned_attestations int
+		) on commit drop`)
+	if err != nil {
+		return fmt.Errorf("error creating temp table for 'failed attestations': %w", err)
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("failed_attestations_import", strings.Split(failedAttestationsColumns, ", ")...))
+	if err != nil {
+		return fmt.Errorf("error preparing copy of 'failed attestations': %w", err)
+	}
+	for _, stat := range stats {
+		if _, err := stmt.Exec(failedAttestationValues(stat, day)...); err != nil {
+			stmt.Close()
+			return fmt.Errorf("error copying 'failed attestations': %w", err)
+		}
+	}
+	// the final call without values flushes the buffered rows
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return fmt.Errorf("error copying 'failed attestations': %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("error copying 'failed attestations': %w", err)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`
+		insert into validator_stats (%[1]s)
+		select %[1]s from failed_attestations_import
+		%[2]s`, failedAttestationsColumns, failedAttestationsOnConflict))
+	if err != nil {
+		return fmt.Errorf("error inserting 'failed attestations': %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	observeRowsExported("failed_attestations", len(stats))
+	return nil
+}
+
+// WriteValidatorAttestationInclusionStats writes the average and max inclusion delay (distance in slots between attester and inclusion slot) of the included attestations of each validator for the day.
+// Validators without an included attestation on that day keep NULL in both columns.
+func WriteValidatorAttestationInclusionStats(day uint64) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline("inclusion_distance")))
+	defer cancel()
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_inclusion_distance_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "inclusion_distance")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// the attestation effectiveness also counts the missed and orphaned attestations of the day
+	failedAttestationsExported := false
+	err = ReaderDb.Get(&failedAttestationsExported, `SELECT failed_attestations_exported FROM validator_stats_status WHERE day = $1`, day)
+	if err != nil {
+		err = fmt.Errorf("error retrieving required data: %v", err)
+	} else {
+		err = missingDependencyError(day, map[string]bool{"failed_attestations_exported": failedAttestationsExported})
+	}
+	if err = ignoreInDryRun(err); err != nil {
+		return err
+	}
+
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	start := time.Now()
+
+	logrus.Infof("exporting 'attestation inclusion distance' statistics firstEpoch: %v lastEpoch: %v", firstEpoch, lastEpoch)
+
+	validatorMap := map[uint64]*types.ValidatorAttestationInclusionStatistic{}
+	mux := sync.Mutex{}
+	g, gCtx := errgroup.WithContext(ctx)
+	epochBatchSize := uint64(2)
+	for i := firstEpoch; i <= lastEpoch; i += epochBatchSize {
+		fromEpoch := i
+		toEpoch := fromEpoch + epochBatchSize - 1
+		if toEpoch > lastEpoch {
+			toEpoch = lastEpoch
+		}
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
+			var inclusion map[uint64]*types.ValidatorAttestationInclusionStatistic
+			err := retryBigtable("GetValidatorAttestationInclusionStatistics", func() error {
+				var err error
+				inclusion, err = BigtableClient.GetValidatorAttestationInclusionStatistics(exportedValidators(), fromEpoch, toEpoch)
+				return err
+			})
+			if err != nil {
+				logrus.Errorf("error getting 'attestation inclusion distance' %v", err)
+				return err
+			}
+			mux.Lock()
+			defer mux.Unlock()
+			for validator, stat := range inclusion {
+				if validatorMap[validator] == nil {
+					validatorMap[validator] = stat
+					continue
+				}
+				validatorMap[validator].IncludedAttestations += stat.IncludedAttestations
+				validatorMap[validator].InclusionDistanceSum += stat.InclusionDistanceSum
+				if stat.MaxInclusionDistance > validatorMap[validator].MaxInclusionDistance {
+					validatorMap[validator].MaxInclusionDistance = stat.MaxInclusionDistance
+				}
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	failed := []struct {
+		ValidatorIndex     uint64 `db:"validatorindex"`
+		FailedAttestations uint64 `db:"failed_attestations"`
+	}{}
+	err = ReaderDb.Select(&failed, `
+		SELECT validatorindex, COALESCE(missed_attestations, 0) + COALESCE(orphaned_attestations, 0) AS failed_attestations
+		FROM validator_stats
+		WHERE day = $1 AND (missed_attestations > 0 OR orphaned_attestations > 0)`, day)
+	if err != nil {
+		logrus.Errorf("error getting failed attestations for day %v: %v", day, err)
+		return err
+	}
+	for _, f := range failed {
+		if validatorMap[f.ValidatorIndex] == nil {
+			validatorMap[f.ValidatorIndex] = &types.ValidatorAttestationInclusionStatistic{Index: f.ValidatorIndex}
+		}
+		validatorMap[f.ValidatorIndex].FailedAttestations = f.FailedAttestations
+	}
+
+	logrus.Infof("fetching 'attestation inclusion distance' done in %v, now we export them to the db", time.Since(start))
+	start = time.Now()
+
+	if skipDryRunWrites("inclusion_distance", day, len(validatorMap)) {
+		return nil
+	}
+	// without data the inclusion distances of a previous export of the day are still reset below
+	noDataForDay("inclusion_distance", day, len(validatorMap))
+
+	// validators without an included attestation must keep NULL (not 0) so they do not skew averages, also when the day is re-exported
+	_, err = WriterDb.Exec(`update validator_stats set avg_inclusion_distance = NULL, max_inclusion_distance = NULL, attestation_effectiveness = NULL where day = $1 and (avg_inclusion_distance is not null or attestation_effectiveness is not null)`, day)
+	if err != nil {
+		logrus.Errorf("error resetting 'attestation inclusion distance' for day %v: %v", day, err)
+		return err
+	}
+
+	statsArr := make([]*types.ValidatorAttestationInclusionStatistic, 0, len(validatorMap))
+	for _, stat := range validatorMap {
+		statsArr = append(statsArr, stat)
+	}
+
+	g, gCtx = errgroup.WithContext(ctx)
+
+	batchSize := 100 // max: 65535 / 5, but we are faster with smaller batches
+	for b := 0; b < len(statsArr); b += batchSize {
+		start := b
+		end := b + batchSize
+		if len(statsArr) < end {
+			end = len(statsArr)
+		}
+
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
+			return saveAttestationInclusionBatch(statsArr[start:end], day)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		logrus.Error(err)
+		return err
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err := markColumnExported(day, "inclusion_distance_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("'attestation inclusion distance' statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+func saveAttestationInclusionBatch(batch []*types.ValidatorAttestationInclusionStatistic, day uint64) error {
+	numArgs := 5
+	valueStrings := make([]string, 0, len(batch))
+	valueArgs := make([]interface{}, 0, len(batch)*numArgs)
+
+	for i, stat := range batch {
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3, i*numArgs+4, i*numArgs+5))
+		valueArgs = append(valueArgs, stat.Index)
+		valueArgs = append(valueArgs, day)
+		// validators which only failed their attestations have no inclusion distance
+		if stat.IncludedAttestations > 0 {
+			valueArgs = append(valueArgs, stat.AvgInclusionDistance())
+			valueArgs = append(valueArgs, stat.MaxInclusionDistance)
+		} else {
+			valueArgs = append(valueArgs, nil)
+			valueArgs = append(valueArgs, nil)
+		}
+		valueArgs = append(valueArgs, stat.AttestationEffectiveness())
+	}
+	stmt := fmt.Sprintf(`
+		insert into validator_stats (validatorindex, day, avg_inclusion_distance, max_inclusion_distance, attestation_effectiveness) VALUES
+		%s
+		on conflict (validatorindex, day) do update set avg_inclusion_distance = excluded.avg_inclusion_distance, max_inclusion_distance = excluded.max_inclusion_distance, attestation_effectiveness = excluded.attestation_effectiveness;`,
+		strings.Join(valueStrings, ","))
+	_, err := WriterDb.Exec(stmt, valueArgs...)
+	if err != nil {
+		logrus.Errorf("Error inserting 'attestation inclusion distance' %v", err)
+		return err
+	}
+	observeRowsExported("inclusion_distance", len(batch))
+
+	return nil
+}
+
+const inclusionDelayEpochBatchSize = 2
+
+// WriteValidatorInclusionDelayForDay writes the average and max inclusion delay of the included attestations of each validator for
+// the day. The inclusion delay is the inclusion distance minus the minimum distance of 1 slot. Validators without an included
+// attestation on that day keep NULL in both columns.
+func WriteValidatorInclusionDelayForDay(day uint64) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(exportDeadline("inclusion_delay")))
+	defer cancel()
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_inclusion_delay_stats").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if err := checkIfDayIsFinalized(day); err != nil {
+		return err
+	}
+
+	unlock, err := lockStatisticsExport(day, "inclusion_delay")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+
+	start := time.Now()
+	logger.Infof("exporting 'attestation inclusion delay' statistics firstEpoch: %v lastEpoch: %v", firstEpoch, lastEpoch)
+
+	validatorMap := map[uint64]*types.ValidatorAttestationInclusionStatistic{}
+	mux := sync.Mutex{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(adaptiveEpochBatchConcurrency)
+	for fromEpoch := firstEpoch; fromEpoch <= lastEpoch; fromEpoch += inclusionDelayEpochBatchSize {
+		fromEpoch := fromEpoch
+		toEpoch := fromEpoch + inclusionDelayEpochBatchSize - 1
+		if toEpoch > lastEpoch {
+			toEpoch = lastEpoch
+		}
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
+			var inclusion map[uint64]*types.ValidatorAttestationInclusionStatistic
+			err := retryBigtable("GetValidatorAttestationInclusionStatistics", func() error {
+				var err error
+				inclusion, err = BigtableClient.GetValidatorAttestationInclusionStatistics(exportedValidators(), fromEpoch, toEpoch)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			mux.Lock()
+			defer mux.Unlock()
+			for validator, stat := range inclusion {
+				if validatorMap[validator] == nil {
+					validatorMap[validator] = stat
+					continue
+				}
+				validatorMap[validator].IncludedAttestations += stat.IncludedAttestations
+				validatorMap[validator].InclusionDistanceSum += stat.InclusionDistanceSum
+				if stat.MaxInclusionDistance > validatorMap[validator].MaxInclusionDistance {
+					validatorMap[validator].MaxInclusionDistance = stat.MaxInclusionDistance
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	logger.Infof("fetching 'attestation inclusion delay' done in %v, now we export them to the db", time.Since(start))
+	start = time.Now()
+
+	stats := make([]*types.ValidatorAttestationInclusionStatistic, 0, len(validatorMap))
+	for _, stat := range validatorMap {
+		if stat.IncludedAttestations > 0 {
+			stats = append(stats, stat)
+		}
+	}
+
+	if skipDryRunWrites("inclusion_delay", day, len(stats)) {
+		return nil
+	}
+	// without data the inclusion delays of a previous export of the day are still reset below
+	noDataForDay("inclusion_delay", day, len(stats))
+
+	// validators without an included attestation must keep NULL (not 0) so they do not skew averages, also when the day is re-exported
+	_, err = WriterDb.Exec(`update validator_stats set avg_inclusion_delay = NULL, max_inclusion_delay = NULL where day = $1 and avg_inclusion_delay is not null`, day)
+	if err != nil {
+		return fmt.Errorf("error resetting 'attestation inclusion delay' of day %v: %w", day, err)
+	}
+
+	batchSize := insertBatchSize("inclusion_delay", 0, 100, inclusionDelayNumArgs)
+	for b := 0; b < len(stats); b += batchSize {
+		end := b + batchSize
+		if len(stats) < end {
+			end = len(stats)
+		}
+		if err := saveInclusionDelayBatch(stats[b:end], day); err != nil {
+			return err
+		}
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
+
+	if err := markColumnExported(day, "inclusion_delay_exported", time.Since(exportStart)); err != nil {
+		return err
+	}
+
+	logger.Infof("'attestation inclusion delay' statistics export of day %v completed, took %v", day, time.Since(exportStart))
+	return nil
+}
+
+const inclusionDelayNumArgs = 4
+
+func saveInclusionDelayBatch(batch []*types.ValidatorAttestationInclusionStatistic, day uint64) error {
+	valueStrings := make([]string, 0, len(batch))
+	valueArgs := make([]interface{}, 0, len(batch)*inclusionDelayNumArgs)
+	for i, stat := range batch {
+		n := i * inclusionDelayNumArgs
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		valueArgs = append(valueArgs, stat.Index, day, stat.AvgInclusionDelay(), stat.MaxInclusionDelay())
+	}
+	_, err := WriterDb.Exec(fmt.Sprintf(`
+		insert into validator_stats (validatorindex, day, avg_inclusion_delay, max_inclusion_delay) VALUES
+		%s
+		on conflict (validatorindex, day) do update set avg_inclusion_delay = excluded.avg_inclusion_delay, max_inclusion_delay = excluded.max_inclusion_delay;`,
+		strings.Join(valueStrings, ",")), valueArgs...)
+	if err != nil {
+		return fmt.Errorf("error inserting 'attestation inclusion delay': %w", err)
+	}
+	observeRowsExported("inclusion_delay", len(batch))
+	return nil
+}
+
+// observeRowsExported adds the number of validator_stats rows written by a batch of a sub-export to the metrics.RowsExported counter
+func observeRowsExported(export string, rows int) {
+	metrics.RowsExported.WithLabelValues(export).Add(float64(rows))
+}
+
+var ErrStatisticsExportLocked = errors.New("statistics export is already running in another instance")
+
+// ErrStatisticsExportSkipped is returned if a locked export is skipped, so the caller does not take the day as exported and retries
+// it later. It matches ErrStatisticsExportLocked as well.
+var ErrStatisticsExportSkipped = fmt.Errorf("%w, skipped", ErrStatisticsExportLocked)
+
+// exportLocker guards the sub-exports of a day against being run by multiple exporter instances at the same time
+type exportLocker interface {
+	// lock blocks until the lock of the sub-export is acquired
+	lock(day uint64, export string) (unlock func(), err error)
+	// tryLock returns immediately, acquired is false if the lock is held by someone else
+	tryLock(day uint64, export string) (unlock func(), acquired bool, err error)
+}
+
+// advisoryExportLocker uses postgres session level advisory locks keyed on the sub-export and the day. The lock is held by a
+// dedicated connection outside of a transaction, so no idle transaction is kept open while exporting, and is also released if
+// the instance dies.
+type advisoryExportLocker struct{}
+
+func (advisoryExportLocker) lock(day uint64, export string) (func(), error) {
+	conn, err := WriterDb.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.ExecContext(context.Background(), "SELECT pg_advisory_lock(hashtext($1), $2::int)", export, day)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error acquiring lock of %v export of day %v: %w", export, day, err)
+	}
+	return advisoryUnlock(conn, day, export), nil
+}
+
+func (advisoryExportLocker) tryLock(day uint64, export string) (func(), bool, error) {
+	conn, err := WriterDb.Conn(context.Background())
+	if err != nil {
+		return nil, false, err
+	}
+	acquired := false
+	err = conn.QueryRowContext(context.Background(), "SELECT pg_try_advisory_lock(hashtext($1), $2::int)", export, day).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("error acquiring lock of %v export of day %v: %w", export, day, err)
+		}
+		return nil, false, nil
+	}
+	return advisoryUnlock(conn, day, export), true, nil
+}
+
+// advisoryUnlock returns the unlock function of a session level advisory lock held by conn. If the lock can't be released the
+// connection is discarded instead of being returned to the pool, which releases the lock as well.
+func advisoryUnlock(conn *sql.Conn, day uint64, export string) func() {
+	return func() {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1), $2::int)", export, day)
+		if err != nil {
+			logger.Errorf("error releasing lock of %v export of day %v, closing its connection: %v", export, day, err)
+			conn.Raw(func(driverConn interface{}) error {
+				return driver.ErrBadConn
+			})
+		}
+	}
+}
+
+var statisticsExportLocker exportLocker = advisoryExportLocker{}
+
+// lockStatisticsExport locks the sub-export of the day for this instance according to the configured export lock mode:
+// "wait" blocks until the lock is free, "error" returns ErrStatisticsExportLocked if it is held by another instance and
+// by default ErrStatisticsExportSkipped is returned so the instance holding the lock can complete it.
+func lockStatisticsExport(day uint64, export string) (unlock func(), err error) {
+	if utils.Config.Statistics.DryRun {
+		// nothing is written in dry run mode
+		return func() {}, nil
+	}
+	return acquireExportLock(statisticsExportLocker, utils.Config.Statistics.ExportLockMode, day, export)
+}
+
+func acquireExportLock(locker exportLocker, mode string, day uint64, export string) (func(), error) {
+	if mode == "wait" {
+		return locker.lock(day, export)
+	}
+
+	unlock, acquired, err := locker.tryLock(day, export)
+	if err != nil {
+		return nil, err
+	}
+	if acquired {
+		return unlock, nil
+	}
+	if mode == "error" {
+		return nil, fmt.Errorf("%w: %v export of day %v", ErrStatisticsExportLocked, export, day)
+	}
+	logger.Infof("skipping %v export of day %v as it is running in another instance", export, day)
+	return nil, fmt.Errorf("%w: %v export of day %v", ErrStatisticsExportSkipped, export, day)
+}
+
+// markColumnExported sets the exported flag column of the day in the status table. If the duration of the export is passed
+// it is stored in milliseconds in the matching _export_ms column within the same statement.
+func markColumnExported(day uint64, column string, duration ...time.Duration) error {
+	if validatorFilterActive() {
+		logger.Infof("validator filter: not marking [%v] exported for day [%v]", column, day)
+		return nil
+	}
+
+	start := time.Now()
+	logger.Infof("marking [%v] exported for day [%v] as completed in the status table", column, day)
+
+	args := []interface{}{day}
+	if len(duration) > 0 {
+		args = append(args, duration[0].Milliseconds())
+	}
+	_, err := WriterDb.Exec(markColumnExportedQuery(column, len(duration) > 0), args...)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Marking complete in %v", time.Since(start))
+	return nil
+}
+
+// exportDurationColumn returns the status table column holding the export duration of an exported flag column,
+// e.g. balance_export_ms for balance_exported
+func exportDurationColumn(column string) string {
+	return strings.TrimSuffix(column, "_exported") + "_export_ms"
+}
+
+func markColumnExportedQuery(column string, withDuration bool) string {
+	if !withDuration {
+		return fmt.Sprintf(`	
+		INSERT INTO validator_stats_status (day, status, %[1]v) 
+		VALUES ($1, false, true) 
+		ON CONFLICT (day) 
+			DO UPDATE SET %[1]v=EXCLUDED.%[1]v;
+			`, column)
+	}
+	return fmt.Sprintf(`	
+		INSERT INTO validator_stats_status (day, status, %[1]v, %[2]v) 
+		VALUES ($1, false, true, $2) 
+		ON CONFLICT (day) 
+			DO UPDATE SET %[1]v=EXCLUDED.%[1]v, %[2]v=EXCLUDED.%[2]v;
+			`, column, exportDurationColumn(column))
+}
+
+// GetValidatorIncomeHistoryChart returns the daily cl rewards of the validators in the given currency. By default all days are converted
+// using the current exchange rate, if useHistoricalPrices is set each day is converted using the price of that day instead. Days
+// without a historical price, like the current day, fall back to the current exchange rate.
+func GetValidatorIncomeHistoryChart(validatorIndices []uint64, currency string, lastFinalizedEpoch uint64, useHistoricalPrices bool) ([]*types.ChartDataPoint, error) {
+	incomeHistory, err := GetValidatorIncomeHistory(validatorIndices, 0, 0, lastFinalizedEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if !useHistoricalPrices || len(incomeHistory) == 0 {
+		return incomeHistoryChartSeries(incomeHistory, currency), nil
+	}
+
+	prices, err := getHistoricalPricesForDays(currency, incomeHistory[0].Day, incomeHistory[len(incomeHistory)-1].Day)
+	if err != nil {
+		return nil, err
+	}
+	currentDay := utils.TimeToDay(uint64(time.Now().Unix()))
+	currentRate := utils.ExchangeRateForCurrency(currency)
+	return incomeHistoryChartSeriesWithRates(incomeHistory, func(day int64) float64 {
+		if price, ok := prices[day]; ok && day < int64(currentDay) {
+			return price
+		}
+		return currentRate
+	}), nil
+}
+
+// getHistoricalPricesForDays returns the eth price in the given currency of each day between fromDay and toDay that has a row in the price table
+func getHistoricalPricesForDays(currency string, fromDay, toDay int64) (map[int64]float64, error) {
+	prices := map[int64]float64{}
+	if utils.Config.Chain.Config.DepositChainID != 1 || currency == "ETH" {
+		// there are no historical prices for testnets and eth is always converted 1:1
+		return prices, nil
+	}
+	if !utils.SliceContains(price.GetAvailableCurrencies(), strings.ToUpper(currency)) {
+		return nil, fmt.Errorf("currency %v not supported", currency)
+	}
+	column := strings.ToLower(currency)
+
+	genesisTime := time.Unix(int64(utils.Config.Chain.GenesisTimestamp), 0).UTC()
+	dayStartGenesisTime := time.Date(genesisTime.Year(), genesisTime.Month(), genesisTime.Day(), 0, 0, 0, 0, time.UTC)
+
+	rows := []struct {
+		Ts    time.Time `db:"ts"`
+		Price float64   `db:"price"`
+	}{}
+	err := ReaderDb.Select(&rows, fmt.Sprintf("SELECT ts, %s AS price FROM price WHERE ts >= $1 AND ts <= $2", column),
+		dayStartGenesisTime.Add(utils.Day*time.Duration(fromDay)), dayStartGenesisTime.Add(utils.Day*time.Duration(toDay)))
+	if err != nil {
+		return nil, fmt.Errorf("error getting historical %v prices for days %v - %v: %w", currency, fromDay, toDay, err)
+	}
+	for _, row := range rows {
+		prices[int64(row.Ts.Sub(dayStartGenesisTime)/utils.Day)] = row.Price
+	}
+	return prices, nil
+}
+
+// GetValidatorIncomeHistoryChartMulti returns the income history chart of the validators for each of the given currencies.
+// The income history is only read once and shared across all currencies.
+func GetValidatorIncomeHistoryChartMulti(validatorIndices []uint64, currencies []string, lastFinalizedEpoch uint64) (map[string][]*types.ChartDataPoint, error) {
+	for _, currency := range currencies {
+		if !utils.SliceContains(price.GetAvailableCurrencies(), currency) {
+			return nil, fmt.Errorf("unsupported currency %v, supported currencies are %v", currency, strings.Join(price.GetAvailableCurrencies(), ", "))
+		}
+	}
+
+	incomeHistory, err := GetValidatorIncomeHistory(validatorIndices, 0, 0, lastFinalizedEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[string][]*types.ChartDataPoint, len(currencies))
+	for _, currency := range currencies {
+		series[currency] = incomeHistoryChartSeries(incomeHistory, currency)
+	}
+	return series, nil
+}
+
+func incomeHistoryChartSeries(incomeHistory []types.ValidatorIncomeHistory, currency string) []*types.ChartDataPoint {
+	exchangeRate := utils.ExchangeRateForCurrency(currency)
+	return incomeHistoryChartSeriesWithRates(incomeHistory, func(day int64) float64 {
+		return exchangeRate
+	})
+}
+
+// incomeHistoryChartSeriesWithRates converts the cl rewards of each day using the exchange rate returned by rateForDay
+func incomeHistoryChartSeriesWithRates(incomeHistory []types.ValidatorIncomeHistory, rateForDay func(day int64) float64) []*types.ChartDataPoint {
+	var clRewardsSeries = make([]*types.ChartDataPoint, len(incomeHistory))
+
+	for i := 0; i < len(incomeHistory); i++ {
+		exchangeRate := rateForDay(incomeHistory[i].Day)
+		color := "#7cb5ec"
+		if incomeHistory[i].ClRewards < 0 {
+			color = "#f7a35c"
+		}
+		balanceTs := utils.DayToTime(incomeHistory[i].Day)
+		clRewardsSeries[i] = &types.ChartDataPoint{X: float64(balanceTs.Unix() * 1000), Y: exchangeRate * (float64(incomeHistory[i].ClRewards) / float64(utils.ClCurrencyDivisor())), Color: color}
+	}
+	return clRewardsSeries
+}
+
+// GetValidatorCombinedIncomeHistoryChart returns the daily total income of the validators in the given currency, being the sum of
+// the cl rewards and the execution layer rewards of the proposed blocks. Days with a negative total are colored orange.
+func GetValidatorCombinedIncomeHistoryChart(validatorIndices []uint64, currency string, lastFinalizedEpoch uint64) ([]*types.ChartDataPoint, error) {
+	incomeHistory, err := GetValidatorIncomeHistory(validatorIndices, 0, 0, lastFinalizedEpoch)
+	if err != nil {
+		return nil, err
+	}
+	return combinedIncomeHistoryChartSeries(incomeHistory, utils.ExchangeRateForCurrency(currency)), nil
+}
+
+// GetValidatorIncomeHistoryCharts returns the daily income of the validators in the given currency. If stacked is set the consensus
+// and the execution layer income are returned as separate "cl" and "el" series, otherwise a single "total" series with their sum as
+// returned by GetValidatorCombinedIncomeHistoryChart.
+func GetValidatorIncomeHistoryCharts(validatorIndices []uint64, currency string, lastFinalizedEpoch uint64, stacked bool) (map[string][]*types.ChartDataPoint, error) {
+	incomeHistory, err := GetValidatorIncomeHistory(validatorIndices, 0, 0, lastFinalizedEpoch)
+	if err != nil {
+		return nil, err
+	}
+	exchangeRate := utils.ExchangeRateForCurrency(currency)
+	if !stacked {
+		return map[string][]*types.ChartDataPoint{"total": combinedIncomeHistoryChartSeries(incomeHistory, exchangeRate)}, nil
+	}
+	return map[string][]*types.ChartDataPoint{
+		"cl": incomeHistoryChartSeries(incomeHistory, currency),
+		"el": elIncomeHistoryChartSeries(incomeHistory, exchangeRate),
+	}, nil
+}
+
+// elIncomeHistoryChartSeries converts the execution layer income of each day from wei, it is taken from the mev rewards for the same
+// reason and in the same unit as in combinedIncomeGwei, so the stacked series add up to the combined one
+func elIncomeHistoryChartSeries(incomeHistory []types.ValidatorIncomeHistory, exchangeRate float64) []*types.ChartDataPoint {
+	series := make([]*types.ChartDataPoint, len(incomeHistory))
+	for i, h := range incomeHistory {
+		y, _ := h.MevRewards.Div(decimal.NewFromInt(1e9)).Div(decimal.NewFromInt(int64(utils.ClCurrencyDivisor()))).Mul(decimal.NewFromFloat(exchangeRate)).Float64()
+		series[i] = &types.ChartDataPoint{X: float64(utils.DayToTime(h.Day).Unix() * 1000), Y: y, Color: "#90ed7d"}
+	}
+	return series
+}
+
+// combinedIncomeGwei returns the total income of a day in gwei. The execution layer part is taken from the mev rewards only, as they
+// already fall back to the tx fee rewards for blocks that were not built by a relay and adding the el rewards would count them twice.
+// The conversion from wei is done in decimal so large mev rewards don't lose precision.
+func combinedIncomeGwei(h types.ValidatorIncomeHistory) decimal.Decimal {
+	return decimal.NewFromInt(h.ClRewards).Add(h.MevRewards.Div(decimal.NewFromInt(1e9)))
+}
+
+func combinedIncomeHistoryChartSeries(incomeHistory []types.ValidatorIncomeHistory, exchangeRate float64) []*types.ChartDataPoint {
+	series := make([]*types.ChartDataPoint, len(incomeHistory))
+	for i, h := range incomeHistory {
+		total := combinedIncomeGwei(h)
+		color := "#7cb5ec"
+		if total.IsNegative() {
+			color = "#f7a35c"
+		}
+		y, _ := total.Div(decimal.NewFromInt(int64(utils.ClCurrencyDivisor()))).Mul(decimal.NewFromFloat(exchangeRate)).Float64()
+		series[i] = &types.ChartDataPoint{X: float64(utils.DayToTime(h.Day).Unix() * 1000), Y: y, Color: color}
+	}
+	return series
+}
+
+// GetValidatorIncomeHistoryInCurrency returns the daily total income (cl rewards and execution layer rewards) of the validators
+// between from and to in the given currency, using the current exchange rate. ETH is passed through with a rate of 1.
+func GetValidatorIncomeHistoryInCurrency(validatorIndices []uint64, currency string, from, to uint64, lastFinalizedEpoch uint64) ([]types.ChartDataPoint, error) {
+	if !utils.SliceContains(price.GetAvailableCurrencies(), currency) {
+		return nil, fmt.Errorf("unsupported currency %v, supported currencies are %v", currency, strings.Join(price.GetAvailableCurrencies(), ", "))
+	}
+
+	incomeHistory, err := GetValidatorIncomeHistory(validatorIndices, from, to, lastFinalizedEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeRate := 1.0
+	if currency != "ETH" {
+		exchangeRate = utils.ExchangeRateForCurrency(currency)
+	}
+	return incomeHistoryInCurrency(incomeHistory, exchangeRate), nil
+}
+
+func incomeHistoryInCurrency(incomeHistory []types.ValidatorIncomeHistory, exchangeRate float64) []types.ChartDataPoint {
+	series := combinedIncomeHistoryChartSeries(incomeHistory, exchangeRate)
+	points := make([]types.ChartDataPoint, len(series))
+	for i, point := range series {
+		points[i] = *point
+	}
+	return points
+}
+
+func GetValidatorIncomeHistory(validatorIndices []uint64, lowerBoundDay uint64, upperBoundDay uint64, lastFinalizedEpoch uint64) ([]types.ValidatorIncomeHistory, error) {
+	if len(validatorIndices) == 0 {
+		return []types.ValidatorIncomeHistory{}, nil
+	}
+
+	if upperBoundDay == 0 {
+		upperBoundDay = 65536
+	}
+
+	validatorIndices = utils.SortedUniqueUint64(validatorIndices)
+	validatorIndicesStr := make([]string, len(validatorIndices))
+	for i, v := range validatorIndices {
+		validatorIndicesStr[i] = fmt.Sprintf("%d", v)
+	}
+
+	validatorIndicesPqArr := pq.Array(validatorIndices)
+
+	// only the exported days are cached, the estimate of the current day is based on live balances and therefore retrieved on every call
+	var result []types.ValidatorIncomeHistory
+	cacheDur := time.Second * time.Duration(utils.Config.Chain.Config.SecondsPerSlot*utils.Config.Chain.Config.SlotsPerEpoch+10) // updates every epoch, keep 10sec longer
+	cacheKey := fmt.Sprintf("%d:validatorIncomeHistory:%d:%d:%d:%s", utils.Config.Chain.Config.DepositChainID, lowerBoundDay, upperBoundDay, lastFinalizedEpoch, strings.Join(validatorIndicesStr, ","))
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, cacheDur, []types.ValidatorIncomeHistory{}); err == nil {
+		result = cached.([]types.ValidatorIncomeHistory)
+	} else {
+		// the last computed series is kept independent of the finalized epoch, so that on a miss only the days after it have to be
+		// queried. It is keyed on the version of the exported days, so it is not used anymore once a day is deleted or re-exported.
+		statsVersion, err := getValidatorStatsVersion()
+		if err != nil {
+			return nil, err
+		}
+		seriesCacheDur := time.Hour * 24
+		seriesCacheKey := fmt.Sprintf("%d:validatorIncomeHistorySeries:%s:%d:%d:%s", utils.Config.Chain.Config.DepositChainID, statsVersion, lowerBoundDay, upperBoundDay, strings.Join(validatorIndicesStr, ","))
+
+		var series []types.ValidatorIncomeHistory
+		if cached, err := cache.TieredCache.GetWithLocalTimeout(seriesCacheKey, seriesCacheDur, []types.ValidatorIncomeHistory{}); err == nil {
+			series = cached.([]types.ValidatorIncomeHistory)
+		}
+
+		fromDay := incrementalIncomeHistoryFromDay(series, lowerBoundDay)
+		newer := []types.ValidatorIncomeHistory{}
+		err = ReaderDb.Select(&newer, `
+			SELECT 
+				day, 
+				SUM(COALESCE(cl_rewards_gwei, 0)) AS cl_rewards_gwei,
+				SUM(COALESCE(cl_rewards_gwei_net, 0)) AS cl_rewards_gwei_net,
+				SUM(COALESCE(el_rewards_wei, 0)) AS el_rewards_wei,
+				SUM(COALESCE(mev_rewards_wei, 0)) AS mev_rewards_wei,
+				SUM(COALESCE(end_balance, 0)) AS end_balance
+			FROM validator_stats 
+			WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3 
+			GROUP BY day 
+			ORDER BY day
+		;`, validatorIndicesPqArr, fromDay, upperBoundDay)
+		if err != nil {
+			return nil, err
+		}
+		result = mergeIncomeHistory(series, newer)
+
+		go func(result []types.ValidatorIncomeHistory) {
+			err := cache.TieredCache.Set(cacheKey, result, cacheDur)
+			if err != nil {
+				utils.LogError(err, fmt.Errorf("error setting tieredCache for GetValidatorIncomeHistory with key %v", cacheKey), 0)
+			}
+			err = cache.TieredCache.Set(seriesCacheKey, result, seriesCacheDur)
+			if err != nil {
+				utils.LogError(err, fmt.Errorf("error setting tieredCache for GetValidatorIncomeHistory with key %v", seriesCacheKey), 0)
+			}
+		}(result)
+	}
+
+	// retrieve rewards for epochs not yet in stats
+	if upperBoundDay == 65536 {
+		// the latest days can already contain rows of completed sub-exports, the current day estimate starts after the last
+		// completely exported day and replaces them
+		lastDay, err := GetLastFinalizedStatisticDay()
+		if err != nil && !errors.Is(err, ErrNoStatisticsExported) {
+			return nil, err
+		}
+		result = incomeHistoryUpToDay(result, lastDay)
+
+		currentDayIncome, err := getValidatorCurrentDayIncome(validatorIndices, lastDay, lastFinalizedEpoch)
+		if err != nil {
+			return nil, err
+		}
+		result = appendCurrentDayIncome(result, currentDayIncome)
+	}
+
+	return result, nil
+}
+
+// getValidatorStatsVersion returns a version of the completely exported days, consisting of their number, the last one and the time
+// the last one was completed. It changes whenever a day is completed, deleted or re-exported.
+func getValidatorStatsVersion() (string, error) {
+	version := struct {
+		Days          uint64 `db:"days"`
+		LastDay       int64  `db:"last_day"`
+		LastCompleted int64  `db:"last_completed"`
+	}{}
+	err := ReaderDb.Get(&version, `
+		SELECT
+			COUNT(*) AS days,
+			COALESCE(MAX(day), -1) AS last_day,
+			COALESCE(EXTRACT(EPOCH FROM MAX(completed_ts))::BIGINT, 0) AS last_completed
+		FROM validator_stats_status
+		WHERE status`)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving the version of the exported statistics: %w", err)
+	}
+	return fmt.Sprintf("%d-%d-%d", version.Days, version.LastDay, version.LastCompleted), nil
+}
+
+// incrementalIncomeHistoryFromDay returns the first day that has to be queried to bring the cached series up to date. The last cached
+// day is queried again as it may have been cached before all of its statistics were exported.
+func incrementalIncomeHistoryFromDay(series []types.ValidatorIncomeHistory, lowerBoundDay uint64) uint64 {
+	if len(series) == 0 {
+		return lowerBoundDay
+	}
+	lastDay := series[len(series)-1].Day
+	if lastDay < 0 || uint64(lastDay) < lowerBoundDay {
+		return lowerBoundDay
+	}
+	return uint64(lastDay)
+}
+
+// mergeIncomeHistory returns a copy of the (possibly cached) series with all days of newer appended, days contained in both are taken
+// from newer. Both have to be ordered by day.
+func mergeIncomeHistory(series, newer []types.ValidatorIncomeHistory) []types.ValidatorIncomeHistory {
+	result := make([]types.ValidatorIncomeHistory, 0, len(series)+len(newer))
+	for _, h := range series {
+		if len(newer) > 0 && h.Day >= newer[0].Day {
+			break
+		}
+		result = append(result, h)
+	}
+	return append(result, newer...)
+}
+
+// GetValidatorEffectiveBalanceHistory returns the summed up end_effective_balance of the validators for every day between fromDay and
+// toDay (inclusive). Like GetValidatorIncomeHistory, the exported days are cached and the day following the last exported day is
+// taken from the live effective balances at the last finalized epoch.
+func GetValidatorEffectiveBalanceHistory(validatorIndices []uint64, fromDay, toDay uint64) ([]types.ValidatorEffectiveBalanceHistory, error) {
+	if len(validatorIndices) == 0 || fromDay > toDay {
+		return []types.ValidatorEffectiveBalanceHistory{}, nil
+	}
+
+	validatorIndices = utils.SortedUniqueUint64(validatorIndices)
+	validatorIndicesStr := make([]string, len(validatorIndices))
+	for i, v := range validatorIndices {
+		validatorIndicesStr[i] = fmt.Sprintf("%d", v)
+	}
+
+	// the current day starts after the last completely exported day, rows of its completed sub-exports are not taken into account
+	currentDay := uint64(0)
+	lastDay, err := GetLastFinalizedStatisticDay()
+	if err == nil {
+		currentDay = lastDay + 1
+	} else if !errors.Is(err, ErrNoStatisticsExported) {
+		return nil, err
+	}
+
+	var result []types.ValidatorEffectiveBalanceHistory
+	if currentDay > fromDay {
+		cacheDur := time.Second * time.Duration(utils.Config.Chain.Config.SecondsPerSlot*utils.Config.Chain.Config.SlotsPerEpoch+10) // updates every epoch, keep 10sec longer
+		cacheKey := fmt.Sprintf("%d:validatorEffectiveBalanceHistory:%d:%d:%d:%s", utils.Config.Chain.Config.DepositChainID, fromDay, toDay, lastDay, strings.Join(validatorIndicesStr, ","))
+		if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, cacheDur, []types.ValidatorEffectiveBalanceHistory{}); err == nil {
+			result = cached.([]types.ValidatorEffectiveBalanceHistory)
+		} else {
+			upToDay := toDay
+			if lastDay < upToDay {
+				upToDay = lastDay
+			}
+			result = []types.ValidatorEffectiveBalanceHistory{}
+			err := ReaderDb.Select(&result, `
+				SELECT day, SUM(COALESCE(end_effective_balance, 0)) AS end_effective_balance
+				FROM validator_stats
+				WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3
+				GROUP BY day
+				ORDER BY day`, pq.Array(validatorIndices), fromDay, upToDay)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving effective balance history of %v validators: %w", len(validatorIndices), err)
+			}
+
+			go func(result []types.ValidatorEffectiveBalanceHistory) {
+				err := cache.TieredCache.Set(cacheKey, result, cacheDur)
+				if err != nil {
+					utils.LogError(err, fmt.Errorf("error setting tieredCache for GetValidatorEffectiveBalanceHistory with key %v", cacheKey), 0)
+				}
+			}(result)
+		}
+	}
+
+	if currentDay < fromDay || currentDay > toDay {
+		return result, nil
+	}
+
+	var lastFinalizedEpoch uint64
+	err = ReaderDb.Get(&lastFinalizedEpoch, "SELECT COALESCE(MAX(epoch), 0) FROM epochs WHERE finalized")
+	if err != nil {
+		return nil, fmt.Errorf("error getting last finalized epoch: %w", err)
+	}
+	balances, err := BigtableClient.GetValidatorBalanceHistory(validatorIndices, lastFinalizedEpoch, lastFinalizedEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting effective balances of epoch %v: %w", lastFinalizedEpoch, err)
+	}
+	return appendCurrentDayEffectiveBalance(result, currentDay, balances), nil
+}
+
+// GetValidatorDepositHistory returns the number and the amount of the deposits of the validators summed up per day between fromDay and
+// toDay (inclusive), days without deposits are left out. The genesis deposits are folded into day 0, see foldGenesisDeposits.
+func GetValidatorDepositHistory(validatorIndices []uint64, fromDay, toDay uint64) ([]types.ValidatorDepositHistory, error) {
+	history := []types.ValidatorDepositHistory{}
+	if len(validatorIndices) == 0 {
+		return history, nil
+	}
+
+	// the genesis deposits are stored at day -1
+	lowerBoundDay := int64(fromDay)
+	if lowerBoundDay == 0 {
+		lowerBoundDay = -1
+	}
+	err := ReaderDb.Select(&history, `
+		SELECT day, SUM(COALESCE(deposits, 0)) AS deposits, SUM(COALESCE(deposits_amount, 0)) AS deposits_amount
+		FROM validator_stats
+		WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3 AND deposits > 0
+		GROUP BY day
+		ORDER BY day`, pq.Array(utils.SortedUniqueUint64(validatorIndices)), lowerBoundDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving deposit history of %v validators: %w", len(validatorIndices), err)
+	}
+	return foldGenesisDeposits(history), nil
+}
+
+// foldGenesisDeposits adds the genesis deposits stored at day -1 to day 0 of the history and sets its IncludesGenesisDeposits flag,
+// so charts don't show a negative day. The history has to be ordered by day.
+func foldGenesisDeposits(history []types.ValidatorDepositHistory) []types.ValidatorDepositHistory {
+	if len(history) == 0 || history[0].Day != -1 {
+		return history
+	}
+	genesis := history[0]
+	if len(history) > 1 && history[1].Day == 0 {
+		history[1].Deposits += genesis.Deposits
+		history[1].DepositsAmount += genesis.DepositsAmount
+		history[1].IncludesGenesisDeposits = true
+		return history[1:]
+	}
+	history[0].Day = 0
+	history[0].IncludesGenesisDeposits = true
+	return history
+}
+
+// GetValidatorWithdrawalHistory returns the number and the amount of the withdrawals of the validators summed up per day between
+// fromDay and toDay (inclusive), days without withdrawals are left out
+func GetValidatorWithdrawalHistory(validatorIndices []uint64, fromDay, toDay uint64) ([]types.ValidatorWithdrawalHistory, error) {
+	history := []types.ValidatorWithdrawalHistory{}
+	if len(validatorIndices) == 0 {
+		return history, nil
+	}
+
+	err := ReaderDb.Select(&history, `
+		SELECT day, SUM(COALESCE(withdrawals, 0)) AS withdrawals, SUM(COALESCE(withdrawals_amount, 0)) AS withdrawals_amount
+		FROM validator_stats
+		WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3 AND withdrawals > 0
+		GROUP BY day
+		ORDER BY day`, pq.Array(utils.SortedUniqueUint64(validatorIndices)), fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving withdrawal history of %v validators: %w", len(validatorIndices), err)
+	}
+	return history, nil
+}
+
+type validatorIncomeAnnotationRow struct {
+	Day                  int64 `db:"day"`
+	ClRewards            int64 `db:"cl_rewards_gwei"`
+	SlashingEvents       int64 `db:"slashing_events"`
+	OrphanedBlocks       int64 `db:"orphaned_blocks"`
+	MissedBlocks         int64 `db:"missed_blocks"`
+	MissedSync           int64 `db:"missed_sync"`
+	MissedAttestations   int64 `db:"missed_attestations"`
+	OrphanedAttestations int64 `db:"orphaned_attestations"`
+}
+
+// GetValidatorIncomeAnnotations returns the reasons for the days between fromDay and toDay (inclusive) on which the summed up cl rewards
+// of the validators were negative, ordered by day. It is meant to be shown next to GetValidatorIncomeHistoryChart, which colors these days.
+func GetValidatorIncomeAnnotations(validatorIndices []uint64, fromDay, toDay uint64) ([]types.ValidatorIncomeAnnotation, error) {
+	if len(validatorIndices) == 0 {
+		return []types.ValidatorIncomeAnnotation{}, nil
+	}
+
+	rows := []validatorIncomeAnnotationRow{}
+	err := ReaderDb.Select(&rows, `
+		SELECT
+			day,
+			SUM(COALESCE(cl_rewards_gwei, 0)) AS cl_rewards_gwei,
+			(
+				SELECT COUNT(*) FROM validator_slashing_events e WHERE e.validatorindex = ANY($1) AND e.day = validator_stats.day
+			) AS slashing_events,
+			SUM(COALESCE(orphaned_blocks, 0)) AS orphaned_blocks,
+			SUM(COALESCE(missed_blocks, 0)) AS missed_blocks,
+			SUM(COALESCE(missed_sync, 0)) AS missed_sync,
+			SUM(COALESCE(missed_attestations, 0)) AS missed_attestations,
+			SUM(COALESCE(orphaned_attestations, 0)) AS orphaned_attestations
+		FROM validator_stats
+		WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3
+		GROUP BY day
+		HAVING SUM(COALESCE(cl_rewards_gwei, 0)) < 0
+		ORDER BY day`, pq.Array(utils.SortedUniqueUint64(validatorIndices)), fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving income annotations of %v validators: %w", len(validatorIndices), err)
+	}
+	return incomeAnnotations(rows), nil
+}
+
+// incomeAnnotations returns the reasons of all days with negative cl rewards, days without any failed duty are annotated as unknown
+func incomeAnnotations(rows []validatorIncomeAnnotationRow) []types.ValidatorIncomeAnnotation {
+	annotations := make([]types.ValidatorIncomeAnnotation, 0, len(rows))
+	for _, row := range rows {
+		if row.ClRewards >= 0 {
+			continue
+		}
+		reasons := []string{}
+		for _, reason := range []struct {
+			name  string
+			count int64
+		}{
+			{types.IncomeReasonSlashed, row.SlashingEvents},
+			{types.IncomeReasonOrphanedBlocks, row.OrphanedBlocks},
+			{types.IncomeReasonMissedBlocks, row.MissedBlocks},
+			{types.IncomeReasonMissedSync, row.MissedSync},
+			{types.IncomeReasonMissedAttestations, row.MissedAttestations},
+			{types.IncomeReasonOrphanedAttestations, row.OrphanedAttestations},
+		} {
+			if reason.count > 0 {
+				reasons = append(reasons, reason.name)
+			}
+		}
+		if len(reasons) == 0 {
+			reasons = append(reasons, types.IncomeReasonUnknown)
+		}
+		annotations = append(annotations, types.ValidatorIncomeAnnotation{Day: row.Day, Reasons: reasons})
+	}
+	return annotations
+}
+
+// validatorMissedRewardsRow holds the summed up statistics of a set of validators over a period required to estimate the reward
+// of a single attestation, sync committee duty and block proposal. Days with sync committee duties are aggregated separately
+// as their cl rewards include the sync rewards.
+type validatorMissedRewardsRow struct {
+	MissedAttestations      int64 `db:"missed_attestations"`
+	MissedSync              int64 `db:"missed_sync"`
+	MissedBlocks            int64 `db:"missed_blocks"`
+	AttestationDays         int64 `db:"attestation_days"`
+	AttestationDayRewards   int64 `db:"attestation_day_rewards"`
+	AttestationDayMissed    int64 `db:"attestation_day_missed"`
+	SyncDays                int64 `db:"sync_days"`
+	SyncDayRewards          int64 `db:"sync_day_rewards"`
+	SyncDayMissed           int64 `db:"sync_day_missed"`
+	ParticipatedSync        int64 `db:"participated_sync"`
+	ProposedBlocks          int64 `db:"proposed_blocks"`
+	ProposerRewards         int64 `db:"proposer_rewards"`
+	ProposedBlocksElRewards int64 `db:"el_rewards_gwei"`
+}
+
+// GetValidatorMissedRewardsEstimate estimates the income the validators did not earn between fromDay and toDay (inclusive) because of
+// missed attestations, missed sync committee duties and missed block proposals by multiplying the number of missed duties with the
+// reward of a single duty. The reward of a duty is derived from the averages of the validators during the period:
+//   - an attestation earns the cl rewards without proposer rewards of the days without sync committee duties divided by the
+//     number of successful attestations of these days
+//   - a sync committee duty earns the cl rewards without proposer and attestation rewards of the days with sync committee duties
+//     divided by the number of successful sync committee duties
+//   - a block proposal earns the cl proposer and el rewards of the proposed blocks divided by their number
+//
+// Penalties for missed duties are not part of the estimate. Duties the validators did not perform successfully during the period
+// can't be derived and are estimated as 0, the statistics.missedRewardsAssumptions config overrides the reward of a duty, e.g. to
+// tune the estimate after a fork changed the rewards.
+func GetValidatorMissedRewardsEstimate(validatorIndices []uint64, fromDay, toDay uint64) (*types.ValidatorMissedRewardsEstimate, error) {
+	if len(validatorIndices) == 0 {
+		return &types.ValidatorMissedRewardsEstimate{}, nil
+	}
+
+	row := validatorMissedRewardsRow{}
+	err := ReaderDb.Get(&row, `
+		SELECT
+			COALESCE(SUM(missed_attestations), 0) AS missed_attestations,
+			COALESCE(SUM(missed_sync), 0) AS missed_sync,
+			COALESCE(SUM(missed_blocks), 0) AS missed_blocks,
+			COUNT(*) FILTER (WHERE COALESCE(participated_sync, 0) = 0 AND COALESCE(missed_sync, 0) = 0) AS attestation_days,
+			COALESCE(SUM(COALESCE(cl_rewards_gwei, 0) - COALESCE(cl_proposer_rewards_gwei, 0)) FILTER (WHERE COALESCE(participated_sync, 0) = 0 AND COALESCE(missed_sync, 0) = 0), 0) AS attestation_day_rewards,
+			COALESCE(SUM(COALESCE(missed_attestations, 0) + COALESCE(orphaned_attestations, 0)) FILTER (WHERE COALESCE(participated_sync, 0) = 0 AND COALESCE(missed_sync, 0) = 0), 0) AS attestation_day_missed,
+			COUNT(*) FILTER (WHERE participated_sync > 0) AS sync_days,
+			COALESCE(SUM(COALESCE(cl_rewards_gwei, 0) - COALESCE(cl_proposer_rewards_gwei, 0)) FILTER (WHERE participated_sync > 0), 0) AS sync_day_rewards,
+			COALESCE(SUM(COALESCE(missed_attestations, 0) + COALESCE(orphaned_attestations, 0)) FILTER (WHERE participated_sync > 0), 0) AS sync_day_missed,
+			COALESCE(SUM(participated_sync), 0) AS participated_sync,
+			COALESCE(SUM(proposed_blocks), 0) AS proposed_blocks,
+			COALESCE(SUM(cl_proposer_rewards_gwei), 0) AS proposer_rewards,
+			COALESCE(SUM(el_rewards_wei) / 1e9, 0)::BIGINT AS el_rewards_gwei
+		FROM validator_stats
+		WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3`, pq.Array(utils.SortedUniqueUint64(validatorIndices)), fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving missed duties of %v validators: %w", len(validatorIndices), err)
+	}
+	// the days of a chain whose day is not a multiple of the epoch duration differ by at most one epoch
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(fromDay)
+	return missedRewardsEstimate(row, int64(lastEpoch-firstEpoch+1)), nil
+}
+
+// missedRewardsEstimate derives the reward of a single duty from the period averages, applies the configured overrides and multiplies
+// them with the missed duties. Negative derived rewards, e.g. of a period with an inactivity leak, are estimated as 0.
+func missedRewardsEstimate(row validatorMissedRewardsRow, epochsPerDay int64) *types.ValidatorMissedRewardsEstimate {
+	perDuty := func(rewards, duties int64) int64 {
+		if duties <= 0 || rewards <= 0 {
+			return 0
+		}
+		return rewards / duties
+	}
+	attestationReward := perDuty(row.AttestationDayRewards, row.AttestationDays*epochsPerDay-row.AttestationDayMissed)
+	syncReward := perDuty(row.SyncDayRewards-attestationReward*(row.SyncDays*epochsPerDay-row.SyncDayMissed), row.ParticipatedSync)
+	proposalReward := perDuty(row.ProposerRewards+row.ProposedBlocksElRewards, row.ProposedBlocks)
+
+	assumptions := utils.Config.Statistics.MissedRewardsAssumptions
+	if assumptions.AttestationRewardGwei > 0 {
+		attestationReward = assumptions.AttestationRewardGwei
+	}
+	if assumptions.SyncRewardGwei > 0 {
+		syncReward = assumptions.SyncRewardGwei
+	}
+	if assumptions.ProposalRewardGwei > 0 {
+		proposalReward = assumptions.ProposalRewardGwei
+	}
+
+	estimate := &types.ValidatorMissedRewardsEstimate{
+		MissedAttestations:    row.MissedAttestations,
+		MissedSync:            row.MissedSync,
+		MissedBlocks:          row.MissedBlocks,
+		AttestationRewardGwei: attestationReward,
+		SyncRewardGwei:        syncReward,
+		ProposalRewardGwei:    proposalReward,
+		AttestationsGwei:      row.MissedAttestations * attestationReward,
+		SyncGwei:              row.MissedSync * syncReward,
+		ProposalsGwei:         row.MissedBlocks * proposalReward,
+	}
+	estimate.TotalGwei = estimate.AttestationsGwei + estimate.SyncGwei + estimate.ProposalsGwei
+	return estimate
+}
+
+// appendCurrentDayEffectiveBalance returns a copy of the (possibly cached) history with the summed up latest effective balance of each
+// validator appended as the current day, so the cached slice is never modified
+func appendCurrentDayEffectiveBalance(history []types.ValidatorEffectiveBalanceHistory, currentDay uint64, balances map[uint64][]*types.ValidatorBalance) []types.ValidatorEffectiveBalanceHistory {
+	current := types.ValidatorEffectiveBalanceHistory{Day: int64(currentDay)}
+	for _, balance := range balances {
+		if len(balance) == 0 {
+			continue
+		}
+		current.EffectiveBalance += balance[0].EffectiveBalance
+	}
+
+	result := make([]types.ValidatorEffectiveBalanceHistory, len(history), len(history)+1)
+	copy(result, history)
+	return append(result, current)
+}
+
+// GetValidatorIncomeHistoryPerValidator returns the exported daily income history of each of the given validators keyed by validator index
+func GetValidatorIncomeHistoryPerValidator(validatorIndices []uint64, lowerBoundDay uint64, upperBoundDay uint64) (map[uint64][]types.ValidatorIncomeHistory, error) {
+	if len(validatorIndices) == 0 {
+		return map[uint64][]types.ValidatorIncomeHistory{}, nil
+	}
+
+	if upperBoundDay == 0 {
+		upperBoundDay = 65536
+	}
+
+	validatorIndices = utils.SortedUniqueUint64(validatorIndices)
+	validatorIndicesStr := make([]string, len(validatorIndices))
+	for i, v := range validatorIndices {
+		validatorIndicesStr[i] = fmt.Sprintf("%d", v)
+	}
+
+	lastExportedDay, err := GetLastExportedStatisticDay()
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDur := time.Second * time.Duration(utils.Config.Chain.Config.SecondsPerSlot*utils.Config.Chain.Config.SlotsPerEpoch+10) // updates every epoch, keep 10sec longer
+	cacheKey := fmt.Sprintf("%d:validatorIncomeHistoryPerValidator:%d:%d:%d:%s", utils.Config.Chain.Config.DepositChainID, lowerBoundDay, upperBoundDay, lastExportedDay, strings.Join(validatorIndicesStr, ","))
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, cacheDur, map[uint64][]types.ValidatorIncomeHistory{}); err == nil {
+		return cached.(map[uint64][]types.ValidatorIncomeHistory), nil
+	}
+
+	rows := []*validatorIncomeHistoryRow{}
+	err = ReaderDb.Select(&rows, `
+		SELECT 
+			validatorindex,
+			day, 
+			COALESCE(cl_rewards_gwei, 0) AS cl_rewards_gwei,
+			COALESCE(cl_rewards_gwei_net, 0) AS cl_rewards_gwei_net,
+			COALESCE(el_rewards_wei, 0) AS el_rewards_wei,
+			COALESCE(mev_rewards_wei, 0) AS mev_rewards_wei,
+			end_balance,
+			start_balance,
+			deposits_amount,
+			withdrawals_amount
+		FROM validator_stats 
+		WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3 
+		ORDER BY validatorindex, day
+	;`, pq.Array(validatorIndices), lowerBoundDay, upperBoundDay)
+	if err != nil {
+		return nil, err
+	}
+
+	result := groupIncomeHistoryByValidator(rows)
+
+	go func(result map[uint64][]types.ValidatorIncomeHistory) {
+		err := cache.TieredCache.Set(cacheKey, result, cacheDur)
+		if err != nil {
+			utils.LogError(err, fmt.Errorf("error setting tieredCache for GetValidatorIncomeHistoryPerValidator with key %v", cacheKey), 0)
+		}
+	}(result)
+
+	return result, nil
+}
+
+// GetValidatorIncomeHistoryStream calls cb with the summed up income of the validators for every exported day between fromDay and
+// toDay in ascending order. Other than GetValidatorIncomeHistory the rows are read one by one and neither cached nor kept in memory,
+// which makes it suitable for large validator sets. Iteration stops at the first error returned by cb.
+func GetValidatorIncomeHistoryStream(validatorIndices []uint64, fromDay, toDay uint64, cb func(types.ValidatorIncomeHistory) error) error {
+	if len(validatorIndices) == 0 {
+		return nil
+	}
+
+	rows, err := ReaderDb.Queryx(`
+		SELECT 
+			day, 
+			SUM(COALESCE(cl_rewards_gwei, 0)) AS cl_rewards_gwei,
+			SUM(COALESCE(cl_rewards_gwei_net, 0)) AS cl_rewards_gwei_net,
+			SUM(COALESCE(el_rewards_wei, 0)) AS el_rewards_wei,
+			SUM(COALESCE(mev_rewards_wei, 0)) AS mev_rewards_wei,
+			SUM(COALESCE(end_balance, 0)) AS end_balance
+		FROM validator_stats 
+		WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3 
+		GROUP BY day 
+		ORDER BY day`, pq.Array(utils.SortedUniqueUint64(validatorIndices)), fromDay, toDay)
+	if err != nil {
+		return fmt.Errorf("error retrieving validator income history: %w", err)
+	}
+	defer rows.Close()
+
+	next := func() (*types.ValidatorIncomeHistory, error) {
+		if !rows.Next() {
+			return nil, rows.Err()
+		}
+		row := &types.ValidatorIncomeHistory{}
+		return row, rows.StructScan(row)
+	}
+
+	return streamIncomeHistory(next, cb)
+}
+
+// streamIncomeHistory passes the rows returned by next to cb until next returns nil
+func streamIncomeHistory(next func() (*types.ValidatorIncomeHistory, error), cb func(types.ValidatorIncomeHistory) error) error {
+	for {
+		row, err := next()
+		if err != nil {
+			return fmt.Errorf("error reading validator income history: %w", err)
+		}
+		if row == nil {
+			return nil
+		}
+		if err := cb(*row); err != nil {
+			return err
+		}
+	}
+}
+
+type validatorIncomeHistoryRow struct {
+	ValidatorIndex uint64 `db:"validatorindex"`
+	types.ValidatorIncomeHistory
+}
+
+// groupIncomeHistoryByValidator splits rows ordered by validator index and day into one history per validator
+func groupIncomeHistoryByValidator(rows []*validatorIncomeHistoryRow) map[uint64][]types.ValidatorIncomeHistory {
+	result := make(map[uint64][]types.ValidatorIncomeHistory)
+	for _, row := range rows {
+		result[row.ValidatorIndex] = append(result[row.ValidatorIndex], row.ValidatorIncomeHistory)
+	}
+	return result
+}
+
+type validatorStatsCSVRow struct {
+	ValidatorIndex uint64          `db:"validatorindex"`
+	Day            int64           `db:"day"`
+	ClRewards      int64           `db:"cl_rewards_gwei"`
+	ElRewards      decimal.Decimal `db:"el_rewards_wei"`
+	MevRewards     decimal.Decimal `db:"mev_rewards_wei"`
+	Withdrawals    int64           `db:"withdrawals_amount"`
+	Deposits       int64           `db:"deposits_amount"`
+	EndBalance     int64           `db:"end_balance"`
+}
+
+// ExportValidatorStatsCSV streams the daily statistics of the validators between fromDay and toDay as csv to w. The values of all
+// validators are summed up per day like in GetValidatorIncomeHistory, unless perValidator is set which emits one row per validator and day.
+func ExportValidatorStatsCSV(w io.Writer, validatorIndices []uint64, fromDay, toDay uint64, currency string, perValidator bool) error {
+	validatorColumn := "0 AS validatorindex"
+	groupBy := "GROUP BY day ORDER BY day"
+	if perValidator {
+		validatorColumn = "validatorindex"
+		groupBy = "GROUP BY day, validatorindex ORDER BY day, validatorindex"
+	}
+
+	rows, err := ReaderDb.Queryx(fmt.Sprintf(`
+		SELECT 
+			%s,
+			day,
+			SUM(COALESCE(cl_rewards_gwei, 0)) AS cl_rewards_gwei,
+			SUM(COALESCE(el_rewards_wei, 0)) AS el_rewards_wei,
+			SUM(COALESCE(mev_rewards_wei, 0)) AS mev_rewards_wei,
+			SUM(COALESCE(withdrawals_amount, 0)) AS withdrawals_amount,
+			SUM(COALESCE(deposits_amount, 0)) AS deposits_amount,
+			SUM(COALESCE(end_balance, 0)) AS end_balance
+		FROM validator_stats
+		WHERE validatorindex = ANY($1) AND day BETWEEN $2 AND $3
+		%s`, validatorColumn, groupBy), pq.Array(validatorIndices), fromDay, toDay)
+	if err != nil {
+		return fmt.Errorf("error retrieving validator stats for csv export: %w", err)
+	}
+	defer rows.Close()
+
+	next := func() (*validatorStatsCSVRow, error) {
+		if !rows.Next() {
+			return nil, rows.Err()
+		}
+		row := &validatorStatsCSVRow{}
+		return row, rows.StructScan(row)
+	}
+
+	return writeValidatorStatsCSV(w, next, currency, utils.ExchangeRateForCurrency(currency), perValidator)
+}
+
+// writeValidatorStatsCSV writes the rows returned by next until it returns nil
+func writeValidatorStatsCSV(w io.Writer, next func() (*validatorStatsCSVRow, error), currency string, exchangeRate float64, perValidator bool) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"day", "date"}
+	if perValidator {
+		header = append(header, "validator")
+	}
+	currency = strings.ToLower(currency)
+	header = append(header, "cl_rewards_eth", "el_rewards_eth", "mev_rewards_eth", "withdrawals_eth", "deposits_eth", "end_balance_eth",
+		"cl_rewards_"+currency, "el_rewards_"+currency, "mev_rewards_"+currency, "end_balance_"+currency)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	gweiToEth := func(gwei int64) decimal.Decimal {
+		return decimal.NewFromInt(gwei).Div(decimal.NewFromInt(int64(utils.ClCurrencyDivisor())))
+	}
+	weiToEth := func(wei decimal.Decimal) decimal.Decimal {
+		return wei.Div(decimal.NewFromInt(1e18))
+	}
+	rate := decimal.NewFromFloat(exchangeRate)
+
+	for {
+		row, err := next()
+		if err != nil {
+			return fmt.Errorf("error reading validator stats for csv export: %w", err)
+		}
+		if row == nil {
+			break
+		}
+
+		cl, el, mev, endBalance := gweiToEth(row.ClRewards), weiToEth(row.ElRewards), weiToEth(row.MevRewards), gweiToEth(row.EndBalance)
+		record := []string{fmt.Sprintf("%d", row.Day), utils.DayToTime(row.Day).UTC().Format("2006-01-02")}
+		if perValidator {
+			record = append(record, fmt.Sprintf("%d", row.ValidatorIndex))
+		}
+		record = append(record, cl.String(), el.String(), mev.String(), gweiToEth(row.Withdrawals).String(), gweiToEth(row.Deposits).String(), endBalance.String(),
+			cl.Mul(rate).StringFixed(2), el.Mul(rate).StringFixed(2), mev.Mul(rate).StringFixed(2), endBalance.Mul(rate).StringFixed(2))
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// getValidatorCurrentDayIncome estimates the income of the validators for the day following lastDay up to lastFinalizedEpoch
+func getValidatorCurrentDayIncome(validatorIndices []uint64, lastDay uint64, lastFinalizedEpoch uint64) (types.ValidatorIncomeHistory, error) {
+	currentDay := lastDay + 1
+	firstEpoch := utils.FirstEpochOfDay(currentDay)
+
+	currentBalances := make(map[uint64]uint64)
+	g := errgroup.Group{}
+	g.Go(func() error {
+		latestBalances, err := BigtableClient.GetValidatorBalanceHistory(validatorIndices, lastFinalizedEpoch, lastFinalizedEpoch)
+		if err != nil {
+			logger.Errorf("error getting validator balance data in GetValidatorEarnings: %v", err)
+			return err
+		}
+
+		for validator, balance := range latestBalances {
+			if len(balance) == 0 {
+				continue
+			}
+
+			currentBalances[validator] = balance[0].Balance
+		}
+		return nil
+	})
+
+	var lastBalances map[uint64]uint64
+	g.Go(func() error {
+		amounts := []validatorAmount{}
+		err := ReaderDb.Select(&amounts, `
+			SELECT validatorindex, COALESCE(end_balance, 0) AS amount
+			FROM validator_stats
+			WHERE day = $2 AND validatorindex = ANY($1)`, pq.Array(validatorIndices), lastDay)
+		lastBalances = validatorAmountsByIndex(validatorIndices, amounts)
+		return err
+	})
+
+	var deposits map[uint64]uint64
+	g.Go(func() error {
+		var err error
+		deposits, err = GetValidatorDepositsForEpochsByValidator(validatorIndices, firstEpoch, lastFinalizedEpoch)
+		return err
+	})
+
+	var withdrawals map[uint64]uint64
+	g.Go(func() error {
+		var err error
+		withdrawals, err = GetValidatorWithdrawalsForEpochsByValidator(validatorIndices, firstEpoch, lastFinalizedEpoch)
+		return err
+	})
+
+	var elRewards *proposerElRewards
+	g.Go(func() error {
+		var err error
+		elRewards, err = getValidatorElRewardsForEpochs(validatorIndices, firstEpoch, lastFinalizedEpoch)
+		return err
+	})
+
+	err := g.Wait()
+	if err != nil {
+		return types.ValidatorIncomeHistory{}, err
+	}
+
+	return types.ValidatorIncomeHistory{
+		Day:        int64(currentDay),
+		ClRewards:  estimateCurrentDayClRewards(validatorIndices, currentBalances, lastBalances, deposits, withdrawals),
+		ElRewards:  decimal.NewFromBigInt(elRewards.TxFeeReward, 0),
+		MevRewards: decimal.NewFromBigInt(elRewards.MevReward, 0),
+	}, nil
+}
+
+// estimateCurrentDayClRewards sums up the balance changes of the validators since the end of the last exported day. A validator
+// without a current balance and without withdrawals has exited or is missing from the balance data, its balance did not turn
+// into a loss so it does not contribute to the estimate instead of causing a spurious cliff.
+func estimateCurrentDayClRewards(validatorIndices []uint64, currentBalances, lastBalances, deposits, withdrawals map[uint64]uint64) int64 {
+	total := int64(0)
+	for _, validator := range validatorIndices {
+		if currentBalances[validator] == 0 && withdrawals[validator] == 0 {
+			continue
+		}
+		total += int64(currentBalances[validator]) - int64(lastBalances[validator]) - int64(deposits[validator]) + int64(withdrawals[validator])
+	}
+	return total
+}
+
+// getValidatorElRewardsForEpochs returns the summed el and mev rewards of the blocks proposed by the validators in the given epochs
+func getValidatorElRewardsForEpochs(validatorIndices []uint64, fromEpoch, toEpoch uint64) (*proposerElRewards, error) {
+	total := &proposerElRewards{TxFeeReward: big.NewInt(0), MevReward: big.NewInt(0), HadRelayData: true}
+
+	blocks := []struct {
+		ExecBlockNumber uint64 `db:"exec_block_number"`
+		Proposer        uint64 `db:"proposer"`
+	}{}
+	err := ReaderDb.Select(&blocks, "SELECT exec_block_number, proposer FROM blocks WHERE proposer = ANY($1) AND epoch >= $2 AND epoch <= $3 AND exec_block_number > 0 AND status = '1'", pq.Array(validatorIndices), fromEpoch, toEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving blocks data: %w", err)
+	}
+	if len(blocks) == 0 {
+		return total, nil
+	}
+
+	numbers := make([]uint64, 0, len(blocks))
+	blockProposers := make(map[uint64]uint64, len(blocks))
+	for _, b := range blocks {
+		numbers = append(numbers, b.ExecBlockNumber)
+		blockProposers[b.ExecBlockNumber] = b.Proposer
+	}
+
+	blocksData, err := getBlocksIndexedChunked(context.Background(), numbers)
+	if err != nil {
+		return nil, fmt.Errorf("error in GetBlocksIndexedMultiple: %w", err)
+	}
+
+	relaysData, err := getRelayDataForIndexedBlocksCached(blocksData)
+	if err != nil {
+		return nil, fmt.Errorf("error in GetRelayDataForIndexedBlocks: %w", err)
+	}
+
+	overrides, err := mevBribeOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rewards := range aggregateProposerElRewards(blocksData, blockProposers, relaysData, overrides) {
+		total.TxFeeReward.Add(total.TxFeeReward, rewards.TxFeeReward)
+		total.MevReward.Add(total.MevReward, rewards.MevReward)
+		total.HadRelayData = total.HadRelayData && rewards.HadRelayData
+	}
+	return total, nil
+}
+
+// GetValidatorWorstDays returns the exported days with the lowest summed cl rewards of the given validators, worst day first.
+// The estimate of the current day is never included as a partial day would otherwise rank as the worst one.
+func GetValidatorWorstDays(validatorIndices []uint64, limit int) ([]types.ValidatorIncomeHistory, error) {
+	if limit <= 0 {
+		return []types.ValidatorIncomeHistory{}, nil
+	}
+
+	lastExportedDay, err := GetLastExportedStatisticDay()
+	if err != nil {
+		return nil, err
+	}
+
+	// passing an upper bound day skips the current day estimate, the cached history is shared with GetValidatorIncomeHistory
+	history, err := GetValidatorIncomeHistory(validatorIndices, 0, lastExportedDay, 0)
+	if err != nil {
+		return nil, err
+	}
+	return worstIncomeDays(history, lastExportedDay, limit), nil
+}
+
+// worstIncomeDays returns up to limit days of the history not after lastExportedDay ordered by ascending cl rewards, ties are
+// ordered by day. The (possibly cached) history is not modified.
+func worstIncomeDays(history []types.ValidatorIncomeHistory, lastExportedDay uint64, limit int) []types.ValidatorIncomeHistory {
+	days := make([]types.ValidatorIncomeHistory, 0, len(history))
+	for _, h := range history {
+		if h.Day >= 0 && uint64(h.Day) <= lastExportedDay {
+			days = append(days, h)
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		if days[i].ClRewards != days[j].ClRewards {
+			return days[i].ClRewards < days[j].ClRewards
+		}
+		return days[i].Day < days[j].Day
+	})
+	if len(days) > limit {
+		days = days[:limit]
+	}
+	return days
+}
+
+// incomeHistoryUpToDay returns the days of the history up to and including lastDay. The (possibly cached) history is not modified.
+func incomeHistoryUpToDay(history []types.ValidatorIncomeHistory, lastDay uint64) []types.ValidatorIncomeHistory {
+	end := len(history)
+	for end > 0 && history[end-1].Day > int64(lastDay) {
+		end--
+	}
+	return history[:end]
+}
+
+// appendCurrentDayIncome returns a copy of the (possibly cached) history with the current day appended, so the cached slice is never modified
+func appendCurrentDayIncome(history []types.ValidatorIncomeHistory, currentDay types.ValidatorIncomeHistory) []types.ValidatorIncomeHistory {
+	result := make([]types.ValidatorIncomeHistory, len(history), len(history)+1)
+	copy(result, history)
+	return append(result, currentDay)
+}
+
+// excludedValidatorsCondition returns the sql condition (starting with AND) and its argument that excludes the given validators from a
+// validator_stats query, argIndex is the position of the argument in the query. Both are empty if no validators are excluded.
+func excludedValidatorsCondition(excluded []uint64, argIndex int) (string, []interface{}) {
+	if len(excluded) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(" AND NOT (validatorindex = ANY($%d))", argIndex), []interface{}{pq.Array(excluded)}
+}
+
+func WriteChartSeriesForDay(day int64) error {
+	startTs := time.Now()
+
+	if day < 0 {
+		// before the beaconchain
+		return fmt.Errorf("this function does not yet pre-beaconchain blocks")
+	}
+
+	beaconchainDay := int64(utils.FirstEpochOfDay(uint64(day)))
+
+	startDate := utils.EpochToTime(uint64(beaconchainDay))
+	dateTrunc := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	// chart days are utc calendar days, both boundaries are aligned to the start of the epoch containing midnight
+	// inclusive slot
+	firstSlot := utils.TimeToSlot(uint64(dateTrunc.Unix()))
+	firstSlot -= firstSlot % utils.Config.Chain.Config.SlotsPerEpoch
+	firstEpoch := firstSlot / utils.Config.Chain.Config.SlotsPerEpoch
+	// exclusive slot
+	lastSlot := utils.TimeToSlot(uint64(dateTrunc.Add(time.Hour * 24).Unix()))
+	lastSlot -= lastSlot % utils.Config.Chain.Config.SlotsPerEpoch
+	lastEpoch := lastSlot / utils.Config.Chain.Config.SlotsPerEpoch
+	epochsInDay := lastEpoch - firstEpoch
+
+	finalizedCount, err := CountFinalizedEpochs(firstEpoch, lastEpoch)
+	if err != nil {
+		return err
+	}
+
+	if finalizedCount < epochsInDay {
+		return fmt.Errorf("%w: delaying chart series export as not all epochs for day %v finalized. %v of %v", ErrDayNotFinalized, day, finalizedCount, epochsInDay)
+	}
+
+	firstBlock, err := getEth1BlockNumberForSlot(uint64(firstSlot))
+	if err != nil {
+		return fmt.Errorf("error getting block number for slot: %v err: %w", firstSlot, err)
+	}
+
+	lastBlock, err := getEth1BlockNumberForSlot(lastSlot)
+	if err != nil {
+		return fmt.Errorf("error getting block number for slot: %v err: %w", lastSlot, err)
+	}
+
+	// EIP-1559 specific indicators are only exported for days after the merge
+	preMerge := firstBlock < utils.Config.Chain.MergeBlock
+	logger.Infof("exporting chart_series for day %v ts: %v (slot %v to %v, block %v to %v, pre merge: %v)", day, dateTrunc, firstSlot, lastSlot, firstBlock, lastBlock, preMerge)
+
+	blocksChan := make(chan *types.Eth1Block, 360)
+	batchSize := int64(360)
+	go func(stream chan *types.Eth1Block) {
+		logger.Infof("querying blocks from %v to %v", firstBlock, lastBlock)
 		for b := int64(lastBlock) - 1; b > int64(firstBlock); b -= batchSize {
 			high := b
 			low := b - batchSize + 1
@@ -1315,266 +5718,1276 @@ func WriteChartSeriesForDay(day int64) error {
 				low = int64(firstBlock)
 			}
 
-			err := BigtableClient.GetFullBlocksDescending(stream, uint64(high), uint64(low))
+			err := BigtableClient.GetFullBlocksDescending(stream, uint64(high), uint64(low))
+			if err != nil {
+				logger.Errorf("error getting blocks descending high: %v low: %v err: %v", high, low, err)
+			}
+
+		}
+		close(stream)
+	}(blocksChan)
+
+	// logger.Infof("got %v blocks", len(blocks))
+
+	blockCount := int64(0)
+	txCount := int64(0)
+
+	totalBaseFee := decimal.NewFromInt(0)
+	totalGasPrice := decimal.NewFromInt(0)
+	totalTxSavings := decimal.NewFromInt(0)
+	totalTxFees := decimal.NewFromInt(0)
+	txFees := newTxFeeQuantiles()
+	// effective gas prices of all transaction types
+	gasPrices := newTxFeeQuantiles()
+	totalBurned := decimal.NewFromInt(0)
+	totalGasUsed := decimal.NewFromInt(0)
+
+	legacyTxCount := int64(0)
+	accessListTxCount := int64(0)
+	eip1559TxCount := int64(0)
+	failedTxCount := int64(0)
+	successTxCount := int64(0)
+
+	totalFailedGasUsed := decimal.NewFromInt(0)
+	totalFailedTxFee := decimal.NewFromInt(0)
+
+	totalBaseBlockReward := decimal.NewFromInt(0)
+
+	totalGasLimit := decimal.NewFromInt(0)
+	totalTips := decimal.NewFromInt(0)
+
+	// totalSize := decimal.NewFromInt(0)
+
+	// blockCount := len(blocks)
+
+	// missedBlockCount := (firstSlot - uint64(lastSlot)) - uint64(blockCount)
+
+	var prevBlock *types.Eth1Block
+
+	accumulatedBlockTime := decimal.NewFromInt(0)
+
+	for blk := range blocksChan {
+		// logger.Infof("analyzing block: %v with: %v transactions", blk.Number, len(blk.Transactions))
+		blockCount += 1
+		baseFee := decimal.NewFromBigInt(new(big.Int).SetBytes(blk.BaseFee), 0)
+		totalBaseFee = totalBaseFee.Add(baseFee)
+		totalGasLimit = totalGasLimit.Add(decimal.NewFromInt(int64(blk.GasLimit)))
+
+		if prevBlock != nil {
+			accumulatedBlockTime = accumulatedBlockTime.Add(decimal.NewFromInt(prevBlock.Time.AsTime().UnixMicro() - blk.Time.AsTime().UnixMicro()))
+		}
+
+		totalBaseBlockReward = totalBaseBlockReward.Add(decimal.NewFromBigInt(utils.Eth1BlockReward(blk.Number, blk.Difficulty), 0))
+		totalBaseBlockReward = totalBaseBlockReward.Add(decimal.NewFromBigInt(utils.Eth1UncleRewards(blk), 0))
+
+		for _, tx := range blk.Transactions {
+			// for _, itx := range tx.Itx {
+			// }
+			// blk.Time
+			txCount += 1
+			maxFee := decimal.NewFromBigInt(new(big.Int).SetBytes(tx.MaxFeePerGas), 0)
+			prioFee := decimal.NewFromBigInt(new(big.Int).SetBytes(tx.MaxPriorityFeePerGas), 0)
+			gasUsed := decimal.NewFromBigInt(new(big.Int).SetUint64(tx.GasUsed), 0)
+			gasPrice := decimal.NewFromBigInt(new(big.Int).SetBytes(tx.GasPrice), 0)
+
+			var tipFee decimal.Decimal
+			var txFee decimal.Decimal
+			switch tx.Type {
+			case 0:
+				legacyTxCount += 1
+				totalGasPrice = totalGasPrice.Add(gasPrice)
+				txFee = gasUsed.Mul(gasPrice)
+				tipFee = gasPrice.Sub(baseFee)
+
+			case 1:
+				accessListTxCount += 1
+				totalGasPrice = totalGasPrice.Add(gasPrice)
+				txFee = gasUsed.Mul(gasPrice)
+				tipFee = gasPrice.Sub(baseFee)
+
+			case 2:
+				// priority fee is capped because the base fee is filled first
+				tipFee = decimal.Min(prioFee, maxFee.Sub(baseFee))
+				eip1559TxCount += 1
+				// totalMinerTips = totalMinerTips.Add(tipFee.Mul(gasUsed))
+				txFee = baseFee.Mul(gasUsed).Add(tipFee.Mul(gasUsed))
+				totalTxSavings = totalTxSavings.Add(maxFee.Mul(gasUsed).Sub(baseFee.Mul(gasUsed).Add(tipFee.Mul(gasUsed))))
+
+			default:
+				logger.Fatalf("error unknown tx type %v hash: %x", tx.Status, tx.Hash)
+			}
+			totalTxFees = totalTxFees.Add(txFee)
+			txFees.add(txFee)
+			gasPrices.add(effectiveGasPrice(tx.Type, gasPrice, baseFee, maxFee, prioFee))
+
+			switch tx.Status {
+			case 0:
+				failedTxCount += 1
+				totalFailedGasUsed = totalFailedGasUsed.Add(gasUsed)
+				totalFailedTxFee = totalFailedTxFee.Add(txFee)
+			case 1:
+				successTxCount += 1
+			default:
+				logger.Fatalf("error unknown status code %v hash: %x", tx.Status, tx.Hash)
+			}
+			totalGasUsed = totalGasUsed.Add(gasUsed)
+			totalBurned = totalBurned.Add(baseFee.Mul(gasUsed))
+			if blk.Number < 12244000 {
+				totalTips = totalTips.Add(gasUsed.Mul(gasPrice))
+			} else {
+				totalTips = totalTips.Add(gasUsed.Mul(tipFee))
+			}
+		}
+		prevBlock = blk
+	}
+
+	avgBlockTime := accumulatedBlockTime.Div(decimal.NewFromInt(blockCount - 1))
+
+	logger.Infof("exporting consensus rewards from %v to %v", firstEpoch, lastEpoch)
+
+	// consensus rewards are in Gwei
+	totalConsensusRewards := int64(0)
+
+	excludedCondition, excludedArgs := excludedValidatorsCondition(utils.Config.Statistics.ChartExcludedValidators, 2)
+	if len(excludedArgs) > 0 {
+		logger.Infof("excluding %v validators from the network chart aggregates", len(utils.Config.Statistics.ChartExcludedValidators))
+	}
+
+	err = WriterDb.Get(&totalConsensusRewards, "SELECT SUM(COALESCE(cl_rewards_gwei, 0)) FROM validator_stats WHERE day = $1"+excludedCondition, append([]interface{}{day}, excludedArgs...)...)
+	if err != nil {
+		return fmt.Errorf("error calculating totalConsensusRewards: %w", err)
+	}
+	logger.Infof("consensus rewards: %v", totalConsensusRewards)
+
+	// effective balances are in Gwei, only the validators active at the end of the day count like for TOTAL_STAKED
+	totalActiveEffectiveBalance := int64(0)
+
+	err = WriterDb.Get(&totalActiveEffectiveBalance, "SELECT COALESCE(SUM(end_effective_balance), 0) FROM validator_stats WHERE day = $1"+excludedCondition+activeValidatorsCondition(len(excludedArgs)+2),
+		append(append([]interface{}{day}, excludedArgs...), lastEpoch)...)
+	if err != nil {
+		return fmt.Errorf("error calculating totalActiveEffectiveBalance: %w", err)
+	}
+
+	if !preMerge {
+		logger.Infof("Exporting BURNED_FEES %v", totalBurned.String())
+		_, err = WriterDb.Exec("INSERT INTO chart_series (time, indicator, value) VALUES ($1, 'BURNED_FEES', $2) ON CONFLICT (time, indicator) DO UPDATE SET value = EXCLUDED.value", dateTrunc, totalBurned.String())
+		if err != nil {
+			return fmt.Errorf("error calculating BURNED_FEES chart_series: %w", err)
+		}
+	}
+
+	logger.Infof("Exporting NON_FAILED_TX_GAS_USAGE %v", totalGasUsed.Sub(totalFailedGasUsed).String())
+	err = SaveChartSeriesPoint(dateTrunc, "NON_FAILED_TX_GAS_USAGE", totalGasUsed.Sub(totalFailedGasUsed).String())
+	if err != nil {
+		return fmt.Errorf("error calculating NON_FAILED_TX_GAS_USAGE chart_series: %w", err)
+	}
+	logger.Infof("Exporting BLOCK_COUNT %v", blockCount)
+	err = SaveChartSeriesPoint(dateTrunc, "BLOCK_COUNT", blockCount)
+	if err != nil {
+		return fmt.Errorf("error calculating BLOCK_COUNT chart_series: %w", err)
+	}
+
+	// convert microseconds to seconds
+	logger.Infof("Exporting BLOCK_TIME_AVG %v", avgBlockTime.Div(decimal.NewFromInt(1e6)).Abs().String())
+	err = SaveChartSeriesPoint(dateTrunc, "BLOCK_TIME_AVG", avgBlockTime.Div(decimal.NewFromInt(1e6)).String())
+	if err != nil {
+		return fmt.Errorf("error calculating BLOCK_TIME_AVG chart_series: %w", err)
+	}
+	// convert consensus rewards to gwei
+	emission := (totalBaseBlockReward.Add(decimal.NewFromInt(totalConsensusRewards).Mul(decimal.NewFromInt(1000000000))).Add(totalTips)).Sub(totalBurned)
+	logger.Infof("Exporting TOTAL_EMISSION %v day emission", emission)
+
+	newEmission, err := runningChartSeriesTotal("TOTAL_EMISSION", dateTrunc, emission)
+	if err != nil {
+		return err
+	}
+	err = SaveChartSeriesPoint(dateTrunc, "TOTAL_EMISSION", newEmission)
+	if err != nil {
+		return fmt.Errorf("error calculating TOTAL_EMISSION chart_series: %w", err)
+	}
+
+	clIssuance := clIssuanceEth(totalConsensusRewards)
+	logger.Infof("Exporting CL_ISSUANCE %v", clIssuance.String())
+	err = SaveChartSeriesPoint(dateTrunc, "CL_ISSUANCE", clIssuance.String())
+	if err != nil {
+		return fmt.Errorf("error calculating CL_ISSUANCE chart_series: %w", err)
+	}
+
+	netIssuance := netIssuanceEth(totalConsensusRewards, totalBurned)
+	logger.Infof("Exporting NET_ISSUANCE %v", netIssuance.String())
+	err = SaveChartSeriesPoint(dateTrunc, "NET_ISSUANCE", netIssuance.String())
+	if err != nil {
+		return fmt.Errorf("error calculating NET_ISSUANCE chart_series: %w", err)
+	}
+
+	if totalActiveEffectiveBalance > 0 {
+		stakingApr := stakingAPR(totalConsensusRewards, totalActiveEffectiveBalance)
+		logger.Infof("Exporting STAKING_APR %v", stakingApr.String())
+		err = SaveChartSeriesPoint(dateTrunc, "STAKING_APR", stakingApr.String())
+		if err != nil {
+			return fmt.Errorf("error calculating STAKING_APR chart_series: %w", err)
+		}
+	} else {
+		logger.Warnf("skipping STAKING_APR chart_series export, no active effective balance for day %v", day)
+	}
+
+	if lastEpoch > utils.Config.Chain.Config.CappellaForkEpoch {
+		err = writeWithdrawalChartSeries(dateTrunc, day, excludedCondition, excludedArgs)
+		if err != nil {
+			return err
+		}
+	} else {
+		logger.Infof("skipping withdrawal chart_series export, day %v is before capella", day)
+	}
+
+	err = writeStakingChartSeries(dateTrunc, day, lastEpoch, excludedCondition, excludedArgs)
+	if err != nil {
+		return err
+	}
+
+	if totalGasPrice.GreaterThan(decimal.NewFromInt(0)) && decimal.NewFromInt(legacyTxCount).Add(decimal.NewFromInt(accessListTxCount)).GreaterThan(decimal.NewFromInt(0)) {
+		logger.Infof("Exporting AVG_GASPRICE")
+		_, err = WriterDb.Exec("INSERT INTO chart_series (time, indicator, value) VALUES($1, 'AVG_GASPRICE', $2) ON CONFLICT (time, indicator) DO UPDATE SET value = EXCLUDED.value", dateTrunc, totalGasPrice.Div((decimal.NewFromInt(legacyTxCount).Add(decimal.NewFromInt(accessListTxCount)))).String())
+		if err != nil {
+			return fmt.Errorf("error calculating AVG_GASPRICE chart_series err: %w", err)
+		}
+	}
+
+	if txCount > 0 {
+		logger.Infof("Exporting AVG_GASUSED %v", totalGasUsed.Div(decimal.NewFromInt(blockCount)).String())
+		err = SaveChartSeriesPoint(dateTrunc, "AVG_GASUSED", totalGasUsed.Div(decimal.NewFromInt(blockCount)).String())
+		if err != nil {
+			return fmt.Errorf("error calculating AVG_GASUSED chart_series: %w", err)
+		}
+	}
+
+	if avgTxFee, ok := averageTxFee(totalTxFees, txCount); ok {
+		logger.Infof("Exporting AVG_TX_FEE %v", avgTxFee.String())
+		err = SaveChartSeriesPoint(dateTrunc, "AVG_TX_FEE", avgTxFee.String())
+		if err != nil {
+			return fmt.Errorf("error calculating AVG_TX_FEE chart_series: %w", err)
+		}
+
+		medianTxFee := txFees.quantile(0.5)
+		logger.Infof("Exporting MEDIAN_TX_FEE %v", medianTxFee.String())
+		err = SaveChartSeriesPoint(dateTrunc, "MEDIAN_TX_FEE", medianTxFee.String())
+		if err != nil {
+			return fmt.Errorf("error calculating MEDIAN_TX_FEE chart_series: %w", err)
+		}
+
+		for _, percentile := range []int{10, 50, 90} {
+			indicator := fmt.Sprintf("GASPRICE_P%d", percentile)
+			gasPrice := gasPrices.quantile(float64(percentile) / 100)
+			logger.Infof("Exporting %v %v", indicator, gasPrice.String())
+			err = SaveChartSeriesPoint(dateTrunc, indicator, gasPrice.String())
 			if err != nil {
-				logger.Errorf("error getting blocks descending high: %v low: %v err: %v", high, low, err)
+				return fmt.Errorf("error calculating %v chart_series: %w", indicator, err)
 			}
+		}
+	}
+
+	logger.Infof("Exporting TOTAL_GASUSED %v", totalGasUsed.String())
+	err = SaveChartSeriesPoint(dateTrunc, "TOTAL_GASUSED", totalGasUsed.String())
+	if err != nil {
+		return fmt.Errorf("error calculating TOTAL_GASUSED chart_series: %w", err)
+	}
 
+	if blockCount > 0 {
+		logger.Infof("Exporting AVG_GASLIMIT %v", totalGasLimit.Div(decimal.NewFromInt(blockCount)))
+		err = SaveChartSeriesPoint(dateTrunc, "AVG_GASLIMIT", totalGasLimit.Div(decimal.NewFromInt(blockCount)))
+		if err != nil {
+			return fmt.Errorf("error calculating AVG_GASLIMIT chart_series: %w", err)
 		}
-		close(stream)
-	}(blocksChan)
+	}
 
-	// logger.Infof("got %v blocks", len(blocks))
+	if !totalGasLimit.IsZero() {
+		logger.Infof("Exporting AVG_BLOCK_UTIL %v", totalGasUsed.Div(totalGasLimit).Mul(decimal.NewFromInt(100)))
+		err = SaveChartSeriesPoint(dateTrunc, "AVG_BLOCK_UTIL", totalGasUsed.Div(totalGasLimit).Mul(decimal.NewFromInt(100)))
+		if err != nil {
+			return fmt.Errorf("error calculating AVG_BLOCK_UTIL chart_series: %w", err)
+		}
+	}
 
-	blockCount := int64(0)
-	txCount := int64(0)
+	currency := utils.Config.Statistics.MarketCapCurrency
+	ethPrice, err := marketCapEthPrice(currency, dateTrunc, price.GetEthPrice)
+	if errors.Is(err, ErrNoHistoricalPrice) {
+		// the market cap of the day is exported by a later run once its price has been imported
+		logger.Warnf("skipping MARKET_CAP chart_series export: %v", err)
+	} else if err != nil {
+		return fmt.Errorf("error retrieving eth price for MARKET_CAP chart_series: %w", err)
+	} else {
+		// the price is stored next to the market cap so a re-export can be compared against it
+		priceIndicator := fmt.Sprintf("ETH_PRICE_%s", strings.ToUpper(currency))
+		logger.Infof("Exporting %v: %v", priceIndicator, ethPrice)
+		err = SaveChartSeriesPoint(dateTrunc, priceIndicator, ethPrice)
+		if err != nil {
+			return fmt.Errorf("error calculating %v chart_series: %w", priceIndicator, err)
+		}
 
-	totalBaseFee := decimal.NewFromInt(0)
-	totalGasPrice := decimal.NewFromInt(0)
-	totalTxSavings := decimal.NewFromInt(0)
-	totalTxFees := decimal.NewFromInt(0)
-	totalBurned := decimal.NewFromInt(0)
-	totalGasUsed := decimal.NewFromInt(0)
+		marketCap := marketCapForEmission(newEmission, ethPrice)
+		logger.Infof("Exporting MARKET_CAP: %v", marketCap.String())
+		err = SaveChartSeriesPoint(dateTrunc, "MARKET_CAP", marketCap.String())
+		if err != nil {
+			return fmt.Errorf("error calculating MARKET_CAP chart_series: %w", err)
+		}
+	}
 
-	legacyTxCount := int64(0)
-	accessListTxCount := int64(0)
-	eip1559TxCount := int64(0)
-	failedTxCount := int64(0)
-	successTxCount := int64(0)
+	logger.Infof("Exporting TX_COUNT %v", txCount)
+	err = SaveChartSeriesPoint(dateTrunc, "TX_COUNT", txCount)
+	if err != nil {
+		return fmt.Errorf("error calculating TX_COUNT chart_series: %w", err)
+	}
 
-	totalFailedGasUsed := decimal.NewFromInt(0)
-	totalFailedTxFee := decimal.NewFromInt(0)
+	// Not sure how this is currently possible (where do we store the size, i think this is missing)
+	// logger.Infof("Exporting AVG_SIZE %v", totalSize.div)
+	// err = SaveChartSeriesPoint(dateTrunc, "AVG_SIZE", totalSize.div)
+	// if err != nil {
+	// 	return fmt.Errorf("error calculating AVG_SIZE chart_series: %w", err)
+	// }
 
-	totalBaseBlockReward := decimal.NewFromInt(0)
+	// logger.Infof("Exporting POWER_CONSUMPTION %v", avgBlockTime.String())
+	// err = SaveChartSeriesPoint(dateTrunc, "POWER_CONSUMPTION", avgBlockTime.String())
+	// if err != nil {
+	// 	return fmt.Errorf("error calculating POWER_CONSUMPTION chart_series: %w", err)
+	// }
 
-	totalGasLimit := decimal.NewFromInt(0)
-	totalTips := decimal.NewFromInt(0)
+	// logger.Infof("Exporting NEW_ACCOUNTS %v", avgBlockTime.String())
+	// err = SaveChartSeriesPoint(dateTrunc, "NEW_ACCOUNTS", avgBlockTime.String())
+	// if err != nil {
+	// 	return fmt.Errorf("error calculating NEW_ACCOUNTS chart_series: %w", err)
+	// }
 
-	// totalSize := decimal.NewFromInt(0)
+	logger.Infof("marking day export as completed in the status table")
+	_, err = WriterDb.Exec("insert into chart_series_status (day, status) values ($1, true)", day)
+	if err != nil {
+		return err
+	}
 
-	// blockCount := len(blocks)
+	logger.Infof("chart_series export completed: took %v", time.Since(startTs))
 
-	// missedBlockCount := (firstSlot - uint64(lastSlot)) - uint64(blockCount)
+	return nil
+}
 
-	var prevBlock *types.Eth1Block
+// writeWithdrawalChartSeries stores the number of withdrawals of the day as WITHDRAWALS_COUNT and the running total of the withdrawn
+// amount (in ETH) as TOTAL_WITHDRAWN. Both are taken from the withdrawals columns of the validator_stats of the day.
+func writeWithdrawalChartSeries(dateTrunc time.Time, day int64, excludedCondition string, excludedArgs []interface{}) error {
+	withdrawals := struct {
+		Count  int64 `db:"withdrawals"`
+		Amount int64 `db:"withdrawals_amount"`
+	}{}
+	err := WriterDb.Get(&withdrawals, "SELECT COALESCE(SUM(withdrawals), 0) AS withdrawals, COALESCE(SUM(withdrawals_amount), 0) AS withdrawals_amount FROM validator_stats WHERE day = $1"+excludedCondition, append([]interface{}{day}, excludedArgs...)...)
+	if err != nil {
+		return fmt.Errorf("error calculating withdrawals of day %v: %w", day, err)
+	}
 
-	accumulatedBlockTime := decimal.NewFromInt(0)
+	logger.Infof("Exporting WITHDRAWALS_COUNT %v", withdrawals.Count)
+	err = SaveChartSeriesPoint(dateTrunc, "WITHDRAWALS_COUNT", withdrawals.Count)
+	if err != nil {
+		return fmt.Errorf("error calculating WITHDRAWALS_COUNT chart_series: %w", err)
+	}
 
-	for blk := range blocksChan {
-		// logger.Infof("analyzing block: %v with: %v transactions", blk.Number, len(blk.Transactions))
-		blockCount += 1
-		baseFee := decimal.NewFromBigInt(new(big.Int).SetBytes(blk.BaseFee), 0)
-		totalBaseFee = totalBaseFee.Add(baseFee)
-		totalGasLimit = totalGasLimit.Add(decimal.NewFromInt(int64(blk.GasLimit)))
+	totalWithdrawn, err := runningChartSeriesTotal("TOTAL_WITHDRAWN", dateTrunc, decimal.NewFromInt(withdrawals.Amount).Div(decimal.NewFromInt(int64(utils.ClCurrencyDivisor()))))
+	if err != nil {
+		return err
+	}
+	logger.Infof("Exporting TOTAL_WITHDRAWN %v", totalWithdrawn.String())
+	err = SaveChartSeriesPoint(dateTrunc, "TOTAL_WITHDRAWN", totalWithdrawn.String())
+	if err != nil {
+		return fmt.Errorf("error calculating TOTAL_WITHDRAWN chart_series: %w", err)
+	}
+	return nil
+}
 
-		if prevBlock != nil {
-			accumulatedBlockTime = accumulatedBlockTime.Add(decimal.NewFromInt(prevBlock.Time.AsTime().UnixMicro() - blk.Time.AsTime().UnixMicro()))
+// writeStakingChartSeries stores the number of validators that are active at the end of the day as ACTIVE_VALIDATORS and the sum of
+// their effective balances (in ETH) as TOTAL_STAKED. A validator is active if it was activated and has not exited by the last epoch
+// of the day, pending and exited validators still have an effective balance in the validator_stats of the day.
+func writeStakingChartSeries(dateTrunc time.Time, day int64, lastEpoch uint64, excludedCondition string, excludedArgs []interface{}) error {
+	staking := struct {
+		ActiveValidators int64 `db:"active_validators"`
+		TotalStaked      int64 `db:"total_staked"`
+	}{}
+	err := WriterDb.Get(&staking, fmt.Sprintf(`
+		SELECT COUNT(*) AS active_validators, COALESCE(SUM(end_effective_balance), 0) AS total_staked
+		FROM validator_stats
+		WHERE day = $1%s%s`, excludedCondition, activeValidatorsCondition(len(excludedArgs)+2)),
+		append(append([]interface{}{day}, excludedArgs...), lastEpoch)...)
+	if err != nil {
+		return fmt.Errorf("error calculating active validators of day %v: %w", day, err)
+	}
+
+	logger.Infof("Exporting ACTIVE_VALIDATORS %v", staking.ActiveValidators)
+	err = SaveChartSeriesPoint(dateTrunc, "ACTIVE_VALIDATORS", staking.ActiveValidators)
+	if err != nil {
+		return fmt.Errorf("error calculating ACTIVE_VALIDATORS chart_series: %w", err)
+	}
+
+	totalStaked := decimal.NewFromInt(staking.TotalStaked).Div(decimal.NewFromInt(int64(utils.ClCurrencyDivisor())))
+	logger.Infof("Exporting TOTAL_STAKED %v", totalStaked.String())
+	err = SaveChartSeriesPoint(dateTrunc, "TOTAL_STAKED", totalStaked.String())
+	if err != nil {
+		return fmt.Errorf("error calculating TOTAL_STAKED chart_series: %w", err)
+	}
+	return nil
+}
+
+// activeValidatorsCondition restricts a validator_stats query to the validators that have been activated and not exited by the epoch
+// bound to the parameter epochArg
+func activeValidatorsCondition(epochArg int) string {
+	return fmt.Sprintf(" AND validatorindex IN (SELECT validatorindex FROM validators WHERE activationepoch <= $%[1]d AND exitepoch > $%[1]d)", epochArg)
+}
+
+// runningChartSeriesTotal adds the value of the day to the latest value of the indicator before ts. The first day of a series (e.g. the
+// first exported day or the first day after the fork introducing it) has no previous value and starts with the value of the day. Days
+// following a gap continue from the latest value before the gap.
+func runningChartSeriesTotal(indicator string, ts time.Time, dayValue decimal.Decimal) (decimal.Decimal, error) {
+	var previous decimal.Decimal
+	err := ReaderDb.Get(&previous, "SELECT value FROM chart_series WHERE indicator = $1 AND time < $2 ORDER BY time DESC LIMIT 1", indicator, ts)
+	if err == sql.ErrNoRows {
+		logger.Warnf("no previous value for %v chart_series before %v, starting the total with the value of the day", indicator, ts)
+		return dayValue, nil
+	}
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("error getting previous value for %v chart_series: %w", indicator, err)
+	}
+	return previous.Add(dayValue), nil
+}
+
+// ErrUnknownChartSeriesIndicator is returned when a chart series is requested for an indicator that is not exported
+var ErrUnknownChartSeriesIndicator = errors.New("unknown chart series indicator")
+
+// chartSeriesIndicators are the indicators written by WriteChartSeriesForDay, the ETH_PRICE_<CURRENCY> indicators are matched by prefix
+var chartSeriesIndicators = map[string]bool{
+	"BURNED_FEES":             true,
+	"NON_FAILED_TX_GAS_USAGE": true,
+	"BLOCK_COUNT":             true,
+	"BLOCK_TIME_AVG":          true,
+	"TOTAL_EMISSION":          true,
+	"CL_ISSUANCE":             true,
+	"NET_ISSUANCE":            true,
+	"STAKING_APR":             true,
+	"WITHDRAWALS_COUNT":       true,
+	"TOTAL_WITHDRAWN":         true,
+	"ACTIVE_VALIDATORS":       true,
+	"TOTAL_STAKED":            true,
+	"AVG_GASPRICE":            true,
+	"AVG_GASUSED":             true,
+	"AVG_TX_FEE":              true,
+	"MEDIAN_TX_FEE":           true,
+	"GASPRICE_P10":            true,
+	"GASPRICE_P50":            true,
+	"GASPRICE_P90":            true,
+	"TOTAL_GASUSED":           true,
+	"AVG_GASLIMIT":            true,
+	"AVG_BLOCK_UTIL":          true,
+	"MARKET_CAP":              true,
+	"TX_COUNT":                true,
+}
+
+func isChartSeriesIndicator(indicator string) bool {
+	return chartSeriesIndicators[indicator] || (strings.HasPrefix(indicator, "ETH_PRICE_") && len(indicator) > len("ETH_PRICE_"))
+}
+
+// GetChartSeries returns the values of a chart_series indicator between from and to (inclusive), ordered by time. X is the
+// time of a value in milliseconds. The result is cached for an hour as the series only get a new value per day.
+func GetChartSeries(indicator string, from, to time.Time) ([]types.ChartDataPoint, error) {
+	if !isChartSeriesIndicator(indicator) {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownChartSeriesIndicator, indicator)
+	}
+
+	cacheDur := time.Hour
+	cacheKey := fmt.Sprintf("%d:chartSeries:%s:%d:%d", utils.Config.Chain.Config.DepositChainID, indicator, from.Unix(), to.Unix())
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, cacheDur, []types.ChartDataPoint{}); err == nil {
+		return cached.([]types.ChartDataPoint), nil
+	}
+
+	rows := []struct {
+		Time  time.Time `db:"time"`
+		Value float64   `db:"value"`
+	}{}
+	err := ReaderDb.Select(&rows, "SELECT time, value FROM chart_series WHERE indicator = $1 AND time >= $2 AND time <= $3 ORDER BY time", indicator, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving %v chart_series from %v to %v: %w", indicator, from, to, err)
+	}
+
+	series := make([]types.ChartDataPoint, len(rows))
+	for i, row := range rows {
+		series[i] = types.ChartDataPoint{X: float64(row.Time.Unix() * 1000), Y: row.Value}
+	}
+
+	go func(series []types.ChartDataPoint) {
+		err := cache.TieredCache.Set(cacheKey, series, cacheDur)
+		if err != nil {
+			utils.LogError(err, fmt.Errorf("error setting tieredCache for GetChartSeries with key %v", cacheKey), 0)
 		}
+	}(series)
 
-		totalBaseBlockReward = totalBaseBlockReward.Add(decimal.NewFromBigInt(utils.Eth1BlockReward(blk.Number, blk.Difficulty), 0))
+	return series, nil
+}
 
-		for _, tx := range blk.Transactions {
-			// for _, itx := range tx.Itx {
-			// }
-			// blk.Time
-			txCount += 1
-			maxFee := decimal.NewFromBigInt(new(big.Int).SetBytes(tx.MaxFeePerGas), 0)
-			prioFee := decimal.NewFromBigInt(new(big.Int).SetBytes(tx.MaxPriorityFeePerGas), 0)
-			gasUsed := decimal.NewFromBigInt(new(big.Int).SetUint64(tx.GasUsed), 0)
-			gasPrice := decimal.NewFromBigInt(new(big.Int).SetBytes(tx.GasPrice), 0)
+// getEth1BlockNumberForSlot returns the execution block number of the slot. Slots before the merge have no execution
+// payload, for those the first eth1 block mined at or after the slot time is looked up instead.
+func getEth1BlockNumberForSlot(slot uint64) (uint64, error) {
+	block, err := GetBlockNumber(slot)
+	if err == nil && block > 0 {
+		return block, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("no execution block found for slot %v", slot)
+	}
+	if utils.Config.Chain.MergeBlock == 0 {
+		return 0, err
+	}
 
-			var tipFee decimal.Decimal
-			var txFees decimal.Decimal
-			switch tx.Type {
-			case 0:
-				legacyTxCount += 1
-				totalGasPrice = totalGasPrice.Add(gasPrice)
-				txFees = gasUsed.Mul(gasPrice)
-				tipFee = gasPrice.Sub(baseFee)
+	preMergeBlock, searchErr := findEth1BlockByTime(utils.SlotToTime(slot), utils.Config.Chain.MergeBlock)
+	if searchErr != nil {
+		return 0, searchErr
+	}
+	if preMergeBlock >= utils.Config.Chain.MergeBlock {
+		// the slot is after the merge, so its missing execution block can't be explained by it
+		return 0, err
+	}
+	return preMergeBlock, nil
+}
 
-			case 1:
-				accessListTxCount += 1
-				totalGasPrice = totalGasPrice.Add(gasPrice)
-				txFees = gasUsed.Mul(gasPrice)
-				tipFee = gasPrice.Sub(baseFee)
+// findEth1BlockByTime returns the first block below high that has been mined at or after ts using a binary search
+func findEth1BlockByTime(ts time.Time, high uint64) (uint64, error) {
+	low := uint64(0)
+	for low < high {
+		mid := low + (high-low)/2
+		block, err := BigtableClient.GetBlockFromBlocksTable(mid)
+		if err != nil {
+			return 0, fmt.Errorf("error getting block %v: %w", mid, err)
+		}
+		if block.Time.AsTime().Before(ts) {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	return low, nil
+}
 
-			case 2:
-				// priority fee is capped because the base fee is filled first
-				tipFee = decimal.Min(prioFee, maxFee.Sub(baseFee))
-				eip1559TxCount += 1
-				// totalMinerTips = totalMinerTips.Add(tipFee.Mul(gasUsed))
-				txFees = baseFee.Mul(gasUsed).Add(tipFee.Mul(gasUsed))
-				totalTxSavings = totalTxSavings.Add(maxFee.Mul(gasUsed).Sub(baseFee.Mul(gasUsed).Add(tipFee.Mul(gasUsed))))
+// ErrNoHistoricalPrice is returned by marketCapEthPrice for a day whose price has not been imported yet
+var ErrNoHistoricalPrice = errors.New("no historical price")
 
-			default:
-				logger.Fatalf("error unknown tx type %v hash: %x", tx.Status, tx.Hash)
-			}
-			totalTxFees = totalTxFees.Add(txFees)
+// marketCapEthPrice returns the eth price of the given date used for the market cap, read from the daily historical prices so
+// re-exporting a day yields the same market cap. Testnets have no historical prices and use the current price instead, on mainnet
+// ErrNoHistoricalPrice is returned for days whose price has not been imported yet.
+func marketCapEthPrice(currency string, date time.Time, currentPrice func(currency string) float64) (float64, error) {
+	if !utils.SliceContains(price.GetAvailableCurrencies(), strings.ToUpper(currency)) {
+		return 0, fmt.Errorf("currency %v not supported", currency)
+	}
+	if strings.ToUpper(currency) == "ETH" {
+		return 1, nil
+	}
+	if utils.Config.Chain.Config.DepositChainID != 1 {
+		return currentPrice(currency), nil
+	}
 
-			switch tx.Status {
-			case 0:
-				failedTxCount += 1
-				totalFailedGasUsed = totalFailedGasUsed.Add(gasUsed)
-				totalFailedTxFee = totalFailedTxFee.Add(txFees)
-			case 1:
-				successTxCount += 1
-			default:
-				logger.Fatalf("error unknown status code %v hash: %x", tx.Status, tx.Hash)
-			}
-			totalGasUsed = totalGasUsed.Add(gasUsed)
-			totalBurned = totalBurned.Add(baseFee.Mul(gasUsed))
-			if blk.Number < 12244000 {
-				totalTips = totalTips.Add(gasUsed.Mul(gasPrice))
-			} else {
-				totalTips = totalTips.Add(gasUsed.Mul(tipFee))
+	var historicalPrice float64
+	err := ReaderDb.Get(&historicalPrice, fmt.Sprintf("SELECT %s FROM price WHERE ts = $1", strings.ToLower(currency)), date)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %v price for %v", ErrNoHistoricalPrice, currency, date.Format("2006-01-02"))
+	} else if err != nil {
+		return 0, fmt.Errorf("error getting historical %v price for %v: %w", currency, date.Format("2006-01-02"), err)
+	}
+	return historicalPrice, nil
+}
+
+// marketCapForEmission returns the market cap for the genesis supply of the chain plus the given emission (in wei) at the given eth price
+func marketCapForEmission(emission decimal.Decimal, ethPrice float64) decimal.Decimal {
+	return emission.Div(decimal.NewFromInt(1e18)).Add(decimal.NewFromFloat(utils.GenesisSupply())).Mul(decimal.NewFromFloat(ethPrice))
+}
+
+// averageTxFee returns the average fee (in wei) of the transactions of a day, ok is false for a day without transactions
+func averageTxFee(totalTxFees decimal.Decimal, txCount int64) (decimal.Decimal, bool) {
+	if txCount == 0 {
+		return decimal.Zero, false
+	}
+	return totalTxFees.Div(decimal.NewFromInt(txCount)), true
+}
+
+// txFeeBucketGrowth is the factor between the lower bounds of two consecutive buckets of txFeeQuantiles
+const txFeeBucketGrowth = 1.09
+
+// effectiveGasPrice returns the price per gas a transaction actually paid: the gas price of legacy and access list transactions and
+// the base fee plus the priority fee of EIP-1559 transactions, which is capped by their max fee
+func effectiveGasPrice(txType uint32, gasPrice, baseFee, maxFee, prioFee decimal.Decimal) decimal.Decimal {
+	if txType == 2 {
+		return baseFee.Add(decimal.Min(prioFee, maxFee.Sub(baseFee)))
+	}
+	return gasPrice
+}
+
+// txFeeQuantiles approximates quantiles of the transaction fees (or gas prices) of a day without holding every fee in memory. The fees are counted
+// in logarithmic buckets, a quantile is reported as the geometric center of its bucket which bounds the relative error to about 4.5%.
+type txFeeQuantiles struct {
+	buckets map[int]int64
+	count   int64
+}
+
+func newTxFeeQuantiles() *txFeeQuantiles {
+	return &txFeeQuantiles{buckets: make(map[int]int64)}
+}
+
+// zeroTxFeeBucket holds fees of zero, which have no logarithm
+const zeroTxFeeBucket = math.MinInt32
+
+func (q *txFeeQuantiles) add(fee decimal.Decimal) {
+	bucket := zeroTxFeeBucket
+	if f, _ := fee.Float64(); f >= 1 {
+		bucket = int(math.Floor(math.Log(f) / math.Log(txFeeBucketGrowth)))
+	}
+	q.buckets[bucket]++
+	q.count++
+}
+
+// quantile returns the approximated p-quantile (0 < p <= 1) of the added fees in wei, zero if no fee has been added
+func (q *txFeeQuantiles) quantile(p float64) decimal.Decimal {
+	if q.count == 0 {
+		return decimal.Zero
+	}
+	buckets := make([]int, 0, len(q.buckets))
+	for bucket := range q.buckets {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	rank := int64(math.Ceil(p * float64(q.count)))
+	seen := int64(0)
+	for _, bucket := range buckets {
+		seen += q.buckets[bucket]
+		if seen >= rank {
+			if bucket == zeroTxFeeBucket {
+				return decimal.Zero
 			}
+			return decimal.NewFromFloat(math.Pow(txFeeBucketGrowth, float64(bucket)+0.5)).Round(0)
 		}
-		prevBlock = blk
 	}
+	return decimal.NewFromFloat(math.Pow(txFeeBucketGrowth, float64(buckets[len(buckets)-1])+0.5)).Round(0)
+}
 
-	avgBlockTime := accumulatedBlockTime.Div(decimal.NewFromInt(blockCount - 1))
+// clIssuanceEth converts the consensus rewards of a day from Gwei to ETH (or the consensus currency of the chain)
+func clIssuanceEth(dayClRewards int64) decimal.Decimal {
+	return decimal.NewFromInt(dayClRewards).Div(decimal.NewFromInt(int64(utils.ClCurrencyDivisor())))
+}
 
-	logger.Infof("exporting consensus rewards from %v to %v", firstEpoch, lastEpoch)
+// netIssuanceEth returns the consensus layer issuance of a day minus the burned fees (in wei) in ETH
+func netIssuanceEth(dayClRewards int64, burned decimal.Decimal) decimal.Decimal {
+	return clIssuanceEth(dayClRewards).Sub(burned.Div(decimal.NewFromInt(1e18)))
+}
+
+// stakingAPR returns the annualized consensus layer reward rate in percent for the rewards of a single day
+// earned on the given active effective balance, both in Gwei
+func stakingAPR(dayClRewards int64, activeEffectiveBalance int64) decimal.Decimal {
+	if activeEffectiveBalance == 0 {
+		return decimal.Zero
+	}
+	return decimal.NewFromInt(dayClRewards).Div(decimal.NewFromInt(activeEffectiveBalance)).Mul(decimal.NewFromInt(365 * 100))
+}
+
+// previewEpochRange returns the epochs of the day that are finalized so far. It fails if none of them is finalized yet.
+func previewEpochRange(day, lastFinalizedEpoch uint64) (uint64, uint64, error) {
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+	if lastFinalizedEpoch < firstEpoch {
+		return 0, 0, fmt.Errorf("no epoch of day %v finalized yet, last finalized epoch is %v", day, lastFinalizedEpoch)
+	}
+	if lastFinalizedEpoch < lastEpoch {
+		lastEpoch = lastFinalizedEpoch
+	}
+	return firstEpoch, lastEpoch, nil
+}
+
+// validatorStatsPreview holds the statistics of a validator for the finalized epochs of a day that is not completely finalized yet
+type validatorStatsPreview struct {
+	ValidatorIndex uint64
+	EndBalance     uint64
+	ClRewardsNet   int64
+}
 
-	// consensus rewards are in Gwei
-	totalConsensusRewards := int64(0)
+// WriteValidatorStatisticsPreviewForDay writes the statistics of the epochs of the day that have been finalized so far to the
+// validator_stats_preview table, so they are available before the day is exported. The status table is not touched, the
+// preview rows are deleted once the finalized statistics of the day have been exported.
+func WriteValidatorStatisticsPreviewForDay(day, lastFinalizedEpoch uint64) error {
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_stats_preview").Observe(time.Since(exportStart).Seconds())
+	}()
 
-	err = WriterDb.Get(&totalConsensusRewards, "SELECT SUM(COALESCE(cl_rewards_gwei, 0)) FROM validator_stats WHERE day = $1", day)
+	firstEpoch, lastEpoch, err := previewEpochRange(day, lastFinalizedEpoch)
 	if err != nil {
-		return fmt.Errorf("error calculating totalConsensusRewards: %w", err)
+		return err
 	}
-	logger.Infof("consensus rewards: %v", totalConsensusRewards)
 
-	logger.Infof("Exporting BURNED_FEES %v", totalBurned.String())
-	_, err = WriterDb.Exec("INSERT INTO chart_series (time, indicator, value) VALUES ($1, 'BURNED_FEES', $2) ON CONFLICT (time, indicator) DO UPDATE SET value = EXCLUDED.value", dateTrunc, totalBurned.String())
+	unlock, err := lockStatisticsExport(day, "preview")
 	if err != nil {
-		return fmt.Errorf("error calculating BURNED_FEES chart_series: %w", err)
+		return err
 	}
+	defer unlock()
 
-	logger.Infof("Exporting NON_FAILED_TX_GAS_USAGE %v", totalGasUsed.Sub(totalFailedGasUsed).String())
-	err = SaveChartSeriesPoint(dateTrunc, "NON_FAILED_TX_GAS_USAGE", totalGasUsed.Sub(totalFailedGasUsed).String())
+	logger.Infof("exporting statistics preview of day %v for epochs %v - %v", day, firstEpoch, lastEpoch)
+	var balanceStatistics map[uint64]*types.ValidatorBalanceStatistic
+	err = retryBigtable("GetValidatorBalanceStatistics", func() error {
+		var err error
+		balanceStatistics, err = BigtableClient.GetValidatorBalanceStatistics(firstEpoch, lastEpoch)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error calculating NON_FAILED_TX_GAS_USAGE chart_series: %w", err)
+		return err
 	}
-	logger.Infof("Exporting BLOCK_COUNT %v", blockCount)
-	err = SaveChartSeriesPoint(dateTrunc, "BLOCK_COUNT", blockCount)
+	var incomeStats map[uint64]*itypes.ValidatorEpochIncome
+	err = retryBigtable("GetAggregatedValidatorIncomeDetailsHistory", func() error {
+		var err error
+		incomeStats, err = BigtableClient.GetAggregatedValidatorIncomeDetailsHistory(exportedValidators(), firstEpoch, lastEpoch)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error calculating BLOCK_COUNT chart_series: %w", err)
+		return err
 	}
 
-	// convert microseconds to seconds
-	logger.Infof("Exporting BLOCK_TIME_AVG %v", avgBlockTime.Div(decimal.NewFromInt(1e6)).Abs().String())
-	err = SaveChartSeriesPoint(dateTrunc, "BLOCK_TIME_AVG", avgBlockTime.Div(decimal.NewFromInt(1e6)).String())
-	if err != nil {
-		return fmt.Errorf("error calculating BLOCK_TIME_AVG chart_series: %w", err)
+	rows := make([]validatorStatsPreview, 0, len(balanceStatistics))
+	for validator, balance := range balanceStatistics {
+		rows = append(rows, validatorStatsPreview{ValidatorIndex: validator, EndBalance: balance.EndBalance, ClRewardsNet: clRewardsNet(incomeStats[validator])})
 	}
-	// convert consensus rewards to gwei
-	emission := (totalBaseBlockReward.Add(decimal.NewFromInt(totalConsensusRewards).Mul(decimal.NewFromInt(1000000000))).Add(totalTips)).Sub(totalBurned)
-	logger.Infof("Exporting TOTAL_EMISSION %v day emission", emission)
 
-	var lastEmission float64
-	err = ReaderDb.Get(&lastEmission, "SELECT value FROM chart_series WHERE indicator = 'TOTAL_EMISSION' AND time < $1 ORDER BY time DESC LIMIT 1", dateTrunc)
+	if skipDryRunWrites("preview", day, len(rows)) {
+		return nil
+	}
+
+	if err = writeValidatorStatsPreview(day, lastEpoch, rows); err != nil {
+		return err
+	}
+
+	logger.Infof("statistics preview of day %v up to epoch %v completed, took %v", day, lastEpoch, time.Since(exportStart))
+	return nil
+}
+
+func writeValidatorStatsPreview(day, lastEpoch uint64, rows []validatorStatsPreview) error {
+	tx, err := WriterDb.Beginx()
 	if err != nil {
-		return fmt.Errorf("error getting previous value for TOTAL_EMISSION chart_series: %w", err)
+		return err
 	}
+	defer tx.Rollback()
 
-	newEmission := decimal.NewFromFloat(lastEmission).Add(emission)
-	err = SaveChartSeriesPoint(dateTrunc, "TOTAL_EMISSION", newEmission)
+	_, err = tx.Exec("DELETE FROM validator_stats_preview WHERE day = $1", day)
 	if err != nil {
-		return fmt.Errorf("error calculating TOTAL_EMISSION chart_series: %w", err)
+		return err
 	}
 
-	if totalGasPrice.GreaterThan(decimal.NewFromInt(0)) && decimal.NewFromInt(legacyTxCount).Add(decimal.NewFromInt(accessListTxCount)).GreaterThan(decimal.NewFromInt(0)) {
-		logger.Infof("Exporting AVG_GASPRICE")
-		_, err = WriterDb.Exec("INSERT INTO chart_series (time, indicator, value) VALUES($1, 'AVG_GASPRICE', $2) ON CONFLICT (time, indicator) DO UPDATE SET value = EXCLUDED.value", dateTrunc, totalGasPrice.Div((decimal.NewFromInt(legacyTxCount).Add(decimal.NewFromInt(accessListTxCount)))).String())
+	numArgs := 5
+	batchSize := 10000 // max parameters: 65535 / 5
+	for b := 0; b < len(rows); b += batchSize {
+		end := b + batchSize
+		if len(rows) < end {
+			end = len(rows)
+		}
+
+		valueStrings := make([]string, 0, end-b)
+		valueArgs := make([]interface{}, 0, (end-b)*numArgs)
+		for i, row := range rows[b:end] {
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", i*numArgs+1, i*numArgs+2, i*numArgs+3, i*numArgs+4, i*numArgs+5))
+			valueArgs = append(valueArgs, row.ValidatorIndex)
+			valueArgs = append(valueArgs, day)
+			valueArgs = append(valueArgs, lastEpoch)
+			valueArgs = append(valueArgs, row.EndBalance)
+			valueArgs = append(valueArgs, row.ClRewardsNet)
+		}
+		stmt := fmt.Sprintf(`
+			insert into validator_stats_preview (validatorindex, day, last_epoch, end_balance, cl_rewards_gwei_net) VALUES
+			%s`, strings.Join(valueStrings, ","))
+		_, err = tx.Exec(stmt, valueArgs...)
 		if err != nil {
-			return fmt.Errorf("error calculating AVG_GASPRICE chart_series err: %w", err)
+			return err
 		}
 	}
 
-	if txCount > 0 {
-		logger.Infof("Exporting AVG_GASUSED %v", totalGasUsed.Div(decimal.NewFromInt(blockCount)).String())
-		err = SaveChartSeriesPoint(dateTrunc, "AVG_GASUSED", totalGasUsed.Div(decimal.NewFromInt(blockCount)).String())
-		if err != nil {
-			return fmt.Errorf("error calculating AVG_GASUSED chart_series: %w", err)
+	return tx.Commit()
+}
+
+const validatorStatsBucketColumns = "validatorindex, bucket, first_epoch, last_epoch, min_balance, max_balance, min_effective_balance, max_effective_balance, start_balance, start_effective_balance, end_balance, end_effective_balance, participated_sync, missed_sync, orphaned_sync, sync_participation_rate, missed_attestations, orphaned_attestations, missed_source, missed_target, missed_head"
+
+// validatorStatsBucketRow combines the balance, sync duty, failed attestation and attestation component statistics of a validator for
+// an epoch range, statistics the validator has no data for are stored as NULL
+type validatorStatsBucketRow struct {
+	ValidatorIndex uint64
+	Balance        *types.ValidatorBalanceStatistic
+	Sync           *types.ValidatorSyncDutiesStatistic
+	Failed         *types.ValidatorFailedAttestationsStatistic
+	Components     *types.ValidatorAttestationComponentsStatistic
+}
+
+func (r *validatorStatsBucketRow) values(bucketKey string, firstEpoch, lastEpoch uint64) []interface{} {
+	values := []interface{}{r.ValidatorIndex, bucketKey, firstEpoch, lastEpoch}
+	if r.Balance != nil {
+		values = append(values, r.Balance.MinBalance, r.Balance.MaxBalance, r.Balance.MinEffectiveBalance, r.Balance.MaxEffectiveBalance, r.Balance.StartBalance, r.Balance.StartEffectiveBalance, r.Balance.EndBalance, r.Balance.EndEffectiveBalance)
+	} else {
+		values = append(values, nil, nil, nil, nil, nil, nil, nil, nil)
+	}
+	if r.Sync != nil {
+		values = append(values, r.Sync.ParticipatedSync, r.Sync.MissedSync, r.Sync.OrphanedSync, r.Sync.ParticipationRate())
+	} else {
+		values = append(values, nil, nil, nil, nil)
+	}
+	if r.Failed != nil {
+		values = append(values, r.Failed.MissedAttestations, r.Failed.OrphanedAttestations)
+	} else {
+		values = append(values, nil, nil)
+	}
+	if r.Components != nil {
+		values = append(values, r.Components.MissedSource, r.Components.MissedTarget, r.Components.MissedHead)
+	} else {
+		values = append(values, nil, nil, nil)
+	}
+	return values
+}
+
+// validatorStatsBucketRows merges the statistics of an epoch range by validator, ordered by validator index. Only the validators
+// matching the validator filter are kept, as the balances are read for all validators.
+func validatorStatsBucketRows(balances map[uint64]*types.ValidatorBalanceStatistic, syncStats map[uint64]*types.ValidatorSyncDutiesStatistic, failed map[uint64]*types.ValidatorFailedAttestationsStatistic, components map[uint64]*types.ValidatorAttestationComponentsStatistic) []*validatorStatsBucketRow {
+	rows := map[uint64]*validatorStatsBucketRow{}
+	row := func(validatorIndex uint64) *validatorStatsBucketRow {
+		if rows[validatorIndex] == nil {
+			rows[validatorIndex] = &validatorStatsBucketRow{ValidatorIndex: validatorIndex}
 		}
+		return rows[validatorIndex]
+	}
+	for validatorIndex, stat := range balances {
+		if isExportedValidator(validatorIndex) {
+			row(validatorIndex).Balance = stat
+		}
+	}
+	for validatorIndex, stat := range syncStats {
+		row(validatorIndex).Sync = stat
+	}
+	for validatorIndex, stat := range failed {
+		row(validatorIndex).Failed = stat
+	}
+	for validatorIndex, stat := range components {
+		row(validatorIndex).Components = stat
 	}
 
-	logger.Infof("Exporting TOTAL_GASUSED %v", totalGasUsed.String())
-	err = SaveChartSeriesPoint(dateTrunc, "TOTAL_GASUSED", totalGasUsed.String())
+	result := make([]*validatorStatsBucketRow, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ValidatorIndex < result[j].ValidatorIndex
+	})
+	return result
+}
+
+// WriteValidatorStatisticsForEpochRange exports the balance, sync duty and failed attestation statistics of the epochs between
+// firstEpoch and lastEpoch (inclusive) to the validator_stats_buckets table, keyed by bucketKey instead of a day. It allows sub-daily
+// rollups, e.g. hourly ones on fast finalizing testnets, and is independent of the daily export: nothing is written to validator_stats
+// or validator_stats_status. Exporting a bucket again overwrites its statistics. A bucket spans at most the epochs of a day.
+func WriteValidatorStatisticsForEpochRange(firstEpoch, lastEpoch uint64, bucketKey string) error {
+	exportStart := time.Now()
+	defer func() {
+		metrics.TaskDuration.WithLabelValues("db_update_validator_stats_buckets").Observe(time.Since(exportStart).Seconds())
+	}()
+
+	if bucketKey == "" {
+		return fmt.Errorf("error exporting epochs %v to %v: bucket key must not be empty", firstEpoch, lastEpoch)
+	}
+	if lastEpoch < firstEpoch {
+		return fmt.Errorf("error exporting bucket %v: last epoch %v is before first epoch %v", bucketKey, lastEpoch, firstEpoch)
+	}
+	// the failed attestations of the range are fetched at once, which the daily export only does for batches of epochs
+	if epochs := lastEpoch - firstEpoch + 1; epochs > utils.EpochsPerDay() {
+		return fmt.Errorf("error exporting bucket %v: %v epochs exceed the %v epochs of a day", bucketKey, epochs, utils.EpochsPerDay())
+	}
+	if err := checkIfEpochRangeIsFinalized(firstEpoch, lastEpoch); err != nil {
+		return err
+	}
+
+	logger.Infof("exporting statistics of epochs %v to %v to bucket %v", firstEpoch, lastEpoch, bucketKey)
+
+	var balances map[uint64]*types.ValidatorBalanceStatistic
+	err := retryBigtable("GetValidatorBalanceStatistics", func() error {
+		var err error
+		balances, err = BigtableClient.GetValidatorBalanceStatistics(firstEpoch, lastEpoch)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error calculating TOTAL_GASUSED chart_series: %w", err)
+		return err
 	}
 
-	if blockCount > 0 {
-		logger.Infof("Exporting AVG_GASLIMIT %v", totalGasLimit.Div(decimal.NewFromInt(blockCount)))
-		err = SaveChartSeriesPoint(dateTrunc, "AVG_GASLIMIT", totalGasLimit.Div(decimal.NewFromInt(blockCount)))
-		if err != nil {
-			return fmt.Errorf("error calculating AVG_GASLIMIT chart_series: %w", err)
-		}
+	var syncStats map[uint64]*types.ValidatorSyncDutiesStatistic
+	err = retryBigtable("GetValidatorSyncDutiesStatistics", func() error {
+		var err error
+		syncStats, err = BigtableClient.GetValidatorSyncDutiesStatistics(exportedValidators(), firstEpoch, lastEpoch)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	if !totalGasLimit.IsZero() {
-		logger.Infof("Exporting AVG_BLOCK_UTIL %v", totalGasUsed.Div(totalGasLimit).Mul(decimal.NewFromInt(100)))
-		err = SaveChartSeriesPoint(dateTrunc, "AVG_BLOCK_UTIL", totalGasUsed.Div(totalGasLimit).Mul(decimal.NewFromInt(100)))
+	var failed map[uint64]*types.ValidatorFailedAttestationsStatistic
+	err = retryBigtable("GetValidatorFailedAttestationsCount", func() error {
+		var err error
+		failed, err = BigtableClient.GetValidatorFailedAttestationsCount(exportedValidators(), firstEpoch, lastEpoch)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// like in the daily export the attestation components are only read if enabled
+	var components map[uint64]*types.ValidatorAttestationComponentsStatistic
+	if utils.Config.Statistics.AttestationComponents {
+		err = retryBigtable("GetValidatorMissedAttestationComponents", func() error {
+			var err error
+			components, err = BigtableClient.GetValidatorMissedAttestationComponents(exportedValidators(), firstEpoch, lastEpoch)
+			return err
+		})
 		if err != nil {
-			return fmt.Errorf("error calculating AVG_BLOCK_UTIL chart_series: %w", err)
+			return err
 		}
 	}
 
-	logger.Infof("Exporting MARKET_CAP: %v", newEmission.Div(decimal.NewFromInt(1e18)).Add(decimal.NewFromFloat(72009990.50)).Mul(decimal.NewFromFloat(price.GetEthPrice("USD"))).String())
-	err = SaveChartSeriesPoint(dateTrunc, "MARKET_CAP", newEmission.Div(decimal.NewFromInt(1e18)).Add(decimal.NewFromFloat(72009990.50)).Mul(decimal.NewFromFloat(price.GetEthPrice("USD"))).String())
-	if err != nil {
-		return fmt.Errorf("error calculating MARKET_CAP chart_series: %w", err)
+	rows := validatorStatsBucketRows(balances, syncStats, failed, components)
+	if utils.Config.Statistics.DryRun {
+		logger.Infof("dry run: skipping export of %v rows to bucket %v", len(rows), bucketKey)
+		return nil
+	}
+	if len(rows) == 0 {
+		logger.Infof("no statistics for epochs %v to %v of bucket %v", firstEpoch, lastEpoch, bucketKey)
+		return nil
 	}
 
-	logger.Infof("Exporting TX_COUNT %v", txCount)
-	err = SaveChartSeriesPoint(dateTrunc, "TX_COUNT", txCount)
+	tx, err := WriterDb.Beginx()
 	if err != nil {
-		return fmt.Errorf("error calculating TX_COUNT chart_series: %w", err)
+		return err
 	}
+	defer tx.Rollback()
 
-	// Not sure how this is currently possible (where do we store the size, i think this is missing)
-	// logger.Infof("Exporting AVG_SIZE %v", totalSize.div)
-	// err = SaveChartSeriesPoint(dateTrunc, "AVG_SIZE", totalSize.div)
-	// if err != nil {
-	// 	return fmt.Errorf("error calculating AVG_SIZE chart_series: %w", err)
-	// }
+	numArgs := len(strings.Split(validatorStatsBucketColumns, ", "))
+	batchSize := insertBatchSize("validator_stats_buckets", 0, 1000, numArgs)
+	for b := 0; b < len(rows); b += batchSize {
+		end := b + batchSize
+		if len(rows) < end {
+			end = len(rows)
+		}
 
-	// logger.Infof("Exporting POWER_CONSUMPTION %v", avgBlockTime.String())
-	// err = SaveChartSeriesPoint(dateTrunc, "POWER_CONSUMPTION", avgBlockTime.String())
-	// if err != nil {
-	// 	return fmt.Errorf("error calculating POWER_CONSUMPTION chart_series: %w", err)
-	// }
+		valueStrings := make([]string, 0, end-b)
+		valueArgs := make([]interface{}, 0, (end-b)*numArgs)
+		for i, row := range rows[b:end] {
+			placeholders := make([]string, numArgs)
+			for j := range placeholders {
+				placeholders[j] = fmt.Sprintf("$%d", i*numArgs+j+1)
+			}
+			valueStrings = append(valueStrings, "("+strings.Join(placeholders, ", ")+")")
+			valueArgs = append(valueArgs, row.values(bucketKey, firstEpoch, lastEpoch)...)
+		}
+		_, err := tx.Exec(fmt.Sprintf(`
+			insert into validator_stats_buckets (%s) VALUES
+			%s
+			on conflict (validatorindex, bucket) do update set first_epoch = excluded.first_epoch, last_epoch = excluded.last_epoch, min_balance = excluded.min_balance, max_balance = excluded.max_balance, min_effective_balance = excluded.min_effective_balance, max_effective_balance = excluded.max_effective_balance, start_balance = excluded.start_balance, start_effective_balance = excluded.start_effective_balance, end_balance = excluded.end_balance, end_effective_balance = excluded.end_effective_balance, participated_sync = excluded.participated_sync, missed_sync = excluded.missed_sync, orphaned_sync = excluded.orphaned_sync, sync_participation_rate = excluded.sync_participation_rate, missed_attestations = excluded.missed_attestations, orphaned_attestations = excluded.orphaned_attestations, missed_source = excluded.missed_source, missed_target = excluded.missed_target, missed_head = excluded.missed_head;`,
+			validatorStatsBucketColumns, strings.Join(valueStrings, ",")), valueArgs...)
+		if err != nil {
+			return fmt.Errorf("error exporting bucket %v: %w", bucketKey, err)
+		}
+	}
 
-	// logger.Infof("Exporting NEW_ACCOUNTS %v", avgBlockTime.String())
-	// err = SaveChartSeriesPoint(dateTrunc, "NEW_ACCOUNTS", avgBlockTime.String())
-	// if err != nil {
-	// 	return fmt.Errorf("error calculating NEW_ACCOUNTS chart_series: %w", err)
-	// }
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	observeRowsExported("validator_stats_buckets", len(rows))
 
-	logger.Infof("marking day export as completed in the status table")
-	_, err = WriterDb.Exec("insert into chart_series_status (day, status) values ($1, true)", day)
+	logger.Infof("export of bucket %v completed, took %v", bucketKey, time.Since(exportStart))
+	return nil
+}
+
+// checkIfEpochRangeIsFinalized is the equivalent of checkIfDayIsFinalized for the exports of an epoch range
+func checkIfEpochRangeIsFinalized(firstEpoch, lastEpoch uint64) error {
+	finalizedCount, err := CountFinalizedEpochs(firstEpoch, lastEpoch)
 	if err != nil {
 		return err
 	}
 
-	logger.Infof("chart_series export completed: took %v", time.Since(startTs))
-
+	if epochs := lastEpoch - firstEpoch + 1; finalizedCount < epochs {
+		return fmt.Errorf("%w: delaying export as not all epochs %v to %v are finalized. %v of %v", ErrDayNotFinalized, firstEpoch, lastEpoch, finalizedCount, epochs)
+	}
 	return nil
 }
 
 func checkIfDayIsFinalized(day uint64) error {
-	epochsPerDay := utils.EpochsPerDay()
 	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+	epochsInDay := lastEpoch - firstEpoch + 1
 
 	finalizedCount, err := CountFinalizedEpochs(firstEpoch, lastEpoch)
 	if err != nil {
 		return err
 	}
 
-	if finalizedCount < epochsPerDay {
-		return fmt.Errorf("delaying export as not all epochs for day %v finalized. %v of %v", day, finalizedCount, epochsPerDay)
+	if finalizedCount < epochsInDay {
+		return fmt.Errorf("%w: delaying export as not all epochs for day %v finalized. %v of %v", ErrDayNotFinalized, day, finalizedCount, epochsInDay)
+	}
+	return nil
+}
+
+// ErrDayNotFinalized is returned by the exports of a day or an epoch range whose epochs are not all finalized yet, the export can be retried later
+var ErrDayNotFinalized = errors.New("day not finalized")
+
+// ErrMissingDependency matches a MissingDependencyError with errors.Is
+var ErrMissingDependency = errors.New("missing required export")
+
+// MissingDependencyError is returned by a sub-export if the exports it depends on have not been completed yet. Dependencies are the
+// names of the missing export columns, prefixed by cur_ or last_ if they refer to the day itself or the day before.
+type MissingDependencyError struct {
+	Day          uint64
+	Dependencies []string
+}
+
+func (e *MissingDependencyError) Error() string {
+	return fmt.Sprintf("%v of day %v: %v", ErrMissingDependency, e.Day, strings.Join(e.Dependencies, ", "))
+}
+
+func (e *MissingDependencyError) Is(target error) bool {
+	return target == ErrMissingDependency
+}
+
+// missingDependencyError returns a MissingDependencyError listing the dependencies that are not exported, nil if all of them are
+func missingDependencyError(day uint64, exported map[string]bool) error {
+	missing := []string{}
+	for dependency, ok := range exported {
+		if !ok {
+			missing = append(missing, dependency)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return &MissingDependencyError{Day: day, Dependencies: missing}
+}
+
+// ErrBigtable matches a BigtableError with errors.Is
+var ErrBigtable = errors.New("bigtable error")
+
+// BigtableError is returned by the exports if their data could not be read from Bigtable, after retrying transient errors
+type BigtableError struct {
+	Op  string
+	Err error
+}
+
+func (e *BigtableError) Error() string {
+	return fmt.Sprintf("error calling %v: %v", e.Op, e.Err)
+}
+
+func (e *BigtableError) Unwrap() error {
+	return e.Err
+}
+
+func (e *BigtableError) Is(target error) bool {
+	return target == ErrBigtable
+}
+
+// retryBigtable calls fn until it succeeds, returns an error that is not worth retrying or the configured
+// number of attempts is exhausted. The delay between attempts grows exponentially and is jittered. The last error is
+// returned wrapped in a BigtableError.
+func retryBigtable(name string, fn func() error) error {
+	maxAttempts := utils.Config.Statistics.BigtableMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	delay := utils.Config.Statistics.BigtableRetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !isRetryableBigtableError(err) || attempt == maxAttempts {
+			break
+		}
+
+		backoff := delay * time.Duration(1<<(attempt-1))
+		backoff += time.Duration(rand.Int63n(int64(backoff)))
+		logger.Warnf("error calling %v (attempt %v of %v), retrying in %v: %v", name, attempt, maxAttempts, backoff, err)
+		time.Sleep(backoff)
+	}
+	if err != nil {
+		return &BigtableError{Op: name, Err: err}
+	}
+	return nil
+}
+
+func isRetryableBigtableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.PermissionDenied, codes.Unauthenticated:
+		return false
+	}
+	return true
+}
+
+// StatisticsExportHook is called after the validator statistics of a day have been exported completely
+type StatisticsExportHook func(day uint64, duration time.Duration)
+
+var statisticsExportHooks = struct {
+	sync.RWMutex
+	hooks []StatisticsExportHook
+}{}
+
+// RegisterStatisticsExportHook registers fn to be called at the end of WriteValidatorStatisticsForDay once the day has been
+// marked as exported and its lock has been released. Hooks are called synchronously by the exporter and must return quickly.
+func RegisterStatisticsExportHook(fn StatisticsExportHook) {
+	statisticsExportHooks.Lock()
+	defer statisticsExportHooks.Unlock()
+	statisticsExportHooks.hooks = append(statisticsExportHooks.hooks, fn)
+}
+
+type statisticsExportWebhookPayload struct {
+	Day         uint64 `json:"day"`
+	FirstEpoch  uint64 `json:"first_epoch"`
+	LastEpoch   uint64 `json:"last_epoch"`
+	StartedAt   int64  `json:"started_at"`
+	CompletedAt int64  `json:"completed_at"`
+	DurationMs  int64  `json:"duration_ms"`
+}
+
+func runStatisticsExportHooks(day uint64, exportStart time.Time) {
+	completedAt := time.Now()
+	duration := completedAt.Sub(exportStart)
+
+	statisticsExportHooks.RLock()
+	hooks := statisticsExportHooks.hooks
+	statisticsExportHooks.RUnlock()
+	for _, hook := range hooks {
+		hook(day, duration)
+	}
+
+	if utils.Config.Statistics.ExportWebhookURL == "" {
+		return
+	}
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(day)
+	payload := &statisticsExportWebhookPayload{
+		Day:         day,
+		FirstEpoch:  firstEpoch,
+		LastEpoch:   lastEpoch,
+		StartedAt:   exportStart.Unix(),
+		CompletedAt: completedAt.Unix(),
+		DurationMs:  duration.Milliseconds(),
+	}
+	timeout := utils.Config.Statistics.ExportWebhookTimeout
+	if timeout <= 0 {
+		timeout = time.Second * 5
+	}
+	if err := postStatisticsExportWebhook(utils.Config.Statistics.ExportWebhookURL, timeout, payload); err != nil {
+		utils.LogError(err, "error calling statistics export webhook", 0, map[string]interface{}{"day": day})
+	}
+}
+
+// postStatisticsExportWebhook posts the payload to url exactly once, a failing or slow endpoint only delays the exporter by timeout
+func postStatisticsExportWebhook(url string, timeout time.Duration, payload *statisticsExportWebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %v", resp.StatusCode)
 	}
 	return nil
 }
+
+// ExportProgressFunc receives the number of completed and scheduled batches of a statistics export stage
+type ExportProgressFunc func(stage string, done, total int)
+
+type exportProgressUpdate struct {
+	stage       string
+	done, total int
+}
+
+var exportProgressReporter = struct {
+	sync.RWMutex
+	updates chan exportProgressUpdate
+}{}
+
+// SetExportProgressReporter registers fn to be called after each completed batch of the statistics exports.
+// fn is invoked from a separate goroutine, updates are dropped while fn is still busy so a slow consumer
+// can never stall an export. Passing nil disables the reporting again.
+func SetExportProgressReporter(fn ExportProgressFunc) {
+	exportProgressReporter.Lock()
+	defer exportProgressReporter.Unlock()
+
+	if exportProgressReporter.updates != nil {
+		close(exportProgressReporter.updates)
+		exportProgressReporter.updates = nil
+	}
+	if fn == nil {
+		return
+	}
+
+	updates := make(chan exportProgressUpdate, 100)
+	go func() {
+		for u := range updates {
+			fn(u.stage, u.done, u.total)
+		}
+	}()
+	exportProgressReporter.updates = updates
+}
+
+func reportExportProgress(stage string, done, total int) {
+	exportProgressReporter.RLock()
+	defer exportProgressReporter.RUnlock()
+
+	if exportProgressReporter.updates == nil {
+		return
+	}
+	select {
+	case exportProgressReporter.updates <- exportProgressUpdate{stage: stage, done: done, total: total}:
+	default:
+	}
+}
+
+// exportProgress reports the batch progress of a statistics sub-export to prometheus and the registered ExportProgressFunc
+type exportProgress struct {
+	export    string
+	total     prometheus.Gauge
+	completed prometheus.Gauge
+
+	mux            sync.Mutex
+	totalCount     int
+	completedCount int
+}
+
+// newExportProgress resets the batch gauges of the sub-export, they always refer to the day exported last
+func newExportProgress(export string) *exportProgress {
+	p := &exportProgress{
+		export:    export,
+		total:     metrics.StatsExportBatchesTotal.WithLabelValues(export),
+		completed: metrics.StatsExportBatchesCompleted.WithLabelValues(export),
+	}
+	p.total.Set(0)
+	p.completed.Set(0)
+	return p
+}
+
+func (p *exportProgress) batchScheduled() {
+	p.total.Inc()
+
+	p.mux.Lock()
+	p.totalCount++
+	p.mux.Unlock()
+}
+
+func (p *exportProgress) batchCompleted() {
+	p.completed.Inc()
+
+	p.mux.Lock()
+	p.completedCount++
+	done, total := p.completedCount, p.totalCount
+	p.mux.Unlock()
+
+	reportExportProgress(p.export, done, total)
+}
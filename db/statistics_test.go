@@ -0,0 +1,3785 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"cloud.google.com/go/bigtable/bttest"
+	"github.com/ethereum/go-ethereum/common"
+	itypes "github.com/gobitfly/eth-rewards/types"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type flakyBigtable struct {
+	calls    int
+	failures int
+	err      error
+}
+
+func (f *flakyBigtable) GetValidatorBalanceStatistics(startEpoch, endEpoch uint64) (map[uint64]*types.ValidatorBalanceStatistic, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return map[uint64]*types.ValidatorBalanceStatistic{1: {Index: 1}}, nil
+}
+
+func TestRetryBigtable(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Statistics.BigtableMaxAttempts = 3
+	utils.Config.Statistics.BigtableRetryDelay = time.Millisecond
+
+	tests := []struct {
+		name      string
+		client    *flakyBigtable
+		wantCalls int
+		wantErr   bool
+	}{
+		{"transient errors are retried", &flakyBigtable{failures: 2, err: status.Error(codes.Unavailable, "unavailable")}, 3, false},
+		{"attempts are limited", &flakyBigtable{failures: 5, err: status.Error(codes.Unavailable, "unavailable")}, 3, true},
+		{"invalid argument is not retried", &flakyBigtable{failures: 2, err: status.Error(codes.InvalidArgument, "invalid")}, 1, true},
+		{"cancelled context is not retried", &flakyBigtable{failures: 2, err: context.Canceled}, 1, true},
+	}
+
+	for _, tt := range tests {
+		var res map[uint64]*types.ValidatorBalanceStatistic
+		err := retryBigtable("GetValidatorBalanceStatistics", func() error {
+			var err error
+			res, err = tt.client.GetValidatorBalanceStatistics(0, 224)
+			return err
+		})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+		}
+		if tt.client.calls != tt.wantCalls {
+			t.Errorf("%v: expected %v calls, got %v", tt.name, tt.wantCalls, tt.client.calls)
+		}
+		if !tt.wantErr && len(res) != 1 {
+			t.Errorf("%v: expected result of the successful call, got %v", tt.name, res)
+		}
+	}
+}
+
+func TestExportErrorTypes(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{contains: "AND finalized", columns: []string{"count"}, row: []driver.Value{int64(100)}},
+		recordingResult{contains: "SELECT failed_attestations_exported", columns: []string{"failed_attestations_exported"}, row: []driver.Value{false}},
+	)
+	utils.Config.Statistics.BigtableMaxAttempts = 2
+	utils.Config.Statistics.BigtableRetryDelay = time.Millisecond
+
+	// only 100 of the 225 epochs of the day are finalized
+	err := checkIfDayIsFinalized(10)
+	if !errors.Is(err, ErrDayNotFinalized) {
+		t.Errorf("expected ErrDayNotFinalized, got %v", err)
+	}
+	if errors.Is(err, ErrMissingDependency) || errors.Is(err, ErrBigtable) {
+		t.Errorf("expected the error to only match ErrDayNotFinalized, got %v", err)
+	}
+
+	recorder.results = recorder.results[1:]
+	err = WriteValidatorAttestationInclusionStats(10)
+	var missing *MissingDependencyError
+	if !errors.Is(err, ErrMissingDependency) || !errors.As(err, &missing) {
+		t.Fatalf("expected a MissingDependencyError, got %v", err)
+	}
+	if missing.Day != 10 || !reflect.DeepEqual(missing.Dependencies, []string{"failed_attestations_exported"}) {
+		t.Errorf("expected the failed attestations of day 10 to be missing, got %v of day %v", missing.Dependencies, missing.Day)
+	}
+	if err := missingDependencyError(10, map[string]bool{"cur_balance_exported": true}); err != nil {
+		t.Errorf("expected no error without missing dependencies, got %v", err)
+	}
+
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	err = retryBigtable("GetValidatorBalanceStatistics", func() error {
+		return unavailable
+	})
+	var bigtableErr *BigtableError
+	if !errors.Is(err, ErrBigtable) || !errors.As(err, &bigtableErr) {
+		t.Fatalf("expected a BigtableError, got %v", err)
+	}
+	if bigtableErr.Op != "GetValidatorBalanceStatistics" || !errors.Is(err, unavailable) {
+		t.Errorf("expected the failed call and its error to be kept, got %v", bigtableErr)
+	}
+	if err := retryBigtable("GetValidatorBalanceStatistics", func() error { return nil }); err != nil {
+		t.Errorf("expected no error for a successful call, got %v", err)
+	}
+}
+
+func TestClRewardsNetIgnoresTopUpDeposits(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{
+			contains: "last_balance_exported",
+			columns:  []string{"last_balance_exported", "cur_balance_exported", "cur_withdrawals_deposits_exported"},
+			row:      []driver.Value{true, true, true},
+		},
+	)
+	BigtableClient = newEmptyBigtable(t)
+
+	// validator 3 earns 1.9 mETH through its duties during day 10 and gets a 1 ETH top-up, which is not part of its income details
+	for epoch, income := range map[uint64]*itypes.ValidatorEpochIncome{
+		2250: {AttestationSourceReward: 600_000, AttestationTargetReward: 1_100_000},
+		2251: {AttestationHeadReward: 300_000, AttestationTargetPenalty: 100_000},
+	} {
+		if err := BigtableClient.SaveValidatorIncomeDetails(epoch, map[uint64]*itypes.ValidatorEpochIncome{3: income}); err != nil {
+			t.Fatalf("error saving income details: %v", err)
+		}
+	}
+
+	if err := WriteValidatorClIcome(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	statements, args := recorder.executed(), recorder.executedArgs()
+	netWritten, grossWritten := false, false
+	for i, stmt := range statements {
+		switch {
+		case strings.Contains(stmt, "cl_rewards_gwei_net) VALUES"):
+			// validators 0 to 3 with 7 values each, the net rewards are the last value of a validator
+			if len(args[i]) != 4*7 {
+				t.Fatalf("expected the rows of the validators 0 to 3, got %v", args[i])
+			}
+			for validator := 0; validator < 4; validator++ {
+				expected := int64(0)
+				if validator == 3 {
+					expected = 1_900_000
+				}
+				if net := args[i][validator*7+6]; net != expected {
+					t.Errorf("expected net cl rewards of %v gwei for validator %v, got %v", expected, validator, net)
+				}
+			}
+			netWritten = true
+		case strings.Contains(stmt, "INSERT INTO validator_stats (validatorindex, day, cl_rewards_gwei)"):
+			// the gross rewards are derived from the balances and only match once the deposits have been subtracted
+			if !strings.Contains(stmt, "- COALESCE(cur.deposits_amount, 0)") {
+				t.Errorf("expected the deposits to be subtracted from the gross cl rewards, got %v", stmt)
+			}
+			grossWritten = true
+		}
+	}
+	if !netWritten || !grossWritten {
+		t.Errorf("expected the net and gross cl rewards to be written, got %v", statements)
+	}
+}
+
+func TestProposerRewardsBreakdownSumsToCombinedRewards(t *testing.T) {
+	income := &itypes.ValidatorEpochIncome{
+		AttestationSourceReward:            600_000,
+		ProposerAttestationInclusionReward: 25_000_000,
+		ProposerSyncInclusionReward:        1_500_000,
+		ProposerSlashingInclusionReward:    62_500_000,
+	}
+
+	breakdown := newProposerRewardsBreakdown(income)
+	if breakdown.AttestationInclusion != 25_000_000 || breakdown.SyncInclusion != 1_500_000 || breakdown.SlashingInclusion != 62_500_000 {
+		t.Errorf("unexpected proposer rewards breakdown %+v", breakdown)
+	}
+	combined := income.ProposerAttestationInclusionReward + income.ProposerSlashingInclusionReward + income.ProposerSyncInclusionReward
+	if breakdown.Total() != combined {
+		t.Errorf("expected the proposer reward components to sum to %v, got %v", combined, breakdown.Total())
+	}
+
+	if total := newProposerRewardsBreakdown(nil).Total(); total != 0 {
+		t.Errorf("expected no proposer rewards for validators without income, got %v", total)
+	}
+}
+
+func TestSlashingIncome(t *testing.T) {
+	// a day with a single slashing: validator 3 included it, validator 7 was reported as whistleblower and validator 9
+	// was the slashed one
+	incomeStats := map[uint64]*itypes.ValidatorEpochIncome{
+		3:  {ProposerAttestationInclusionReward: 25_000_000, ProposerSlashingInclusionReward: 62_500_000},
+		7:  {AttestationSourceReward: 600_000, SlashingReward: 1_000_000},
+		9:  {SlashingPenalty: 1_000_000_000},
+		11: nil,
+	}
+
+	rewards := slashingIncome(incomeStats)
+	expected := map[uint64]uint64{3: 62_500_000, 7: 1_000_000}
+	if len(rewards) != len(expected) {
+		t.Fatalf("expected slashing income for %v validators, got %v", len(expected), rewards)
+	}
+	for validator, reward := range expected {
+		if rewards[validator] != reward {
+			t.Errorf("expected slashing income of %v for validator %v, got %v", reward, validator, rewards[validator])
+		}
+	}
+}
+
+func TestValidatorDayExtremesQuery(t *testing.T) {
+	tests := []struct {
+		column    string
+		ascending bool
+		order     string
+	}{
+		{"cl_rewards_gwei", false, "ORDER BY cl_rewards_gwei DESC, day DESC"},
+		{"cl_rewards_gwei", true, "ORDER BY cl_rewards_gwei ASC, day DESC"},
+		{"missed_attestations", false, "ORDER BY missed_attestations DESC, day DESC"},
+	}
+	for _, tt := range tests {
+		query := validatorDayExtremesQuery(tt.column, tt.ascending)
+		if !strings.Contains(query, tt.order) {
+			t.Errorf("expected query to contain %q, got %v", tt.order, query)
+		}
+		if !strings.Contains(query, tt.column+" IS NOT NULL") {
+			t.Errorf("expected days without exported %v to be excluded, got %v", tt.column, query)
+		}
+	}
+}
+
+func TestMarkColumnExportedQuery(t *testing.T) {
+	if column := exportDurationColumn("cl_rewards_exported"); column != "cl_rewards_export_ms" {
+		t.Errorf("expected duration column cl_rewards_export_ms, got %v", column)
+	}
+
+	query := markColumnExportedQuery("balance_exported", true)
+	for _, expected := range []string{"(day, status, balance_exported, balance_export_ms)", "VALUES ($1, false, true, $2)", "balance_export_ms=EXCLUDED.balance_export_ms"} {
+		if !strings.Contains(query, expected) {
+			t.Errorf("expected query to contain %q, got %v", expected, query)
+		}
+	}
+
+	if query := markColumnExportedQuery("balance_exported", false); strings.Contains(query, "export_ms") || strings.Contains(query, "$2") {
+		t.Errorf("expected no duration to be stored without one being passed, got %v", query)
+	}
+}
+
+func TestDepositWithdrawalSlotRange(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.SlotsPerEpoch = 32
+	utils.Config.Chain.Config.SecondsPerSlot = 12
+	slotsPerDay := utils.EpochsPerDay() * 32
+
+	inRange := func(day, slot uint64) bool {
+		from, to := depositWithdrawalSlotRange(day)
+		return slot >= from && slot < to
+	}
+
+	if from, _ := depositWithdrawalSlotRange(0); from != 0 {
+		t.Errorf("expected day 0 to start at genesis, got slot %v", from)
+	}
+	for day := uint64(0); day < 3; day++ {
+		_, to := depositWithdrawalSlotRange(day)
+		if from, _ := depositWithdrawalSlotRange(day + 1); from != to {
+			t.Errorf("expected the ranges of day %v and %v to be contiguous, got %v and %v", day, day+1, to, from)
+		}
+	}
+
+	// a withdrawal in the first slot of a day is reflected in the end balance of that day
+	firstSlot := 5 * slotsPerDay
+	if !inRange(5, firstSlot) || inRange(4, firstSlot) {
+		t.Errorf("expected a withdrawal in the first slot of day 5 to be attributed to day 5")
+	}
+
+	// a withdrawal in the last slot of a day is only reflected in the end balance of the next day
+	lastSlot := 6*slotsPerDay - 1
+	if !inRange(6, lastSlot) || inRange(5, lastSlot) {
+		t.Errorf("expected a withdrawal in the last slot of day 5 to be attributed to day 6")
+	}
+}
+
+func TestExportDeadline(t *testing.T) {
+	utils.Config = &types.Config{}
+	if d := exportDeadline("balance"); d != 10*time.Minute {
+		t.Errorf("expected the default deadline of 10m, got %v", d)
+	}
+
+	utils.Config.Statistics.ExportDeadlines = map[string]time.Duration{"balance": time.Hour, "cl_rewards": 0}
+	if d := exportDeadline("balance"); d != time.Hour {
+		t.Errorf("expected the configured deadline of 1h, got %v", d)
+	}
+	if d := exportDeadline("cl_rewards"); d != 10*time.Minute {
+		t.Errorf("expected the default deadline for a zero configured deadline, got %v", d)
+	}
+}
+
+func TestValidatorPerformanceLeaderboardQuery(t *testing.T) {
+	periods := map[string]string{
+		"1d":    "ORDER BY cl_performance_1d DESC",
+		"7d":    "ORDER BY cl_performance_7d DESC",
+		"31d":   "ORDER BY cl_performance_31d DESC",
+		"365d":  "ORDER BY cl_performance_365d DESC",
+		"total": "ORDER BY cl_performance_total DESC",
+	}
+	for period, order := range periods {
+		query, err := validatorPerformanceLeaderboardQuery(period, 10, 0)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", period, err)
+			continue
+		}
+		if !strings.Contains(query, order) {
+			t.Errorf("%v: expected query to contain %q, got %v", period, order, query)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		period  string
+		limit   int
+		offset  int
+		wantErr bool
+	}{
+		{"unknown period", "30d", 10, 0, true},
+		{"injected period", "total; DROP TABLE validator_performance", 10, 0, true},
+		{"zero limit", "7d", 0, 0, true},
+		{"negative limit", "7d", -1, 0, true},
+		{"max limit", "7d", maxValidatorPerformanceLeaderboardLimit, 0, false},
+		{"limit above max", "7d", maxValidatorPerformanceLeaderboardLimit + 1, 0, true},
+		{"negative offset", "7d", 10, -1, true},
+		{"large offset", "7d", 10, 1_000_000, false},
+	}
+	for _, tt := range tests {
+		_, err := validatorPerformanceLeaderboardQuery(tt.period, tt.limit, tt.offset)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestRunValidatorBatchesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := runValidatorBatches(ctx, 10, "test", 100_000, func(start, end int) error {
+		t.Errorf("expected batch %v - %v to be skipped", start, end)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancellation to be returned, got %v", err)
+	}
+
+	// a cancellation after all batches ran does not fail the export
+	ctx, cancel = context.WithCancel(context.Background())
+	err = runValidatorBatches(ctx, 10, "test", 500, func(start, end int) error {
+		cancel()
+		return nil
+	})
+	if err != nil {
+		t.Errorf("expected a run without skipped batches to succeed, got %v", err)
+	}
+}
+
+func TestWriteValidatorTotalPerformanceDeadlineExceeded(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{
+			contains: "last_cl_rewards_exported",
+			columns:  []string{"last_cl_rewards_exported", "last_el_rewards_exported", "cur_cl_rewards_exported", "cur_el_rewards_exported"},
+			row:      []driver.Value{true, true, true, true},
+		},
+		recordingResult{contains: "max(validatorindex) + 1", columns: []string{"count"}, row: []driver.Value{int64(5000)}},
+	)
+	utils.Config.Statistics.ExportDeadlines = map[string]time.Duration{"cumulative_totals": time.Nanosecond}
+
+	if err := WriteValidatorTotalPerformance(10); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the exceeded deadline to be returned, got %v", err)
+	}
+	for _, stmt := range recorder.executed() {
+		if strings.Contains(stmt, "total_performance_exported") {
+			t.Errorf("expected total performance not to be marked exported, got %v", stmt)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances []uint64
+		p        float64
+		expected float64
+	}{
+		{"median of odd set", []uint64{31e9, 32e9, 33e9}, 0.5, 32e9},
+		{"median of even set", []uint64{31e9, 32e9, 32e9, 34e9}, 0.5, 32e9},
+		{"median is interpolated", []uint64{31e9, 32e9}, 0.5, 31.5e9},
+		{"25th percentile", []uint64{16e9, 31e9, 32e9, 32e9, 33e9}, 0.25, 31e9},
+		{"75th percentile", []uint64{16e9, 31e9, 32e9, 32e9, 33e9}, 0.75, 32e9},
+		{"single validator", []uint64{32e9}, 0.5, 32e9},
+		{"no validators", []uint64{}, 0.5, 0},
+	}
+
+	for _, tt := range tests {
+		if v := percentile(tt.balances, tt.p); v != tt.expected {
+			t.Errorf("%v: expected %v, got %v", tt.name, tt.expected, v)
+		}
+	}
+}
+
+type fakeStatisticsTx struct {
+	// statusRows are the days with a validator_stats_status row and whether their total performance is exported
+	statusRows map[uint64]bool
+	failOnExec int
+	execs      int
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeStatisticsTx) Get(dest interface{}, query string, args ...interface{}) error {
+	exported, ok := tx.statusRows[args[0].(uint64)]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	*dest.(*bool) = exported
+	return nil
+}
+
+func (tx *fakeStatisticsTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	tx.execs++
+	if tx.execs == tx.failOnExec {
+		return nil, errors.New("connection reset")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (tx *fakeStatisticsTx) Commit() error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeStatisticsTx) Rollback() error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+func TestDeleteValidatorStatsForDayIsAtomic(t *testing.T) {
+	tx := &fakeStatisticsTx{statusRows: map[uint64]bool{10: true}, failOnExec: 3}
+	err := deleteValidatorStatsForDay(tx, 10, false)
+	if err == nil {
+		t.Fatalf("expected error when deleting the relay stats fails")
+	}
+	if tx.committed || !tx.rolledBack {
+		t.Errorf("expected the transaction to be rolled back, committed: %v, rolled back: %v", tx.committed, tx.rolledBack)
+	}
+
+	tx = &fakeStatisticsTx{statusRows: map[uint64]bool{10: true}}
+	err = deleteValidatorStatsForDay(tx, 10, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tx.committed || tx.rolledBack {
+		t.Errorf("expected the transaction to be committed, committed: %v, rolled back: %v", tx.committed, tx.rolledBack)
+	}
+
+	// the running totals of day 11 are derived from day 10
+	tx = &fakeStatisticsTx{statusRows: map[uint64]bool{10: true, 11: true}}
+	if err := deleteValidatorStatsForDay(tx, 10, false); err == nil || tx.execs != 0 {
+		t.Errorf("expected the deletion to be refused without any statement executed, got %v after %v statements", err, tx.execs)
+	}
+	if tx.committed || !tx.rolledBack {
+		t.Errorf("expected the transaction to be rolled back, committed: %v, rolled back: %v", tx.committed, tx.rolledBack)
+	}
+}
+
+func TestDeleteValidatorStatsForDay(t *testing.T) {
+	// day 10 and day 11 have been exported including their total performance
+	recorder := newRecordingDb(t,
+		recordingResult{contains: "FROM validator_stats_status WHERE day = $1 FOR UPDATE", columns: []string{"total_performance_exported"}, row: []driver.Value{true}},
+	)
+
+	if err := DeleteValidatorStatsForDay(10, false); err == nil {
+		t.Errorf("expected the deletion to be refused while the totals of the following day are exported")
+	}
+	if statements := recorder.executed(); len(statements) != 0 {
+		t.Fatalf("expected nothing to be deleted, got %v", statements)
+	}
+
+	if err := DeleteValidatorStatsForDay(10, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statements, args := recorder.executed(), recorder.executedArgs()
+	deleted, reset, followingReset := false, false, false
+	for i, stmt := range statements {
+		day := args[i][0]
+		switch {
+		case strings.Contains(stmt, "DELETE FROM validator_stats WHERE day = $1"):
+			deleted = day == int64(10)
+		case strings.Contains(stmt, "UPDATE validator_stats_status") && strings.Contains(stmt, "failed_attestations_exported = false"):
+			reset = day == int64(10)
+			for _, column := range strings.Fields(strings.ReplaceAll(validatorStatsStatusColumns, ",", " ")) {
+				if strings.HasSuffix(column, "_exported") && !strings.Contains(stmt, column+" = false") {
+					t.Errorf("expected %v to be reset, got %v", column, stmt)
+				}
+			}
+		case strings.Contains(stmt, "UPDATE validator_stats_status SET status = false, total_performance_exported = false"):
+			followingReset = day == int64(11)
+		}
+	}
+	if !deleted || !reset || !followingReset {
+		t.Errorf("expected the rows of day 10 to be deleted (%v), its flags (%v) and the totals of day 11 (%v) to be reset, got %v", deleted, reset, followingReset, statements)
+	}
+
+	// a day that has never been exported is left alone
+	recorder = newRecordingDb(t)
+	if err := DeleteValidatorStatsForDay(12, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statements := recorder.executed(); len(statements) != 0 {
+		t.Errorf("expected nothing to be deleted for a day that has never been exported, got %v", statements)
+	}
+}
+
+func TestMissingStatisticsDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		uptoDay  uint64
+		exported []uint64
+		expected []uint64
+	}{
+		{"fully exported", 3, []uint64{0, 1, 2, 3}, []uint64{}},
+		{"gaps are sorted", 5, []uint64{4, 0, 2}, []uint64{1, 3, 5}},
+		{"nothing exported", 2, []uint64{}, []uint64{0, 1, 2}},
+		{"exported days past the range are ignored", 1, []uint64{0, 7}, []uint64{1}},
+	}
+
+	for _, tt := range tests {
+		if res := missingStatisticsDays(tt.uptoDay, tt.exported); !reflect.DeepEqual(res, tt.expected) {
+			t.Errorf("%v: expected %v, got %v", tt.name, tt.expected, res)
+		}
+	}
+}
+
+func TestLateBlocks(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+	utils.Config.Chain.Config.SecondsPerSlot = 12
+
+	slot := uint64(100)
+	onTime := BlockSeenDelay(slot, utils.SlotToTime(slot).Add(2*time.Second))
+	late := BlockSeenDelay(slot, utils.SlotToTime(slot).Add(6*time.Second))
+
+	if threshold := lateBlockThreshold(); threshold != 4*time.Second {
+		t.Fatalf("expected the default threshold of a third of a slot, got %v", threshold)
+	}
+	if onTime > lateBlockThreshold() {
+		t.Errorf("expected a block seen after %v not to be late", onTime)
+	}
+	if late <= lateBlockThreshold() {
+		t.Errorf("expected a block seen after %v to be late", late)
+	}
+
+	utils.Config.Statistics.LateBlockSlotThreshold = 0.75
+	if late > lateBlockThreshold() {
+		t.Errorf("expected a block seen after %v not to be late with a threshold of %v", late, lateBlockThreshold())
+	}
+}
+
+func TestWriteValidatorBlockStatsLateBlocks(t *testing.T) {
+	slot := uint64(100)
+	tests := []struct {
+		name        string
+		threshold   float64
+		thresholdMs int64
+		seenAfter   time.Duration
+		late        bool
+	}{
+		{name: "on time", thresholdMs: 4000, seenAfter: 2 * time.Second},
+		{name: "late", thresholdMs: 4000, seenAfter: 6 * time.Second, late: true},
+		{name: "on time with a higher threshold", threshold: 0.75, thresholdMs: 9000, seenAfter: 6 * time.Second},
+	}
+	for _, tt := range tests {
+		recorder := newRecordingDb(t)
+		utils.Config.Chain.GenesisTimestamp = 1606824023
+		utils.Config.Statistics.LateBlockSlotThreshold = tt.threshold
+
+		if err := WriteValidatorBlockStats(10); err != nil {
+			t.Fatalf("%v: unexpected error: %v", tt.name, err)
+		}
+		statements, args := recorder.executed(), recorder.executedArgs()
+		if len(statements) == 0 || !strings.Contains(statements[0], "sum(case when status = '1' and seen_delay_ms > $4 then 1 else 0 end)") {
+			t.Fatalf("%v: expected the block stats to count the proposed blocks seen after the threshold as late, got %v", tt.name, statements)
+		}
+		// first and last epoch of the day, the day and the threshold in ms
+		if expected := []driver.Value{int64(2250), int64(2474), int64(10), tt.thresholdMs}; !reflect.DeepEqual(args[0], expected) {
+			t.Errorf("%v: expected the args %v, got %v", tt.name, expected, args[0])
+		}
+
+		delay := BlockSeenDelay(slot, utils.SlotToTime(slot).Add(tt.seenAfter)).Milliseconds()
+		if late := delay > tt.thresholdMs; late != tt.late {
+			t.Errorf("%v: expected a block seen after %vms to be late %v with a threshold of %vms", tt.name, delay, tt.late, tt.thresholdMs)
+		}
+	}
+}
+
+func TestCompareValidatorBlockStats(t *testing.T) {
+	stored := map[uint64]types.ValidatorBlockStats{
+		1: {ProposedBlocks: 2},
+		2: {ProposedBlocks: 1, MissedBlocks: 1},
+		3: {OrphanedBlocks: 1},
+	}
+	counted := map[uint64]types.ValidatorBlockStats{
+		1: {ProposedBlocks: 2},
+		2: {ProposedBlocks: 2, MissedBlocks: 1},
+		4: {MissedBlocks: 1},
+	}
+
+	expected := []types.ValidatorBlockStatsDiscrepancy{
+		{ValidatorIndex: 2, Stored: types.ValidatorBlockStats{ProposedBlocks: 1, MissedBlocks: 1}, Counted: types.ValidatorBlockStats{ProposedBlocks: 2, MissedBlocks: 1}},
+		{ValidatorIndex: 3, Stored: types.ValidatorBlockStats{OrphanedBlocks: 1}},
+		{ValidatorIndex: 4, Counted: types.ValidatorBlockStats{MissedBlocks: 1}},
+	}
+	if res := compareValidatorBlockStats(stored, counted); !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected discrepancies %+v, got %+v", expected, res)
+	}
+
+	if res := compareValidatorBlockStats(counted, counted); len(res) != 0 {
+		t.Errorf("expected no discrepancies for matching block stats, got %+v", res)
+	}
+}
+
+func TestUnreconciledClRewards(t *testing.T) {
+	rows := []clRewardsReconciliationRow{
+		{ValidatorIndex: 1, ClRewards: 2_500_000, EndBalance: 32_002_500_000, LastEndBalance: 32_000_000_000},
+		{ValidatorIndex: 2, ClRewards: 2_000_000, EndBalance: 32_000_000_000, LastEndBalance: 32_010_000_000, WithdrawalsAmount: 12_000_000},
+		{ValidatorIndex: 3, ClRewards: 1_000_000, EndBalance: 33_001_000_000, LastEndBalance: 32_000_000_000, DepositsAmount: 1_000_000_000},
+	}
+	if res := unreconciledClRewards(rows, clRewardsReconciliationTolerance); len(res) != 0 {
+		t.Errorf("expected a correct day to reconcile, got mismatches for validators %v", res)
+	}
+
+	// attribute the withdrawal of validator 2 and the deposit of validator 3 to the wrong day
+	corrupted := append([]clRewardsReconciliationRow{}, rows...)
+	corrupted[1].WithdrawalsAmount = 0
+	corrupted[2].DepositsAmount = 0
+	expected := []uint64{2, 3}
+	if res := unreconciledClRewards(corrupted, clRewardsReconciliationTolerance); !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected mismatches for validators %v, got %v", expected, res)
+	}
+}
+
+func TestAggregateAttestationInclusion(t *testing.T) {
+	history := map[uint64][]*types.ValidatorAttestation{
+		1: {
+			{AttesterSlot: 100, InclusionSlot: 101, Status: 1},
+			{AttesterSlot: 132, InclusionSlot: 133, Status: 1},
+			{AttesterSlot: 164, InclusionSlot: 167, Status: 1},
+			{AttesterSlot: 196, InclusionSlot: 201, Status: 1},
+			{AttesterSlot: 228, InclusionSlot: 0, Status: 0},
+		},
+		2: {
+			{AttesterSlot: 101, InclusionSlot: 0, Status: 0},
+		},
+	}
+
+	res := aggregateAttestationInclusion(history)
+
+	stat := res[1]
+	if stat == nil {
+		t.Fatalf("expected inclusion statistics for validator 1")
+	}
+	if stat.IncludedAttestations != 4 {
+		t.Errorf("expected 4 included attestations, got %v", stat.IncludedAttestations)
+	}
+	if stat.AvgInclusionDistance() != 2.5 {
+		t.Errorf("expected an average inclusion distance of 2.5, got %v", stat.AvgInclusionDistance())
+	}
+	if stat.MaxInclusionDistance != 5 {
+		t.Errorf("expected a max inclusion distance of 5, got %v", stat.MaxInclusionDistance)
+	}
+	if stat.AvgInclusionDelay() != 1.5 || stat.MaxInclusionDelay() != 4 {
+		t.Errorf("expected an average inclusion delay of 1.5 and a max inclusion delay of 4, got %v and %v", stat.AvgInclusionDelay(), stat.MaxInclusionDelay())
+	}
+	if res[2] != nil {
+		t.Errorf("expected no inclusion statistics for a validator without included attestations, got %+v", res[2])
+	}
+}
+
+func TestSyncParticipationRate(t *testing.T) {
+	noDuties := &types.ValidatorSyncDutiesStatistic{Index: 1}
+	if rate := noDuties.ParticipationRate(); rate != nil {
+		t.Errorf("expected no participation rate for a validator without sync duties, got %v", *rate)
+	}
+
+	onlyOrphaned := &types.ValidatorSyncDutiesStatistic{Index: 3, OrphanedSync: 2}
+	if rate := onlyOrphaned.ParticipationRate(); rate != nil {
+		t.Errorf("expected no participation rate for a validator with only orphaned sync duties, got %v", *rate)
+	}
+
+	missedHalf := &types.ValidatorSyncDutiesStatistic{Index: 2, ParticipatedSync: 16, MissedSync: 16, OrphanedSync: 4}
+	rate := missedHalf.ParticipationRate()
+	if rate == nil {
+		t.Fatalf("expected a participation rate for a validator with sync duties")
+	}
+	if *rate != 0.5 {
+		t.Errorf("expected a participation rate of 0.5, got %v", *rate)
+	}
+}
+
+func TestWorstIncomeDays(t *testing.T) {
+	history := []types.ValidatorIncomeHistory{
+		{Day: 10, ClRewards: 2_500_000},
+		{Day: 11, ClRewards: -1_800_000}, // offline for most of the day
+		{Day: 12, ClRewards: 2_400_000},
+		{Day: 13, ClRewards: 2_400_000},
+		{Day: 14, ClRewards: -5_000_000}, // estimate of the current day
+	}
+
+	worst := worstIncomeDays(history, 13, 3)
+	expected := []int64{11, 12, 13}
+	if len(worst) != len(expected) {
+		t.Fatalf("expected %v days, got %+v", len(expected), worst)
+	}
+	for i, day := range expected {
+		if worst[i].Day != day {
+			t.Errorf("expected day %v at position %v, got %v", day, i, worst[i].Day)
+		}
+	}
+	if history[0].Day != 10 || history[1].Day != 11 {
+		t.Errorf("expected the history not to be modified, got %+v", history)
+	}
+}
+
+func TestStreamIncomeHistory(t *testing.T) {
+	newNext := func(rows []types.ValidatorIncomeHistory) func() (*types.ValidatorIncomeHistory, error) {
+		return func() (*types.ValidatorIncomeHistory, error) {
+			if len(rows) == 0 {
+				return nil, nil
+			}
+			row := rows[0]
+			rows = rows[1:]
+			return &row, nil
+		}
+	}
+	rows := []types.ValidatorIncomeHistory{{Day: 10, ClRewards: 100}, {Day: 11, ClRewards: 200}, {Day: 12, ClRewards: 300}}
+
+	total := int64(0)
+	err := streamIncomeHistory(newNext(rows), func(h types.ValidatorIncomeHistory) error {
+		total += h.ClRewards
+		return nil
+	})
+	if err != nil || total != 600 {
+		t.Errorf("expected the callback to aggregate all rows to 600, got %v (err: %v)", total, err)
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = streamIncomeHistory(newNext(rows), func(h types.ValidatorIncomeHistory) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("expected the iteration to stop at the first callback error, got %v after %v calls", err, calls)
+	}
+
+	errRead := errors.New("connection reset")
+	err = streamIncomeHistory(func() (*types.ValidatorIncomeHistory, error) { return nil, errRead }, func(types.ValidatorIncomeHistory) error { return nil })
+	if !errors.Is(err, errRead) {
+		t.Errorf("expected the read error to be returned, got %v", err)
+	}
+}
+
+func TestMergeIncomeHistory(t *testing.T) {
+	series := []types.ValidatorIncomeHistory{{Day: 10, ClRewards: 100}, {Day: 11, ClRewards: 110}, {Day: 12, ClRewards: 50}}
+
+	if from := incrementalIncomeHistoryFromDay(series, 0); from != 12 {
+		t.Errorf("expected the last cached day 12 to be queried again, got %v", from)
+	}
+	if from := incrementalIncomeHistoryFromDay(nil, 5); from != 5 {
+		t.Errorf("expected the lower bound to be queried without a cached series, got %v", from)
+	}
+
+	// day 12 was cached before its el rewards were exported
+	newer := []types.ValidatorIncomeHistory{{Day: 12, ClRewards: 120}, {Day: 13, ClRewards: 130}}
+	merged := mergeIncomeHistory(series, newer)
+	expected := []types.ValidatorIncomeHistory{{Day: 10, ClRewards: 100}, {Day: 11, ClRewards: 110}, {Day: 12, ClRewards: 120}, {Day: 13, ClRewards: 130}}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected merged series %+v, got %+v", expected, merged)
+	}
+	if series[2].ClRewards != 50 || len(series) != 3 {
+		t.Errorf("expected the cached series not to be modified, got %+v", series)
+	}
+
+	if merged := mergeIncomeHistory(series, nil); !reflect.DeepEqual(merged, series) {
+		t.Errorf("expected the cached series without newer days, got %+v", merged)
+	}
+}
+
+func TestGetValidatorStatsVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []driver.Value
+		want string
+	}{
+		{"nothing exported", []driver.Value{int64(0), int64(-1), int64(0)}, "0--1-0"},
+		{"days exported", []driver.Value{int64(11), int64(10), int64(1688774400)}, "11-10-1688774400"},
+		// day 5 has been deleted
+		{"day deleted", []driver.Value{int64(10), int64(10), int64(1688774400)}, "10-10-1688774400"},
+		// day 5 has been exported again
+		{"day re-exported", []driver.Value{int64(11), int64(10), int64(1688860800)}, "11-10-1688860800"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newRecordingDb(t, recordingResult{
+				contains: "FROM validator_stats_status",
+				columns:  []string{"days", "last_day", "last_completed"},
+				row:      tt.row,
+			})
+			version, err := getValidatorStatsVersion()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if version != tt.want {
+				t.Errorf("expected version %v, got %v", tt.want, version)
+			}
+		})
+	}
+}
+
+// BenchmarkIncomeHistoryCacheMiss compares the rows a cache miss has to query and merge for a year of history, once without and once
+// with a series cached on the previous day
+func BenchmarkIncomeHistoryCacheMiss(b *testing.B) {
+	const days = 365
+	history := make([]types.ValidatorIncomeHistory, days)
+	for i := range history {
+		history[i] = types.ValidatorIncomeHistory{Day: int64(i), ClRewards: 2_500_000}
+	}
+
+	b.Run("full", func(b *testing.B) {
+		rows := 0
+		for i := 0; i < b.N; i++ {
+			from := incrementalIncomeHistoryFromDay(nil, 0)
+			rows += len(history[from:])
+			_ = mergeIncomeHistory(nil, history[from:])
+		}
+		b.ReportMetric(float64(rows)/float64(b.N), "rows/op")
+	})
+
+	b.Run("incremental", func(b *testing.B) {
+		cached := history[:days-1]
+		rows := 0
+		for i := 0; i < b.N; i++ {
+			from := incrementalIncomeHistoryFromDay(cached, 0)
+			rows += len(history[from:])
+			_ = mergeIncomeHistory(cached, history[from:])
+		}
+		b.ReportMetric(float64(rows)/float64(b.N), "rows/op")
+	})
+}
+
+// BenchmarkIncomeHistoryChartExchangeRate compares looking up the exchange rate for every point of a 1000 day income chart with
+// looking it up once before converting the points
+func BenchmarkIncomeHistoryChartExchangeRate(b *testing.B) {
+	const days = 1000
+	history := make([]types.ValidatorIncomeHistory, days)
+	for i := range history {
+		history[i] = types.ValidatorIncomeHistory{Day: int64(i), ClRewards: 2_500_000}
+	}
+	priceRows := []recordingResult{
+		{contains: "FROM price WHERE ts = $1", columns: []string{"usd"}, row: []driver.Value{float64(1800)}},
+		{contains: "FROM price WHERE ts >= $1", columns: []string{"ts", "price"}, row: []driver.Value{time.Unix(0, 0).UTC(), float64(1800)}},
+	}
+
+	b.Run("per day", func(b *testing.B) {
+		recorder := newRecordingDb(b, priceRows...)
+		utils.Config.Chain.Config.DepositChainID = 1
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var err error
+			_ = incomeHistoryChartSeriesWithRates(history, func(day int64) float64 {
+				price, lookupErr := GetHistoricalPrice(1, "USD", uint64(day))
+				if lookupErr != nil {
+					err = lookupErr
+				}
+				return price
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(recorder.queried())/float64(b.N), "queries/op")
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		recorder := newRecordingDb(b, priceRows...)
+		utils.Config.Chain.Config.DepositChainID = 1
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			prices, err := getHistoricalPricesForDays("USD", history[0].Day, history[len(history)-1].Day)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = incomeHistoryChartSeriesWithRates(history, func(day int64) float64 {
+				return prices[day]
+			})
+		}
+		b.ReportMetric(float64(recorder.queried())/float64(b.N), "queries/op")
+	})
+}
+
+func TestCombinedIncomeHistoryChartSeries(t *testing.T) {
+	utils.Config = &types.Config{}
+
+	history := []types.ValidatorIncomeHistory{
+		// a 123.456789012345678901 ETH mev reward must not lose its gwei precision
+		{Day: 1, ClRewards: 2_500_000, ElRewards: decimal.RequireFromString("4000000000000000"), MevRewards: decimal.RequireFromString("123456789012345678901")},
+		{Day: 2, ClRewards: -3_000_000, MevRewards: decimal.RequireFromString("4000000000000000")},
+		{Day: 3, ClRewards: -3_000_000},
+	}
+
+	if total := combinedIncomeGwei(history[0]); !total.Equal(decimal.RequireFromString("123459289012.345678901")) {
+		t.Errorf("expected a combined income of 123459289012.345678901 gwei, got %v", total)
+	}
+
+	series := combinedIncomeHistoryChartSeries(history, 2)
+	expected := []struct {
+		y     float64
+		color string
+	}{
+		{246.918578024691357802, "#7cb5ec"},
+		{0.002, "#7cb5ec"},
+		{-0.006, "#f7a35c"},
+	}
+	for i, e := range expected {
+		if math.Abs(series[i].Y-e.y) > 1e-9 {
+			t.Errorf("expected a combined income of %v on day %v, got %v", e.y, history[i].Day, series[i].Y)
+		}
+		if series[i].Color != e.color {
+			t.Errorf("expected color %v on day %v, got %v", e.color, history[i].Day, series[i].Color)
+		}
+	}
+}
+
+func TestIncomeHistoryUpToDay(t *testing.T) {
+	// day 12 has rows of its completed sub-exports, but its status is not set yet
+	history := []types.ValidatorIncomeHistory{{Day: 10, ClRewards: 100}, {Day: 11, ClRewards: 110}, {Day: 12, ClRewards: -32_000_000_000}}
+
+	result := incomeHistoryUpToDay(history, 11)
+	if len(result) != 2 || result[len(result)-1].Day != 11 {
+		t.Errorf("expected the partially exported day 12 to be dropped, got %+v", result)
+	}
+
+	result = appendCurrentDayIncome(result, types.ValidatorIncomeHistory{Day: 12, ClRewards: 120})
+	if len(result) != 3 || result[2].ClRewards != 120 || history[2].ClRewards != -32_000_000_000 {
+		t.Errorf("expected the estimate to replace the partially exported day without modifying the history, got %+v", result)
+	}
+
+	if result := incomeHistoryUpToDay(history, 12); len(result) != 3 {
+		t.Errorf("expected all days to be kept, got %+v", result)
+	}
+}
+
+func TestAppendCurrentDayIncome(t *testing.T) {
+	cached := make([]types.ValidatorIncomeHistory, 2, 10)
+	cached[0] = types.ValidatorIncomeHistory{Day: 1, ClRewards: 100}
+	cached[1] = types.ValidatorIncomeHistory{Day: 2, ClRewards: 110}
+
+	first := appendCurrentDayIncome(cached, types.ValidatorIncomeHistory{Day: 3, ClRewards: 20})
+	second := appendCurrentDayIncome(cached, types.ValidatorIncomeHistory{Day: 3, ClRewards: 45})
+
+	if len(cached) != 2 || cached[:cap(cached)][2].Day != 0 {
+		t.Errorf("expected the cached finalized history to be left untouched, got %+v", cached)
+	}
+	if len(first) != 3 || first[2].ClRewards != 20 {
+		t.Errorf("expected the first call to contain the current day estimate of 20, got %+v", first)
+	}
+	if len(second) != 3 || second[2].ClRewards != 45 {
+		t.Errorf("expected the second call to reflect the newer current day estimate of 45, got %+v", second)
+	}
+	if first[2].ClRewards != 20 {
+		t.Errorf("expected the first result not to be changed by the second call, got %+v", first)
+	}
+}
+
+func TestMarketCapForEmissionUsesConfiguredGenesisSupply(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Name = "holesky"
+	utils.Config.Chain.GenesisSupply = 1_000_000
+
+	// 500 ETH emitted, at a price of 2
+	got := marketCapForEmission(decimal.NewFromInt(500).Mul(decimal.NewFromInt(1e18)), 2)
+	if want := decimal.NewFromInt(2_001_000); !got.Equal(want) {
+		t.Errorf("expected market cap %v for a genesis supply of 1000000, got %v", want, got)
+	}
+
+	// chains without a configured genesis supply use the one of mainnet
+	utils.Config.Chain.GenesisSupply = 0
+	got = marketCapForEmission(decimal.NewFromInt(500).Mul(decimal.NewFromInt(1e18)), 2)
+	if want := decimal.RequireFromString("144020981"); !got.Equal(want) {
+		t.Errorf("expected market cap %v for the mainnet genesis supply, got %v", want, got)
+	}
+}
+
+func TestExcludedValidatorsCondition(t *testing.T) {
+	condition, args := excludedValidatorsCondition(nil, 2)
+	if condition != "" || len(args) != 0 {
+		t.Errorf("expected no condition without excluded validators, got %q %v", condition, args)
+	}
+
+	condition, args = excludedValidatorsCondition([]uint64{5, 7}, 2)
+	if condition != " AND NOT (validatorindex = ANY($2))" {
+		t.Errorf("unexpected condition %q", condition)
+	}
+	if len(args) != 1 {
+		t.Fatalf("expected a single argument, got %v", args)
+	}
+	value, err := args[0].(driver.Valuer).Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "{5,7}" {
+		t.Errorf("expected the excluded validators as argument, got %v", value)
+	}
+}
+
+func TestRunningChartSeriesTotal(t *testing.T) {
+	recorder := newRecordingDb(t)
+
+	// the first day after capella has no previous total
+	firstDay := time.Date(2023, 4, 12, 0, 0, 0, 0, time.UTC)
+	total, err := runningChartSeriesTotal("TOTAL_WITHDRAWN", firstDay, decimal.NewFromInt(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !total.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected the first total to be the withdrawn amount of the day, got %v", total)
+	}
+
+	// the next day adds to the total stored for the first day
+	recorder.results = []recordingResult{
+		{contains: "FROM chart_series", columns: []string{"value"}, row: []driver.Value{total.String()}},
+	}
+	total, err = runningChartSeriesTotal("TOTAL_WITHDRAWN", firstDay.AddDate(0, 0, 1), decimal.NewFromInt(7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !total.Equal(decimal.NewFromInt(12)) {
+		t.Errorf("expected a total of 12 ETH after two days, got %v", total)
+	}
+
+	// the first exported day has no previous TOTAL_EMISSION, the emission of a day can be negative
+	recorder.results = nil
+	total, err = runningChartSeriesTotal("TOTAL_EMISSION", time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), decimal.NewFromInt(-3_000_000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !total.Equal(decimal.NewFromInt(-3_000_000)) {
+		t.Errorf("expected the first TOTAL_EMISSION to be the emission of the day, got %v", total)
+	}
+}
+
+func TestWriteStakingChartSeries(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{contains: "activationepoch", columns: []string{"active_validators", "total_staked"}, row: []driver.Value{int64(3), int64(96e9)}},
+	)
+
+	condition, args := excludedValidatorsCondition([]uint64{7}, 2)
+	if err := writeStakingChartSeries(time.Date(2023, 4, 12, 0, 0, 0, 0, time.UTC), 860, 193724, condition, args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values := map[string]string{}
+	for _, args := range recorder.args {
+		values[fmt.Sprintf("%s", args[1])] = fmt.Sprintf("%v", args[2])
+	}
+	if values["ACTIVE_VALIDATORS"] != "3" {
+		t.Errorf("expected 3 active validators, got %v", values["ACTIVE_VALIDATORS"])
+	}
+	if values["TOTAL_STAKED"] != "96" {
+		t.Errorf("expected a total of 96 ETH staked, got %v", values["TOTAL_STAKED"])
+	}
+}
+
+func TestWriteWithdrawalChartSeries(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{contains: "withdrawals_amount", columns: []string{"withdrawals", "withdrawals_amount"}, row: []driver.Value{int64(2), int64(5_500_000_000)}},
+	)
+
+	if err := writeWithdrawalChartSeries(time.Date(2023, 4, 12, 0, 0, 0, 0, time.UTC), 860, "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values := map[string]string{}
+	for _, args := range recorder.args {
+		values[fmt.Sprintf("%s", args[1])] = fmt.Sprintf("%v", args[2])
+	}
+	if values["WITHDRAWALS_COUNT"] != "2" {
+		t.Errorf("expected 2 withdrawals, got %v", values["WITHDRAWALS_COUNT"])
+	}
+	if values["TOTAL_WITHDRAWN"] != "5.5" {
+		t.Errorf("expected a total of 5.5 ETH withdrawn, got %v", values["TOTAL_WITHDRAWN"])
+	}
+}
+
+func TestClIssuance(t *testing.T) {
+	validatorClRewards := []int64{2_512_345, 2_498_001, -1_800_000, 3_000_000_000}
+	dayClRewards := int64(0)
+	for _, r := range validatorClRewards {
+		dayClRewards += r
+	}
+
+	want := decimal.RequireFromString("3.003210346")
+	if got := clIssuanceEth(dayClRewards); !got.Equal(want) {
+		t.Errorf("expected CL_ISSUANCE of %v ETH, got %v", want, got)
+	}
+
+	burned := decimal.RequireFromString("1500000000000000000") // 1.5 ETH
+	if got := netIssuanceEth(dayClRewards, burned); !got.Equal(want.Sub(decimal.RequireFromString("1.5"))) {
+		t.Errorf("expected NET_ISSUANCE of %v ETH, got %v", want.Sub(decimal.RequireFromString("1.5")), got)
+	}
+}
+
+func TestTxFeeStatistics(t *testing.T) {
+	// gas used * gas price of the transactions of a day, in wei
+	fees := []int64{21_000 * 20e9, 21_000 * 25e9, 50_000 * 30e9, 120_000 * 22e9, 21_000 * 18e9}
+
+	totalTxFees := decimal.Zero
+	quantiles := newTxFeeQuantiles()
+	for _, fee := range fees {
+		totalTxFees = totalTxFees.Add(decimal.NewFromInt(fee))
+		quantiles.add(decimal.NewFromInt(fee))
+	}
+
+	avg, ok := averageTxFee(totalTxFees, int64(len(fees)))
+	if want := decimal.NewFromInt(1_092_600_000_000_000); !ok || !avg.Equal(want) {
+		t.Errorf("expected an average tx fee of %v, got %v (ok: %v)", want, avg, ok)
+	}
+	if _, ok := averageTxFee(decimal.Zero, 0); ok {
+		t.Errorf("expected no average tx fee for a day without transactions")
+	}
+
+	// the median is the fee of the third transaction, 525000000000000 wei
+	median, _ := quantiles.quantile(0.5).Float64()
+	if math.Abs(median/525e12-1) > 0.045 {
+		t.Errorf("expected a median tx fee of about 525000000000000, got %v", median)
+	}
+	if q := newTxFeeQuantiles().quantile(0.5); !q.IsZero() {
+		t.Errorf("expected a median of zero without transactions, got %v", q)
+	}
+}
+
+func TestGasPricePercentiles(t *testing.T) {
+	gwei := func(v int64) decimal.Decimal {
+		return decimal.NewFromInt(v * 1e9)
+	}
+	baseFee := gwei(20)
+	txs := []struct {
+		txType   uint32
+		gasPrice decimal.Decimal
+		maxFee   decimal.Decimal
+		prioFee  decimal.Decimal
+		want     decimal.Decimal
+	}{
+		{txType: 0, gasPrice: gwei(25), want: gwei(25)},
+		{txType: 1, gasPrice: gwei(30), want: gwei(30)},
+		{txType: 2, maxFee: gwei(100), prioFee: gwei(2), want: gwei(22)},
+		// the priority fee is capped by the max fee
+		{txType: 2, maxFee: gwei(21), prioFee: gwei(5), want: gwei(21)},
+		{txType: 2, maxFee: gwei(50), prioFee: gwei(1), want: gwei(21)},
+	}
+
+	quantiles := newTxFeeQuantiles()
+	for i := 0; i < 20; i++ {
+		for _, tx := range txs {
+			gasPrice := effectiveGasPrice(tx.txType, tx.gasPrice, baseFee, tx.maxFee, tx.prioFee)
+			if !gasPrice.Equal(tx.want) {
+				t.Fatalf("expected an effective gas price of %v for a type %v tx, got %v", tx.want, tx.txType, gasPrice)
+			}
+			quantiles.add(gasPrice)
+		}
+	}
+
+	// the sorted gas prices are 21, 21, 22, 25 and 30 gwei, 20 times each
+	for p, want := range map[float64]float64{0.1: 21e9, 0.5: 22e9, 0.9: 30e9} {
+		got, _ := quantiles.quantile(p).Float64()
+		if math.Abs(got/want-1) > 0.045 {
+			t.Errorf("expected a p%v gas price of about %v, got %v", p*100, want, got)
+		}
+	}
+}
+
+func TestStakingAPR(t *testing.T) {
+	tests := []struct {
+		name                   string
+		dayClRewards           int64
+		activeEffectiveBalance int64
+		want                   decimal.Decimal
+	}{
+		{"regular day", 1_000, 3_650_000, decimal.NewFromInt(10)},
+		{"negative rewards", -1_000, 3_650_000, decimal.NewFromInt(-10)},
+		{"no active balance", 1_000, 0, decimal.Zero},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stakingAPR(tt.dayClRewards, tt.activeEffectiveBalance); !got.Equal(tt.want) {
+				t.Errorf("stakingAPR(%v, %v) = %v, want %v", tt.dayClRewards, tt.activeEffectiveBalance, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportProgressReporterDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan int, 1000)
+	SetExportProgressReporter(func(stage string, done, total int) {
+		<-release
+		received <- done
+	})
+	defer SetExportProgressReporter(nil)
+
+	progress := newExportProgress("test")
+	finished := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			progress.batchScheduled()
+			progress.batchCompleted()
+		}
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the export not to be stalled by a blocked progress reporter")
+	}
+
+	close(release)
+	select {
+	case done := <-received:
+		if done < 1 || done > 1000 {
+			t.Errorf("expected a done count between 1 and 1000, got %v", done)
+		}
+	case <-time.After(time.Second * 5):
+		t.Error("expected the progress reporter to be called")
+	}
+}
+
+func TestFailedAttestationsEpochBatchSize(t *testing.T) {
+	utils.Config = &types.Config{}
+
+	b := newFailedAttestationsEpochBatchSize()
+	b.observe(time.Hour, errors.New("boom"))
+	if got := b.get(); got != 2 {
+		t.Errorf("expected the default batch size of 2 to be kept outside adaptive mode, got %v", got)
+	}
+
+	utils.Config.Statistics.FailedAttestationsEpochBatchSize = 8
+	utils.Config.Statistics.FailedAttestationsAdaptiveBatchSize = true
+	utils.Config.Statistics.FailedAttestationsBatchLatencyThreshold = time.Second
+
+	b = newFailedAttestationsEpochBatchSize()
+	b.observe(time.Millisecond, nil)
+	if got := b.get(); got != 8 {
+		t.Errorf("expected a fast call to keep the batch size at 8, got %v", got)
+	}
+	b.observe(time.Second*2, nil)
+	if got := b.get(); got != 4 {
+		t.Errorf("expected a slow call to halve the batch size to 4, got %v", got)
+	}
+	b.observe(time.Millisecond, errors.New("boom"))
+	b.observe(time.Millisecond, errors.New("boom"))
+	b.observe(time.Millisecond, errors.New("boom"))
+	if got := b.get(); got != 1 {
+		t.Errorf("expected errors to reduce the batch size to no less than 1, got %v", got)
+	}
+}
+
+func TestWriteValidatorBalancesObservesExportedRows(t *testing.T) {
+	recorder := newRecordingDb(t)
+	utils.Config.Statistics.BalancesBatchSize = 2
+	BigtableClient = newEmptyBigtable(t)
+
+	validators := make([]*types.Validator, 0, 5)
+	for i := uint64(0); i < 5; i++ {
+		validators = append(validators, &types.Validator{Index: i, Balance: 32e9 + i, EffectiveBalance: 32e9})
+	}
+	if err := BigtableClient.SaveValidatorBalances(2250, validators); err != nil {
+		t.Fatalf("error saving balances: %v", err)
+	}
+
+	before := testutil.ToFloat64(metrics.RowsExported.WithLabelValues("balances"))
+	if err := WriteValidatorBalances(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	batches := 0
+	for _, stmt := range recorder.executed() {
+		if strings.Contains(stmt, "insert into validator_stats (validatorindex, day, min_balance") {
+			batches++
+		}
+	}
+	if batches != 3 {
+		t.Errorf("expected the 5 balances to be written in 3 batches, got %v", batches)
+	}
+	if got := testutil.ToFloat64(metrics.RowsExported.WithLabelValues("balances")) - before; got != 5 {
+		t.Errorf("expected the balances row counter to increase by 5, got %v", got)
+	}
+}
+
+// batchArgs returns the arguments of the executed statements containing the given substring, ordered by the argument at startArg
+func batchArgs(recorder *recordingDriver, contains string, startArg int) [][]driver.Value {
+	statements, args := recorder.executed(), recorder.executedArgs()
+	res := [][]driver.Value{}
+	for i, stmt := range statements {
+		if strings.Contains(stmt, contains) {
+			res = append(res, args[i])
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i][startArg].(int64) < res[j][startArg].(int64)
+	})
+	return res
+}
+
+func TestWriteValidatorCumulativeTotals(t *testing.T) {
+	exported := recordingResult{
+		contains: "last_cl_rewards_exported",
+		columns:  []string{"last_cl_rewards_exported", "last_el_rewards_exported", "cur_cl_rewards_exported", "cur_el_rewards_exported"},
+		row:      []driver.Value{true, true, true, true},
+	}
+	validators := recordingResult{contains: "max(validatorindex) + 1", columns: []string{"count"}, row: []driver.Value{int64(2500)}}
+	recorder := newRecordingDb(t, exported, validators)
+	utils.Config.Statistics.TotalPerformanceBatchSize = 1000
+
+	if err := WriteValidatorCumulativeTotals(100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, stmt := range recorder.executed() {
+		if !strings.Contains(stmt, "INSERT INTO validator_stats (validatorindex, day, cl_rewards_gwei_total") {
+			t.Errorf("expected the cumulative totals to only write the totals of validator_stats, got %v", stmt)
+		}
+	}
+	// day, first and last validator index (exclusive) of each batch
+	want := [][]driver.Value{{int64(100), int64(0), int64(1000)}, {int64(100), int64(1000), int64(2000)}, {int64(100), int64(2000), int64(2500)}}
+	if got := batchArgs(recorder, "cl_rewards_gwei_total", 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the batches %v, got %v", want, got)
+	}
+
+	// the totals of the previous day are required
+	exported.row = []driver.Value{false, true, true, true}
+	recorder = newRecordingDb(t, exported, validators)
+	if err := WriteValidatorCumulativeTotals(100); !errors.Is(err, ErrMissingDependency) {
+		t.Errorf("expected a missing dependency error, got %v", err)
+	}
+	if statements := recorder.executed(); len(statements) != 0 {
+		t.Errorf("expected nothing to be written without the cl rewards of the previous day, got %v", statements)
+	}
+}
+
+func TestWriteValidatorPerformanceTable(t *testing.T) {
+	recorder := newRecordingDb(t, recordingResult{contains: "max(validatorindex) + 1", columns: []string{"count"}, row: []driver.Value{int64(1500)}})
+	utils.Config.Statistics.TotalPerformanceBatchSize = 1000
+
+	if err := WriteValidatorPerformanceTable(100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statements := recorder.executed()
+	for _, stmt := range statements {
+		if strings.Contains(stmt, "INSERT INTO validator_stats") || strings.Contains(stmt, "rank7d=excluded.rank7d") {
+			t.Errorf("expected the rebuild to only write validator_performance and keep the existing ranks, got %v", stmt)
+		}
+	}
+	// the day, the days 1, 7, 31 and 365 days before it and the validator range of each batch
+	want := [][]driver.Value{
+		{int64(100), int64(99), int64(93), int64(69), int64(-265), int64(0), int64(1000)},
+		{int64(100), int64(99), int64(93), int64(69), int64(-265), int64(1000), int64(1500)},
+	}
+	if got := batchArgs(recorder, "insert into validator_performance", 5); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the batches %v, got %v", want, got)
+	}
+	if last := statements[len(statements)-1]; !strings.Contains(last, "update validator_performance") || !strings.Contains(last, "is distinct from ranked.rank7d") {
+		t.Errorf("expected the changed ranks to be updated after the batches, got %v", last)
+	}
+
+	// without ranks only the batches are written
+	computeRanks := false
+	recorder = newRecordingDb(t, recordingResult{contains: "max(validatorindex) + 1", columns: []string{"count"}, row: []driver.Value{int64(1500)}})
+	utils.Config.Statistics.ComputeRanks = &computeRanks
+	if err := WriteValidatorPerformanceTable(100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statements := recorder.executed(); len(statements) != 1 {
+		t.Errorf("expected a single batch without the rank update, got %v", statements)
+	}
+}
+
+func TestGenesisDayClRewards(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance genesisDayBalance
+		want    int64
+	}{
+		{
+			name:    "genesis validator",
+			balance: genesisDayBalance{EndBalance: 32_010_000_000, GenesisDepositsAmount: 32_000_000_000},
+			want:    10_000_000,
+		},
+		{
+			name:    "genesis validator with top-up on day 0",
+			balance: genesisDayBalance{EndBalance: 33_010_000_000, DepositsAmount: 1_000_000_000, GenesisDepositsAmount: 32_000_000_000},
+			want:    10_000_000,
+		},
+		{
+			name:    "validator deposited on day 0",
+			balance: genesisDayBalance{EndBalance: 32_000_000_000, DepositsAmount: 32_000_000_000},
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := genesisDayClRewards(&tt.balance); got != tt.want {
+				t.Errorf("genesisDayClRewards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivationDayClRewards(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance activationDayBalance
+		want    int64
+	}{
+		{
+			name:    "validator activated within the day",
+			balance: activationDayBalance{StartBalance: 32_000_000_000, EndBalance: 32_002_000_000, DepositsAmount: 32_000_000_000},
+			want:    2_000_000,
+		},
+		{
+			name:    "initial deposit included on the previous day",
+			balance: activationDayBalance{StartBalance: 32_000_000_000, EndBalance: 32_002_000_000},
+			want:    2_000_000,
+		},
+		{
+			name:    "initial deposit topped up within the day",
+			balance: activationDayBalance{StartBalance: 1_000_000_000, EndBalance: 32_000_000_000, DepositsAmount: 32_000_000_000},
+			want:    0,
+		},
+		{
+			name:    "penalized on the first day",
+			balance: activationDayBalance{StartBalance: 32_000_000_000, EndBalance: 31_999_000_000, DepositsAmount: 32_000_000_000},
+			want:    -1_000_000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := activationDayClRewards(&tt.balance); got != tt.want {
+				t.Errorf("activationDayClRewards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateProposerElRewards(t *testing.T) {
+	relayBlock := &types.Eth1BlockIndexed{Number: 100, Hash: []byte{0x01}, TxReward: big.NewInt(50).Bytes()}
+	localBlock := &types.Eth1BlockIndexed{Number: 101, Hash: []byte{0x02}, TxReward: big.NewInt(30).Bytes()}
+
+	bribe := types.WeiString{}
+	if err := bribe.Set("70"); err != nil {
+		t.Fatalf("error setting bribe: %v", err)
+	}
+	relaysData := map[common.Hash]types.RelaysData{
+		common.BytesToHash(relayBlock.Hash): {ExecBlockHash: relayBlock.Hash, MevBribe: bribe},
+	}
+
+	rewards := aggregateProposerElRewards([]*types.Eth1BlockIndexed{relayBlock, localBlock}, map[uint64]uint64{100: 1, 101: 2}, relaysData, nil)
+
+	if got := rewards[1]; got.MevReward.Int64() != 70 || got.TxFeeReward.Int64() != 50 || !got.HadRelayData {
+		t.Errorf("expected the relay block to use the relay bribe of 70 and have relay data, got mev: %v, tx fee: %v, had relay data: %v", got.MevReward, got.TxFeeReward, got.HadRelayData)
+	}
+	if got := rewards[2]; got.MevReward.Int64() != 30 || got.TxFeeReward.Int64() != 30 || got.HadRelayData {
+		t.Errorf("expected the local block to fall back to the tx fee of 30 without relay data, got mev: %v, tx fee: %v, had relay data: %v", got.MevReward, got.TxFeeReward, got.HadRelayData)
+	}
+}
+
+func TestAggregateProposerElRewardsBlockCounts(t *testing.T) {
+	bribe := types.WeiString{}
+	if err := bribe.Set("70"); err != nil {
+		t.Fatalf("error setting bribe: %v", err)
+	}
+
+	blocks := []*types.Eth1BlockIndexed{
+		{Number: 100, Hash: []byte{0x01}, TxReward: big.NewInt(50).Bytes()},
+		{Number: 101, Hash: []byte{0x02}, TxReward: big.NewInt(30).Bytes()},
+		{Number: 102, Hash: []byte{0x03}, TxReward: big.NewInt(20).Bytes()},
+		{Number: 103, Hash: []byte{0x04}, TxReward: big.NewInt(10).Bytes()},
+	}
+	relaysData := map[common.Hash]types.RelaysData{
+		common.BytesToHash(blocks[0].Hash): {ExecBlockHash: blocks[0].Hash, MevBribe: bribe},
+		common.BytesToHash(blocks[2].Hash): {ExecBlockHash: blocks[2].Hash, MevBribe: bribe},
+	}
+
+	rewards := aggregateProposerElRewards(blocks, map[uint64]uint64{100: 1, 101: 1, 102: 1, 103: 2}, relaysData, nil)
+
+	if got := rewards[1]; got.MevBlocks != 2 || got.LocalBlocks != 1 {
+		t.Errorf("expected 2 relay and 1 local block for proposer 1, got %v and %v", got.MevBlocks, got.LocalBlocks)
+	}
+	if share := rewards[1].MevShare(); share == nil || math.Abs(*share-2.0/3.0) > 1e-9 {
+		t.Errorf("expected a mev share of 2/3 for proposer 1, got %v", share)
+	}
+	if got := rewards[2]; got.MevBlocks != 0 || got.LocalBlocks != 1 {
+		t.Errorf("expected 0 relay and 1 local block for proposer 2, got %v and %v", got.MevBlocks, got.LocalBlocks)
+	}
+
+	if share := rewards[3].MevShare(); share != nil {
+		t.Errorf("expected no mev share for a validator without blocks, got %v", *share)
+	}
+	if share := (&proposerElRewards{}).MevShare(); share != nil {
+		t.Errorf("expected no mev share for a proposer with zero blocks, got %v", *share)
+	}
+}
+
+func TestAggregateOrphanedElRewards(t *testing.T) {
+	canonical := &types.Eth1BlockIndexed{Number: 100, Hash: []byte{0x01}, TxReward: big.NewInt(50).Bytes()}
+	reincluded := &types.Eth1BlockIndexed{Number: 101, Hash: []byte{0x02}, TxReward: big.NewInt(30).Bytes()}
+
+	// proposer 2 was orphaned at block 100 which was then built by proposer 1, proposer 3 was orphaned but its payload
+	// was included by the next proposer 4
+	orphaned := map[common.Hash]uint64{
+		common.BytesToHash([]byte{0x03}):    2,
+		common.BytesToHash(reincluded.Hash): 3,
+	}
+	orphanedRewards := aggregateOrphanedElRewards([]*types.Eth1BlockIndexed{canonical, reincluded}, orphaned)
+	canonicalRewards := aggregateProposerElRewards([]*types.Eth1BlockIndexed{canonical, reincluded}, map[uint64]uint64{100: 1, 101: 4}, nil, nil)
+
+	if _, ok := orphanedRewards[2]; ok {
+		t.Errorf("expected no orphaned rewards for a payload that is not indexed, got %v", orphanedRewards[2])
+	}
+	if got := orphanedRewards[3]; got == nil || got.Int64() != 30 {
+		t.Errorf("expected orphaned rewards of 30 for proposer 3, got %v", got)
+	}
+	if got := orphanedRewards[1]; got != nil {
+		t.Errorf("expected canonical blocks not to count as orphaned rewards, got %v", got)
+	}
+	for _, proposer := range []uint64{2, 3} {
+		if got := canonicalRewards[proposer]; got != nil {
+			t.Errorf("expected no canonical el rewards for the orphaned proposer %v, got %v", proposer, got.TxFeeReward)
+		}
+	}
+	if got := canonicalRewards[1].TxFeeReward.Int64(); got != 50 {
+		t.Errorf("expected canonical el rewards of 50 for proposer 1, got %v", got)
+	}
+}
+
+func TestSaveElRewardsBatch(t *testing.T) {
+	recorder := newRecordingDb(t)
+
+	proposerRewards := map[uint64]*proposerElRewards{
+		1: {TxFeeReward: big.NewInt(50), MevReward: big.NewInt(0), LocalBlocks: 1},
+		4: {TxFeeReward: big.NewInt(30), MevReward: big.NewInt(70), HadRelayData: true, MevBlocks: 1},
+	}
+	orphanedRewards := map[uint64]*big.Int{3: big.NewInt(30)}
+
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := saveElRewardsBatch(tx, []uint64{1, 4}, proposerRewards, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := saveOrphanedElRewardsBatch(tx, []uint64{3}, orphanedRewards, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]driver.Value{
+		{int64(1), int64(10), "50", "0", false, int64(0), int64(1), int64(4), int64(10), "30", "70", true, int64(1), int64(0)},
+		{int64(3), int64(10), "30"},
+	}
+	if args := recorder.executedArgs(); !reflect.DeepEqual(args, want) {
+		t.Errorf("expected the args %v, got %v", want, args)
+	}
+	if statements := recorder.executed(); len(statements) != 2 || !strings.Contains(statements[0], "($8, $9, $10, $11, $12, $13, $14)") {
+		t.Errorf("expected a row of 7 parameters per proposer, got %v", statements)
+	}
+
+	// a day with more proposers than the parameter limit allows per statement is split into batches
+	if batchSize := insertBatchSize("el_rewards", 20000, 1000, elRewardsNumArgs); batchSize*elRewardsNumArgs > maxQueryParameters {
+		t.Errorf("expected the el rewards batches to stay below the parameter limit, got %v rows", batchSize)
+	}
+}
+
+func TestAggregateProposerElRewardsOverrides(t *testing.T) {
+	utils.Config = &types.Config{}
+	relayBlock := &types.Eth1BlockIndexed{Number: 100, Hash: common.HexToHash("0x01").Bytes(), TxReward: big.NewInt(50).Bytes()}
+	overriddenBlock := &types.Eth1BlockIndexed{Number: 101, Hash: common.HexToHash("0x02").Bytes(), TxReward: big.NewInt(30).Bytes()}
+	localBlock := &types.Eth1BlockIndexed{Number: 102, Hash: common.HexToHash("0x03").Bytes(), TxReward: big.NewInt(20).Bytes()}
+
+	bribe := types.WeiString{}
+	if err := bribe.Set("70"); err != nil {
+		t.Fatalf("error setting bribe: %v", err)
+	}
+	relaysData := map[common.Hash]types.RelaysData{
+		common.BytesToHash(relayBlock.Hash): {ExecBlockHash: relayBlock.Hash, MevBribe: bribe},
+	}
+
+	// the override of the relay block is ignored as relay data takes precedence
+	utils.Config.Statistics.MevBribeOverrides = map[string]string{
+		common.BytesToHash(relayBlock.Hash).Hex():      "1000",
+		common.BytesToHash(overriddenBlock.Hash).Hex(): "90",
+	}
+	overrides, err := mevBribeOverrides()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rewards := aggregateProposerElRewards([]*types.Eth1BlockIndexed{relayBlock, overriddenBlock, localBlock}, map[uint64]uint64{100: 1, 101: 2, 102: 3}, relaysData, overrides)
+	tests := []struct {
+		proposer     uint64
+		mevReward    int64
+		hadRelayData bool
+	}{
+		{1, 70, true},
+		{2, 90, true},
+		{3, 20, false},
+	}
+	for _, tt := range tests {
+		if got := rewards[tt.proposer]; got.MevReward.Int64() != tt.mevReward || got.HadRelayData != tt.hadRelayData {
+			t.Errorf("expected a mev reward of %v (had relay data: %v) for proposer %v, got %v (%v)", tt.mevReward, tt.hadRelayData, tt.proposer, got.MevReward, got.HadRelayData)
+		}
+	}
+
+	utils.Config.Statistics.MevBribeOverrides = map[string]string{"0x1234": "90"}
+	if _, err := mevBribeOverrides(); err == nil {
+		t.Errorf("expected an error for an invalid block hash")
+	}
+	utils.Config.Statistics.MevBribeOverrides = map[string]string{common.BytesToHash(overriddenBlock.Hash).Hex(): "0.5"}
+	if _, err := mevBribeOverrides(); err == nil {
+		t.Errorf("expected an error for an invalid bribe")
+	}
+}
+
+func TestGroupIncomeHistoryByValidator(t *testing.T) {
+	rows := []*validatorIncomeHistoryRow{}
+	for _, validator := range []uint64{1, 2, 3} {
+		for _, day := range []int64{10, 11} {
+			rows = append(rows, &validatorIncomeHistoryRow{
+				ValidatorIndex:         validator,
+				ValidatorIncomeHistory: types.ValidatorIncomeHistory{Day: day, ClRewards: int64(validator)*100 + day},
+			})
+		}
+	}
+
+	result := groupIncomeHistoryByValidator(rows)
+	if len(result) != 3 {
+		t.Fatalf("expected the history of 3 validators, got %v", len(result))
+	}
+	for _, validator := range []uint64{1, 2, 3} {
+		history := result[validator]
+		if len(history) != 2 {
+			t.Fatalf("expected 2 days for validator %v, got %v", validator, len(history))
+		}
+		for i, day := range []int64{10, 11} {
+			if history[i].Day != day || history[i].ClRewards != int64(validator)*100+day {
+				t.Errorf("unexpected income of validator %v on day %v: %+v", validator, day, history[i])
+			}
+		}
+	}
+}
+
+func TestEstimateCurrentDayClRewards(t *testing.T) {
+	tests := []struct {
+		name            string
+		currentBalances map[uint64]uint64
+		lastBalances    map[uint64]uint64
+		deposits        map[uint64]uint64
+		withdrawals     map[uint64]uint64
+		want            int64
+	}{
+		{
+			name:            "normal validator",
+			currentBalances: map[uint64]uint64{1: 32_001_000_000},
+			lastBalances:    map[uint64]uint64{1: 32_000_000_000},
+			want:            1_000_000,
+		},
+		{
+			name:            "normal validator with a partial withdrawal",
+			currentBalances: map[uint64]uint64{1: 32_000_100_000},
+			lastBalances:    map[uint64]uint64{1: 32_001_000_000},
+			withdrawals:     map[uint64]uint64{1: 1_000_000},
+			want:            100_000,
+		},
+		{
+			name:            "exited validator without balance",
+			currentBalances: map[uint64]uint64{1: 32_001_000_000},
+			lastBalances:    map[uint64]uint64{1: 32_000_000_000, 2: 32_000_000_000},
+			want:            1_000_000,
+		},
+		{
+			name:            "exited validator that has been fully withdrawn",
+			currentBalances: map[uint64]uint64{1: 32_001_000_000},
+			lastBalances:    map[uint64]uint64{1: 32_000_000_000, 2: 32_000_000_000},
+			withdrawals:     map[uint64]uint64{2: 32_000_500_000},
+			want:            1_500_000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateCurrentDayClRewards([]uint64{1, 2}, tt.currentBalances, tt.lastBalances, tt.deposits, tt.withdrawals); got != tt.want {
+				t.Errorf("estimateCurrentDayClRewards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendCurrentDayEffectiveBalance(t *testing.T) {
+	history := make([]types.ValidatorEffectiveBalanceHistory, 2, 3)
+	history[0] = types.ValidatorEffectiveBalanceHistory{Day: 8, EffectiveBalance: 64e9}
+	history[1] = types.ValidatorEffectiveBalanceHistory{Day: 9, EffectiveBalance: 63e9}
+	balances := map[uint64][]*types.ValidatorBalance{
+		1: {{Index: 1, Balance: 32_010_000_000, EffectiveBalance: 32e9}},
+		2: {{Index: 2, Balance: 31_400_000_000, EffectiveBalance: 31e9}},
+		// validators without a balance at the epoch are not counted
+		3: {},
+	}
+
+	result := appendCurrentDayEffectiveBalance(history, 10, balances)
+	want := []types.ValidatorEffectiveBalanceHistory{
+		{Day: 8, EffectiveBalance: 64e9},
+		{Day: 9, EffectiveBalance: 63e9},
+		{Day: 10, EffectiveBalance: 63e9},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("expected %v, got %v", want, result)
+	}
+
+	// the cached history must not be modified through its spare capacity
+	result[2].EffectiveBalance = 0
+	if extended := history[:3]; extended[2].EffectiveBalance != 0 || extended[2].Day != 0 {
+		t.Errorf("expected the cached history to be left untouched, got %v", extended[2])
+	}
+}
+
+func TestFoldGenesisDeposits(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []types.ValidatorDepositHistory
+		want    []types.ValidatorDepositHistory
+	}{
+		{
+			name:    "no deposits",
+			history: []types.ValidatorDepositHistory{},
+			want:    []types.ValidatorDepositHistory{},
+		},
+		{
+			name:    "no genesis deposits",
+			history: []types.ValidatorDepositHistory{{Day: 0, Deposits: 1, DepositsAmount: 32e9}, {Day: 3, Deposits: 1, DepositsAmount: 1e9}},
+			want:    []types.ValidatorDepositHistory{{Day: 0, Deposits: 1, DepositsAmount: 32e9}, {Day: 3, Deposits: 1, DepositsAmount: 1e9}},
+		},
+		{
+			name:    "genesis deposits only",
+			history: []types.ValidatorDepositHistory{{Day: -1, Deposits: 2, DepositsAmount: 64e9}, {Day: 5, Deposits: 1, DepositsAmount: 1e9}},
+			want:    []types.ValidatorDepositHistory{{Day: 0, Deposits: 2, DepositsAmount: 64e9, IncludesGenesisDeposits: true}, {Day: 5, Deposits: 1, DepositsAmount: 1e9}},
+		},
+		{
+			name:    "genesis deposits and deposits of day 0",
+			history: []types.ValidatorDepositHistory{{Day: -1, Deposits: 2, DepositsAmount: 64e9}, {Day: 0, Deposits: 1, DepositsAmount: 32e9}},
+			want:    []types.ValidatorDepositHistory{{Day: 0, Deposits: 3, DepositsAmount: 96e9, IncludesGenesisDeposits: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := foldGenesisDeposits(tt.history); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIncomeHistoryChartSeriesWithRates(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+
+	history := []types.ValidatorIncomeHistory{
+		{Day: 10, ClRewards: 2_000_000_000},
+		{Day: 11, ClRewards: -1_000_000_000},
+		{Day: 12, ClRewards: 1_000_000_000},
+	}
+	prices := map[int64]float64{10: 1000, 11: 1500}
+	series := incomeHistoryChartSeriesWithRates(history, func(day int64) float64 {
+		if price, ok := prices[day]; ok {
+			return price
+		}
+		return 2000
+	})
+
+	expected := []float64{2000, -1500, 2000}
+	for i, point := range series {
+		if point.Y != expected[i] {
+			t.Errorf("day %v: expected %v, got %v", history[i].Day, expected[i], point.Y)
+		}
+		if x := float64(utils.DayToTime(history[i].Day).Unix() * 1000); point.X != x {
+			t.Errorf("day %v: expected x of %v, got %v", history[i].Day, x, point.X)
+		}
+	}
+	if series[1].Color != "#f7a35c" {
+		t.Errorf("expected negative rewards to be highlighted, got color %v", series[1].Color)
+	}
+}
+
+func TestClCurrencyScaling(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1638993340
+	// gnosis chain, balances are in mGNO
+	utils.Config.Chain.Config.ClCurrencyDivisor = 32e9
+	defer func() {
+		utils.Config.Chain.Config.ClCurrencyDivisor = 0
+	}()
+
+	if got := clIssuanceEth(64e9); !got.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("expected CL_ISSUANCE of 2 GNO, got %v", got)
+	}
+
+	history := []types.ValidatorIncomeHistory{
+		{Day: 10, ClRewards: 16e9},
+		{Day: 11, ClRewards: -8e9},
+	}
+	series := incomeHistoryChartSeriesWithRates(history, func(day int64) float64 {
+		return 200
+	})
+	for i, want := range []float64{100, -50} {
+		if series[i].Y != want {
+			t.Errorf("day %v: expected %v, got %v", history[i].Day, want, series[i].Y)
+		}
+	}
+
+	combined := combinedIncomeHistoryChartSeries([]types.ValidatorIncomeHistory{{Day: 10, ClRewards: 32e9}}, 200)
+	if combined[0].Y != 200 {
+		t.Errorf("expected a combined income of 200, got %v", combined[0].Y)
+	}
+}
+
+func TestIncomeHistoryInCurrency(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+
+	// 1 ETH of cl rewards and 0.5 ETH of mev rewards
+	history := []types.ValidatorIncomeHistory{
+		{Day: 10, ClRewards: 1e9, ElRewards: decimal.NewFromInt(4e17), MevRewards: decimal.NewFromInt(5e17)},
+		{Day: 11, ClRewards: -2e9},
+	}
+
+	eth := incomeHistoryInCurrency(history, 1)
+	usd := incomeHistoryInCurrency(history, 2000)
+	if len(eth) != len(history) || len(usd) != len(history) {
+		t.Fatalf("expected a point per day, got %v and %v", len(eth), len(usd))
+	}
+	for i, want := range []float64{1.5, -2} {
+		if eth[i].Y != want {
+			t.Errorf("day %v: expected %v ETH, got %v", history[i].Day, want, eth[i].Y)
+		}
+		if usd[i].Y != want*2000 {
+			t.Errorf("day %v: expected %v USD, got %v", history[i].Day, want*2000, usd[i].Y)
+		}
+		if eth[i].X != usd[i].X {
+			t.Errorf("day %v: expected the same timestamp in both currencies, got %v and %v", history[i].Day, eth[i].X, usd[i].X)
+		}
+	}
+
+	if _, err := GetValidatorIncomeHistoryInCurrency([]uint64{1}, "XYZ", 0, 0, 0); err == nil {
+		t.Errorf("expected an error for an unsupported currency")
+	}
+}
+
+func TestStackedIncomeHistoryChartSeries(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+
+	// 2 ETH of cl rewards and 0.25 ETH of mev rewards, the el rewards are part of the mev rewards
+	history := []types.ValidatorIncomeHistory{
+		{Day: 10, ClRewards: 2e9, ElRewards: decimal.NewFromInt(1e17), MevRewards: decimal.NewFromInt(25e16)},
+	}
+
+	cl := incomeHistoryChartSeriesWithRates(history, func(day int64) float64 { return 1000 })
+	el := elIncomeHistoryChartSeries(history, 1000)
+	combined := combinedIncomeHistoryChartSeries(history, 1000)
+
+	if cl[0].Y != 2000 {
+		t.Errorf("expected cl income of 2000, got %v", cl[0].Y)
+	}
+	if el[0].Y != 250 {
+		t.Errorf("expected el income of 250, got %v", el[0].Y)
+	}
+	if combined[0].Y != 2250 || cl[0].Y+el[0].Y != combined[0].Y {
+		t.Errorf("expected the stacked series to add up to the combined income of 2250, got %v + %v and %v", cl[0].Y, el[0].Y, combined[0].Y)
+	}
+}
+
+func TestWriteValidatorStatsCSV(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+
+	fixture := []*validatorStatsCSVRow{
+		{ValidatorIndex: 5, Day: 10, ClRewards: 10_000_000, ElRewards: decimal.RequireFromString("20000000000000000"), MevRewards: decimal.RequireFromString("30000000000000000"), Withdrawals: 1_000_000_000, EndBalance: 32_000_000_000},
+		{ValidatorIndex: 5, Day: 11, ClRewards: -1_000_000, EndBalance: 31_999_000_000},
+	}
+	next := func() (*validatorStatsCSVRow, error) {
+		if len(fixture) == 0 {
+			return nil, nil
+		}
+		row := fixture[0]
+		fixture = fixture[1:]
+		return row, nil
+	}
+
+	var buf strings.Builder
+	if err := writeValidatorStatsCSV(&buf, next, "USD", 2000, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "day,date,validator,cl_rewards_eth,el_rewards_eth,mev_rewards_eth,withdrawals_eth,deposits_eth,end_balance_eth,cl_rewards_usd,el_rewards_usd,mev_rewards_usd,end_balance_usd\n" +
+		"10,2020-12-11,5,0.01,0.02,0.03,1,0,32,20.00,40.00,60.00,64000.00\n" +
+		"11,2020-12-12,5,-0.001,0,0,0,0,31.999,-2.00,0.00,0.00,63998.00\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected csv output:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestWriteValidatorStatsParquet(t *testing.T) {
+	chunks := [][]types.ValidatorStatsRow{
+		{{ValidatorIndex: 1, Day: 10, EndBalance: 32_010_000_000, ClRewards: 10_000_000, ElRewards: decimal.Zero}},
+		// el rewards exceeding an int64
+		{{ValidatorIndex: 2, Day: 10, EndBalance: 31_990_000_000, ClRewards: -5_000_000, ElRewards: decimal.RequireFromString("40000000000000000000"), HadRelayData: true}},
+	}
+	stream := func(cb func(rows []types.ValidatorStatsRow) error) error {
+		for _, chunk := range chunks {
+			if err := cb(chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeValidatorStatsParquet(buf, stream); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pr, err := reader.NewParquetReader(buffer.NewBufferFileFromBytes(buf.Bytes()), new(validatorStatsParquetRow), 1)
+	if err != nil {
+		t.Fatalf("error reading parquet export: %v", err)
+	}
+	defer pr.ReadStop()
+
+	// the schema consists of all validator_stats columns in the order of types.ValidatorStatsRow, after the root element
+	statsRow := reflect.TypeOf(types.ValidatorStatsRow{})
+	if len(pr.Footer.Schema) != statsRow.NumField()+1 {
+		t.Fatalf("expected %v columns, got %v", statsRow.NumField(), len(pr.Footer.Schema)-1)
+	}
+	for i := 0; i < statsRow.NumField(); i++ {
+		if column, name := statsRow.Field(i).Tag.Get("db"), pr.Footer.Schema[i+1].Name; column != name {
+			t.Errorf("expected column %v to be %v, got %v", i, column, name)
+		}
+	}
+	if len(pr.Footer.RowGroups) != len(chunks) {
+		t.Errorf("expected a row group per chunk, got %v", len(pr.Footer.RowGroups))
+	}
+
+	rows := make([]validatorStatsParquetRow, pr.GetNumRows())
+	if err := pr.Read(&rows); err != nil {
+		t.Fatalf("error reading parquet rows: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %v", len(rows))
+	}
+	if rows[0].ValidatorIndex != 1 || rows[0].EndBalance != 32_010_000_000 || rows[0].ClRewards != 10_000_000 || rows[0].ElRewards != "0" || rows[0].HadRelayData {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+	if rows[1].ValidatorIndex != 2 || rows[1].Day != 10 || rows[1].ClRewards != -5_000_000 || rows[1].ElRewards != "40000000000000000000" || !rows[1].HadRelayData {
+		t.Errorf("unexpected second row %+v", rows[1])
+	}
+}
+
+// memoryExportLocker is an in-process exportLocker standing in for the postgres advisory locks
+type memoryExportLocker struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+func (l *memoryExportLocker) acquire(key string) (func(), chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if held, ok := l.locks[key]; ok {
+		return nil, held
+	}
+	released := make(chan struct{})
+	l.locks[key] = released
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.locks, key)
+		close(released)
+	}, nil
+}
+
+func (l *memoryExportLocker) lock(day uint64, export string) (func(), error) {
+	for {
+		unlock, held := l.acquire(fmt.Sprintf("%v:%v", export, day))
+		if unlock != nil {
+			return unlock, nil
+		}
+		<-held
+	}
+}
+
+func (l *memoryExportLocker) tryLock(day uint64, export string) (func(), bool, error) {
+	unlock, _ := l.acquire(fmt.Sprintf("%v:%v", export, day))
+	return unlock, unlock != nil, nil
+}
+
+func TestAcquireExportLockContention(t *testing.T) {
+	locker := &memoryExportLocker{locks: map[string]chan struct{}{}}
+
+	acquired := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		unlock, err := acquireExportLock(locker, "", 10, "balance")
+		if err != nil {
+			t.Errorf("expected the first instance to acquire the lock, got %v", err)
+			close(acquired)
+			return
+		}
+		close(acquired)
+		<-release
+		unlock()
+	}()
+	<-acquired
+
+	// a skipped export is reported, so the day is not taken as exported
+	_, err := acquireExportLock(locker, "", 10, "balance")
+	if !errors.Is(err, ErrStatisticsExportSkipped) || !errors.Is(err, ErrStatisticsExportLocked) {
+		t.Errorf("expected the second instance to skip the locked export with ErrStatisticsExportSkipped, got %v", err)
+	}
+	_, err = acquireExportLock(locker, "error", 10, "balance")
+	if !errors.Is(err, ErrStatisticsExportLocked) || errors.Is(err, ErrStatisticsExportSkipped) {
+		t.Errorf("expected ErrStatisticsExportLocked, got %v", err)
+	}
+	unlock, err := acquireExportLock(locker, "", 11, "balance")
+	if err != nil {
+		t.Errorf("expected the export of another day not to be locked, got %v", err)
+	} else {
+		unlock()
+	}
+
+	var waited sync.WaitGroup
+	waited.Add(1)
+	var releasedBeforeAcquired bool
+	var releasedMu sync.Mutex
+	releasedFlag := false
+	go func() {
+		defer waited.Done()
+		unlock, err := acquireExportLock(locker, "wait", 10, "balance")
+		if err != nil {
+			t.Errorf("expected the waiting instance to acquire the lock, got %v", err)
+			return
+		}
+		releasedMu.Lock()
+		releasedBeforeAcquired = releasedFlag
+		releasedMu.Unlock()
+		unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	releasedMu.Lock()
+	releasedFlag = true
+	releasedMu.Unlock()
+	close(release)
+	<-done
+	waited.Wait()
+
+	if !releasedBeforeAcquired {
+		t.Errorf("expected the waiting instance to acquire the lock only after the first instance released it")
+	}
+}
+
+func TestRunStatisticsExportHooks(t *testing.T) {
+	received := make(chan statisticsExportWebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload statisticsExportWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("error decoding webhook payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer server.Close()
+
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.SlotsPerEpoch = 32
+	utils.Config.Chain.Config.SecondsPerSlot = 12
+	utils.Config.Statistics.ExportWebhookURL = server.URL
+
+	var hookDay uint64
+	RegisterStatisticsExportHook(func(day uint64, duration time.Duration) {
+		hookDay = day
+	})
+	defer func() {
+		statisticsExportHooks.hooks = nil
+	}()
+
+	runStatisticsExportHooks(2, time.Now().Add(-time.Minute))
+
+	if hookDay != 2 {
+		t.Errorf("expected the registered hook to be called for day 2, got %v", hookDay)
+	}
+	select {
+	case payload := <-received:
+		if payload.Day != 2 || payload.FirstEpoch != 450 || payload.LastEpoch != 674 {
+			t.Errorf("unexpected webhook payload %+v", payload)
+		}
+		if payload.DurationMs < time.Minute.Milliseconds() || payload.CompletedAt-payload.StartedAt < 60 {
+			t.Errorf("expected the webhook payload to contain the export timings, got %+v", payload)
+		}
+	default:
+		t.Errorf("expected the webhook to be called")
+	}
+}
+
+func TestPostStatisticsExportWebhookTimeout(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	err := postStatisticsExportWebhook(server.URL, 50*time.Millisecond, &statisticsExportWebhookPayload{Day: 1})
+	if err == nil {
+		t.Errorf("expected an error for an endpoint exceeding the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the webhook to give up after the timeout, took %v", elapsed)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 1 {
+		t.Errorf("expected exactly one webhook call, got %v", calls)
+	}
+}
+
+// recordingDriver is a database/sql driver answering every COUNT query with the number of epochs per day, queries matching
+// one of the canned results with its row and every other query without rows. It records all statements that are executed.
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	results    []recordingResult
+	queries    int
+	queryArgs  [][]driver.Value
+}
+
+// recordingResult is a single row, or the rows if set, returned for all queries containing the given substring
+type recordingResult struct {
+	contains string
+	columns  []string
+	row      []driver.Value
+	rows     [][]driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) executed() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.statements...)
+}
+
+// queried returns the number of queries run
+func (d *recordingDriver) queried() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.queries
+}
+
+// queriedArgs returns the arguments of all queries in the order they were run
+func (d *recordingDriver) queriedArgs() [][]driver.Value {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([][]driver.Value{}, d.queryArgs...)
+}
+
+// executedArgs returns the arguments of all executed statements in the order they were executed
+func (d *recordingDriver) executedArgs() [][]driver.Value {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([][]driver.Value{}, d.args...)
+}
+
+var recordingDbCount uint64
+
+// newRecordingDb points WriterDb and ReaderDb to a new recordingDriver answering with the given results, replaces the export locker
+// by an in memory one and resets the config to a chain with 32 slots of 12 seconds per epoch. Everything, including BigtableClient,
+// is restored when the test ends.
+func newRecordingDb(t testing.TB, results ...recordingResult) *recordingDriver {
+	t.Helper()
+
+	recorder := &recordingDriver{results: results}
+	name := fmt.Sprintf("statistics_recording_%d", atomic.AddUint64(&recordingDbCount, 1))
+	sql.Register(name, recorder)
+	conn, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, writerDb, readerDb, bigtableClient, locker := utils.Config, WriterDb, ReaderDb, BigtableClient, statisticsExportLocker
+	t.Cleanup(func() {
+		conn.Close()
+		utils.Config, WriterDb, ReaderDb, BigtableClient, statisticsExportLocker = config, writerDb, readerDb, bigtableClient, locker
+	})
+
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.SlotsPerEpoch = 32
+	utils.Config.Chain.Config.SecondsPerSlot = 12
+	WriterDb = sqlx.NewDb(conn, "postgres")
+	ReaderDb = WriterDb
+	statisticsExportLocker = &memoryExportLocker{locks: map[string]chan struct{}{}}
+	return recorder
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{driver: c.driver, query: query}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *recordingConn) Commit() error {
+	return nil
+}
+
+func (c *recordingConn) Rollback() error {
+	return nil
+}
+
+type recordingStmt struct {
+	driver *recordingDriver
+	query  string
+}
+
+func (s *recordingStmt) Close() error {
+	return nil
+}
+
+func (s *recordingStmt) NumInput() int {
+	return -1
+}
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+	s.driver.statements = append(s.driver.statements, s.query)
+	s.driver.args = append(s.driver.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.mu.Lock()
+	s.driver.queries++
+	s.driver.queryArgs = append(s.driver.queryArgs, args)
+	s.driver.mu.Unlock()
+
+	for _, result := range s.driver.results {
+		if strings.Contains(s.query, result.contains) {
+			if result.rows != nil {
+				return &recordingRows{columns: result.columns, values: result.rows}, nil
+			}
+			return &recordingRows{columns: result.columns, values: [][]driver.Value{result.row}}, nil
+		}
+	}
+	if strings.Contains(strings.ToUpper(s.query), "COUNT(") {
+		return &recordingRows{columns: []string{"count"}, values: [][]driver.Value{{int64(utils.EpochsPerDay())}}}, nil
+	}
+	return &recordingRows{columns: []string{"count"}}, nil
+}
+
+type recordingRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *recordingRows) Columns() []string {
+	return r.columns
+}
+
+func (r *recordingRows) Close() error {
+	return nil
+}
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestDryRunDoesNotWrite(t *testing.T) {
+	recorder := newRecordingDb(t)
+	utils.Config.Statistics.DryRun = true
+
+	exports := map[string]func(day uint64) error{
+		"block stats":          WriteValidatorBlockStats,
+		"deposits/withdrawals": WriteValidatorDepositWithdrawals,
+		"slashing events":      WriteValidatorSlashingEventsForDay,
+		"total performance":    WriteValidatorTotalPerformance,
+		"withdrawal addresses": WriteValidatorWithdrawalAddressStatsForDay,
+		"network stats":        WriteNetworkStatsForDay,
+	}
+	for name, export := range exports {
+		if err := export(10); err != nil {
+			t.Errorf("%v: unexpected error: %v", name, err)
+		}
+	}
+
+	if statements := recorder.executed(); len(statements) != 0 {
+		t.Errorf("expected no statements to be executed in dry run mode, got %v", statements)
+	}
+}
+
+func TestWriteValidatorTotalPerformanceWithoutRanks(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{
+			contains: "last_cl_rewards_exported",
+			columns:  []string{"last_cl_rewards_exported", "last_el_rewards_exported", "cur_cl_rewards_exported", "cur_el_rewards_exported"},
+			row:      []driver.Value{true, true, true, true},
+		},
+		recordingResult{
+			contains: "max(validatorindex)",
+			columns:  []string{"count"},
+			row:      []driver.Value{int64(2500)},
+		},
+	)
+	computeRanks := false
+	utils.Config.Statistics.ComputeRanks = &computeRanks
+
+	if err := WriteValidatorTotalPerformance(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	performanceBatches, marked := 0, false
+	for _, stmt := range recorder.executed() {
+		switch {
+		case strings.Contains(stmt, "update validator_performance"):
+			t.Errorf("expected the rank update to be skipped, got %v", stmt)
+		case strings.Contains(stmt, "insert into validator_performance"):
+			performanceBatches++
+		case strings.Contains(stmt, "total_performance_exported"):
+			marked = true
+		}
+	}
+	if performanceBatches != 3 {
+		t.Errorf("expected 3 validator_performance batches, got %v", performanceBatches)
+	}
+	if !marked {
+		t.Errorf("expected total_performance_exported to be marked")
+	}
+}
+
+func TestGetFirstExportedStatisticDayWithoutExportedDays(t *testing.T) {
+	newRecordingDb(t)
+
+	if _, err := GetFirstExportedStatisticDay(); !errors.Is(err, ErrNoStatisticsExported) {
+		t.Errorf("expected ErrNoStatisticsExported, got %v", err)
+	}
+}
+
+func TestPreviewEpochRange(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.SlotsPerEpoch = 32
+	utils.Config.Chain.Config.SecondsPerSlot = 12
+
+	first, last, err := previewEpochRange(10, 10*225+100)
+	if err != nil || first != 10*225 || last != 10*225+100 {
+		t.Errorf("expected the finalized epochs %v - %v of the partial day, got %v - %v (err: %v)", 10*225, 10*225+100, first, last, err)
+	}
+
+	if _, last, _ := previewEpochRange(10, 20*225); last != 11*225-1 {
+		t.Errorf("expected the range to end at the last epoch of the day, got %v", last)
+	}
+
+	if _, _, err := previewEpochRange(10, 10*225-1); err == nil {
+		t.Errorf("expected an error for a day without finalized epochs")
+	}
+}
+
+func TestWriteValidatorStatsPreviewDoesNotMarkStatus(t *testing.T) {
+	recorder := newRecordingDb(t)
+
+	rows := []validatorStatsPreview{
+		{ValidatorIndex: 1, EndBalance: 32_001_000_000, ClRewardsNet: 1_000_000},
+		{ValidatorIndex: 2, EndBalance: 31_999_000_000, ClRewardsNet: -1_000_000},
+	}
+	if err := writeValidatorStatsPreview(10, 10*225+100, rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inserted := false
+	for _, statement := range recorder.executed() {
+		if strings.Contains(statement, "validator_stats_status") {
+			t.Errorf("expected the status table not to be touched by the preview, got %v", statement)
+		}
+		if strings.Contains(statement, "insert into validator_stats_preview") {
+			inserted = true
+		}
+	}
+	if !inserted {
+		t.Errorf("expected preview rows to be written, got %v", recorder.executed())
+	}
+}
+
+func TestAddMissedAttestationComponents(t *testing.T) {
+	res := map[uint64]*types.ValidatorAttestationComponentsStatistic{}
+	income := map[uint64]map[uint64]*itypes.ValidatorEpochIncome{
+		// fully missed attestation and a correct one
+		1: {
+			10: {AttestationSourcePenalty: 5, AttestationTargetPenalty: 9},
+			11: {AttestationSourceReward: 5, AttestationTargetReward: 9, AttestationHeadReward: 3},
+		},
+		// included on time but voted for the wrong target, which makes the head vote wrong as well
+		2: {
+			10: {AttestationSourceReward: 5, AttestationTargetPenalty: 9},
+		},
+		// only the head vote was wrong
+		3: {
+			10: {AttestationSourceReward: 5, AttestationTargetReward: 9},
+			11: {AttestationSourceReward: 5, AttestationTargetReward: 9},
+		},
+		// no attestation duty, e.g. a pending validator or a correct vote during an inactivity leak
+		4: {
+			10: {},
+			11: nil,
+		},
+		// during an inactivity leak no rewards are paid, a correct target vote without head reward is not a missed head vote
+		5: {
+			10: {AttestationSourcePenalty: 5, AttestationTargetPenalty: 9},
+			11: {AttestationTargetPenalty: 9},
+			12: {},
+		},
+	}
+
+	addMissedAttestationComponents(res, income)
+
+	expected := map[uint64]*types.ValidatorAttestationComponentsStatistic{
+		1: {Index: 1, MissedSource: 1, MissedTarget: 1, MissedHead: 1},
+		2: {Index: 2, MissedTarget: 1, MissedHead: 1},
+		3: {Index: 3, MissedHead: 2},
+		5: {Index: 5, MissedSource: 1, MissedTarget: 2, MissedHead: 2},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		for validator, stat := range res {
+			t.Logf("validator %v: %+v", validator, *stat)
+		}
+		t.Errorf("unexpected attestation component statistics")
+	}
+}
+
+func TestCopyFailedAttestationsMatchesBatchInsert(t *testing.T) {
+	recorder := newRecordingDb(t)
+
+	stats := []*types.ValidatorFailedAttestationsStatistic{
+		{Index: 1, MissedAttestations: 3, OrphanedAttestations: 1},
+		{Index: 7, MissedAttestations: 0, OrphanedAttestations: 2},
+		{Index: 42, MissedAttestations: 225},
+	}
+
+	if err := saveFailedAttestationBatch(stats, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.args) != 1 || len(recorder.args[0]) != len(stats)*4 {
+		t.Fatalf("expected a single insert of %v rows, got %v", len(stats), recorder.args)
+	}
+	batchRows := [][]driver.Value{}
+	for i := 0; i < len(recorder.args[0]); i += 4 {
+		batchRows = append(batchRows, recorder.args[0][i:i+4])
+	}
+
+	if err := copyFailedAttestations(stats, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	copyRows := [][]driver.Value{}
+	upserted := false
+	for i, stmt := range recorder.executed()[1:] {
+		args := recorder.args[i+1]
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(stmt), "COPY") && len(args) > 0:
+			copyRows = append(copyRows, args)
+		case strings.Contains(stmt, "from failed_attestations_import") && strings.Contains(stmt, "on conflict (validatorindex, day) do update"):
+			upserted = true
+		}
+	}
+	if !reflect.DeepEqual(copyRows, batchRows) {
+		t.Errorf("expected the copied rows to match the inserted rows %v, got %v", batchRows, copyRows)
+	}
+	if !upserted {
+		t.Errorf("expected the copied rows to be upserted into validator_stats, got %v", recorder.executed())
+	}
+}
+
+func TestAttestationEffectiveness(t *testing.T) {
+	percent := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name string
+		stat types.ValidatorAttestationInclusionStatistic
+		want *float64
+	}{
+		{
+			name: "no duties",
+			stat: types.ValidatorAttestationInclusionStatistic{},
+		},
+		{
+			name: "all included at optimal distance",
+			stat: types.ValidatorAttestationInclusionStatistic{IncludedAttestations: 225, InclusionDistanceSum: 225, MaxInclusionDistance: 1},
+			want: percent(100),
+		},
+		{
+			name: "missed attestations",
+			stat: types.ValidatorAttestationInclusionStatistic{IncludedAttestations: 200, InclusionDistanceSum: 250, MaxInclusionDistance: 4, FailedAttestations: 25},
+			want: percent(200.0 / 225.0 * 100 / 1.25),
+		},
+		{
+			name: "only missed attestations",
+			stat: types.ValidatorAttestationInclusionStatistic{FailedAttestations: 225},
+			want: percent(0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.stat.AttestationEffectiveness()
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected no effectiveness, got %v", *got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("expected effectiveness %v, got none", *tt.want)
+			}
+			if math.Abs(*got-*tt.want) > 1e-9 {
+				t.Errorf("expected effectiveness %v, got %v", *tt.want, *got)
+			}
+		})
+	}
+}
+
+func TestWriteValidatorInclusionDelayForDay(t *testing.T) {
+	recorder := newRecordingDb(t)
+	utils.Config.Statistics.BigtableMaxAttempts = 1
+	BigtableClient = newEmptyBigtable(t)
+
+	// validator 1 is included 1 and 3 slots after its attester slots, validator 2 misses its attestation
+	slotsPerEpoch := utils.Config.Chain.Config.SlotsPerEpoch
+	for epoch, validators := range map[uint64]map[string]uint64{
+		2251: {fmt.Sprintf("%d-0-0", 2251*slotsPerEpoch): 1, fmt.Sprintf("%d-0-1", 2251*slotsPerEpoch): 2},
+		2252: {fmt.Sprintf("%d-0-0", 2252*slotsPerEpoch): 1},
+	} {
+		if err := BigtableClient.SaveAttestationAssignments(epoch, validators); err != nil {
+			t.Fatalf("error saving attestation assignments: %v", err)
+		}
+	}
+	blocks := map[uint64]map[string]*types.Block{}
+	for _, attestation := range []struct{ attesterSlot, inclusionSlot uint64 }{
+		{2251 * slotsPerEpoch, 2251*slotsPerEpoch + 1},
+		{2252 * slotsPerEpoch, 2252*slotsPerEpoch + 3},
+	} {
+		blocks[attestation.inclusionSlot] = map[string]*types.Block{"a": {Slot: attestation.inclusionSlot, Attestations: []*types.Attestation{{Attesters: []uint64{1}, Data: &types.AttestationData{Slot: attestation.attesterSlot}}}}}
+	}
+	if err := BigtableClient.SaveAttestations(blocks); err != nil {
+		t.Fatalf("error saving attestations: %v", err)
+	}
+
+	if err := WriteValidatorInclusionDelayForDay(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reset, inserted, marked := -1, -1, -1
+	for i, stmt := range recorder.executed() {
+		switch {
+		case strings.Contains(stmt, "set avg_inclusion_delay = NULL"):
+			reset = i
+		case strings.Contains(stmt, "insert into validator_stats (validatorindex, day, avg_inclusion_delay"):
+			inserted = i
+			// the validator without an included attestation keeps NULL
+			if want := []driver.Value{int64(1), int64(10), float64(1), int64(2)}; !reflect.DeepEqual(recorder.executedArgs()[i], want) {
+				t.Errorf("expected the inclusion delays %v, got %v", want, recorder.executedArgs()[i])
+			}
+		case strings.Contains(stmt, "inclusion_delay_exported"):
+			marked = i
+		}
+	}
+	if reset < 0 || inserted < reset || marked < inserted {
+		t.Errorf("expected the day to be reset, inserted and marked in order, got %v, %v and %v", reset, inserted, marked)
+	}
+}
+
+func TestAggregateRelayStats(t *testing.T) {
+	blocks := []*types.Eth1BlockIndexed{
+		{Number: 100, Hash: common.HexToHash("0x01").Bytes(), TxReward: big.NewInt(5).Bytes()},
+		{Number: 101, Hash: common.HexToHash("0x02").Bytes(), TxReward: big.NewInt(5).Bytes()},
+		{Number: 102, Hash: common.HexToHash("0x03").Bytes(), TxReward: big.NewInt(5).Bytes()},
+		// built locally, not delivered by any relay
+		{Number: 103, Hash: common.HexToHash("0x04").Bytes(), TxReward: big.NewInt(5).Bytes()},
+	}
+	relayData := func(block *types.Eth1BlockIndexed, tag string, bribe string) types.RelaysData {
+		value := types.WeiString{}
+		if err := value.Set(bribe); err != nil {
+			t.Fatalf("error setting bribe: %v", err)
+		}
+		return types.RelaysData{ExecBlockHash: block.Hash, TagID: tag, MevBribe: value}
+	}
+	relaysData := map[common.Hash]types.RelaysData{
+		common.BytesToHash(blocks[0].Hash): relayData(blocks[0], "flashbots", "100"),
+		common.BytesToHash(blocks[1].Hash): relayData(blocks[1], "flashbots", "51"),
+		common.BytesToHash(blocks[2].Hash): relayData(blocks[2], "ultrasound", "70"),
+	}
+
+	stats := aggregateRelayStats(blocks, relaysData)
+	if len(stats) != 2 {
+		t.Fatalf("expected stats of 2 relays, got %v", len(stats))
+	}
+	tests := []struct {
+		relay     string
+		blocks    uint64
+		mevReward int64
+		avgBid    int64
+	}{
+		{"flashbots", 2, 151, 75},
+		{"ultrasound", 1, 70, 70},
+	}
+	for _, tt := range tests {
+		got := stats[tt.relay]
+		if got == nil {
+			t.Errorf("missing stats of relay %v", tt.relay)
+			continue
+		}
+		if got.Blocks != tt.blocks || got.MevReward.Int64() != tt.mevReward || got.AverageBid().Int64() != tt.avgBid {
+			t.Errorf("expected %v blocks, %v mev reward and %v average bid for relay %v, got %v, %v and %v", tt.blocks, tt.mevReward, tt.avgBid, tt.relay, got.Blocks, got.MevReward, got.AverageBid())
+		}
+	}
+}
+
+func TestInsertBatchSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured int
+		numArgs    int
+		want       int
+	}{
+		{"default", 0, 6, 10000},
+		{"negative uses default", -5, 6, 10000},
+		{"configured", 500, 6, 500},
+		{"at the parameter limit", 10922, 6, 10922},
+		{"clamped to the parameter limit", 13000, 6, 10922},
+		{"clamped with many parameters per row", 10000, 10, 6553},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := insertBatchSize("test", tt.configured, 10000, tt.numArgs); got != tt.want {
+				t.Errorf("insertBatchSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatisticsExportLag(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.GenesisTimestamp = 1606824023
+	utils.Config.Chain.Config.SlotsPerEpoch = 32
+	utils.Config.Chain.Config.SecondsPerSlot = 12
+
+	finalityDelay := 2 * 32 * 12 * time.Second
+	// day 10 becomes exportable once day 10 ended (the start of day 11) and its last epoch has been finalized
+	day10Exportable := utils.DayToTime(11).Add(finalityDelay)
+
+	tests := []struct {
+		name            string
+		lastExportedDay uint64
+		now             time.Time
+		want            time.Duration
+	}{
+		{"next day still running", 9, utils.DayToTime(10).Add(time.Hour), 0},
+		{"next day not finalized yet", 9, utils.DayToTime(11).Add(time.Minute), 0},
+		{"next day just became exportable", 9, day10Exportable, 0},
+		{"behind by two hours", 9, day10Exportable.Add(2 * time.Hour), 2 * time.Hour},
+		{"behind by more than a day", 8, day10Exportable.Add(2 * time.Hour), 26 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statisticsExportLag(tt.lastExportedDay, tt.now); got != tt.want {
+				t.Errorf("statisticsExportLag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarketCapEthPrice(t *testing.T) {
+	newRecordingDb(t)
+
+	currentPrice := func(currency string) float64 { return 1850.5 }
+	date := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.DepositChainID = 5
+	if got, err := marketCapEthPrice("USD", date, currentPrice); err != nil || got != 1850.5 {
+		t.Errorf("expected the current price on testnets, got %v (%v)", got, err)
+	}
+
+	// the recording driver has no price rows, so the historical price is missing
+	utils.Config.Chain.Config.DepositChainID = 1
+	if _, err := marketCapEthPrice("USD", date, currentPrice); !errors.Is(err, ErrNoHistoricalPrice) {
+		t.Errorf("expected ErrNoHistoricalPrice for a missing historical price, got %v", err)
+	}
+
+	newRecordingDb(t, recordingResult{contains: "SELECT eur FROM price WHERE ts = $1", columns: []string{"eur"}, row: []driver.Value{float64(1700.25)}})
+	utils.Config.Chain.Config.DepositChainID = 1
+	if got, err := marketCapEthPrice("EUR", date, currentPrice); err != nil || got != 1700.25 {
+		t.Errorf("expected the historical price, got %v (%v)", got, err)
+	}
+	if got, err := marketCapEthPrice("ETH", date, currentPrice); err != nil || got != 1 {
+		t.Errorf("expected a price of 1 for eth, got %v (%v)", got, err)
+	}
+
+	if _, err := marketCapEthPrice("XYZ", date, currentPrice); err == nil {
+		t.Errorf("expected an error for an unsupported currency")
+	}
+}
+
+func TestValidatorAmountsByIndex(t *testing.T) {
+	validators := []uint64{1, 2, 3}
+	deposits := []validatorAmount{
+		{ValidatorIndex: 1, Amount: 32_000_000_000},
+		{ValidatorIndex: 2, Amount: 1_000_000_000},
+		// a validator can have multiple deposits in the window
+		{ValidatorIndex: 2, Amount: 500_000_000},
+	}
+	withdrawals := []validatorAmount{
+		{ValidatorIndex: 2, Amount: 15_000_000},
+		{ValidatorIndex: 3, Amount: 32_012_000_000},
+	}
+
+	if got, want := validatorAmountsByIndex(validators, deposits), map[uint64]uint64{1: 32_000_000_000, 2: 1_500_000_000, 3: 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected deposits %v, got %v", want, got)
+	}
+	if got, want := validatorAmountsByIndex(validators, withdrawals), map[uint64]uint64{1: 0, 2: 15_000_000, 3: 32_012_000_000}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected withdrawals %v, got %v", want, got)
+	}
+}
+
+// newEmptyBigtable starts an in-memory Bigtable server with an empty beaconchain table and returns a client reading from it
+func newEmptyBigtable(t *testing.T) *Bigtable {
+	srv, err := bttest.NewServer("localhost:0")
+	if err != nil {
+		t.Fatalf("error starting bigtable test server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	conn, err := grpc.Dial(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("error connecting to bigtable test server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	adminClient, err := gcp_bigtable.NewAdminClient(ctx, "project", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("error creating bigtable admin client: %v", err)
+	}
+	if err := adminClient.CreateTable(ctx, "beaconchain"); err != nil {
+		t.Fatalf("error creating beaconchain table: %v", err)
+	}
+	for _, family := range []string{DEFAULT_FAMILY, VALIDATOR_BALANCES_FAMILY, ATTESTATIONS_FAMILY, PROPOSALS_FAMILY, SYNC_COMMITTEES_FAMILY, INCOME_DETAILS_COLUMN_FAMILY, STATS_COLUMN_FAMILY} {
+		if err := adminClient.CreateColumnFamily(ctx, "beaconchain", family); err != nil {
+			t.Fatalf("error creating column family %v: %v", family, err)
+		}
+	}
+
+	client, err := gcp_bigtable.NewClient(ctx, "project", "instance", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("error creating bigtable client: %v", err)
+	}
+	return &Bigtable{client: client, tableBeaconchain: client.Open("beaconchain"), chainId: "1"}
+}
+
+func TestWriteValidatorWithdrawalAddressStatsReplacesDay(t *testing.T) {
+	recorder := newRecordingDb(t)
+
+	if err := WriteValidatorWithdrawalAddressStatsForDay(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a re-export of the day must not keep the rows of addresses which are no longer part of it
+	deleted, inserted, marked := -1, -1, -1
+	for i, stmt := range recorder.executed() {
+		switch {
+		case strings.Contains(stmt, "DELETE FROM validator_withdrawal_address_stats"):
+			deleted = i
+		case strings.Contains(stmt, "insert into validator_withdrawal_address_stats"):
+			inserted = i
+		case strings.Contains(stmt, "withdrawal_address_stats_exported"):
+			marked = i
+		}
+	}
+	if deleted < 0 || inserted < deleted || marked < inserted {
+		t.Errorf("expected the day to be deleted, inserted and marked in order, got %v, %v and %v", deleted, inserted, marked)
+	}
+}
+
+func TestSubExportsMarkExportedWithoutBigtableData(t *testing.T) {
+	recorder := newRecordingDb(t,
+		// the attestation effectiveness requires the failed attestations of the day to be exported
+		recordingResult{
+			contains: "SELECT failed_attestations_exported",
+			columns:  []string{"failed_attestations_exported"},
+			row:      []driver.Value{true},
+		},
+	)
+	utils.Config.Statistics.BigtableMaxAttempts = 1
+	BigtableClient = newEmptyBigtable(t)
+
+	exports := []struct {
+		column string
+		export func(day uint64) error
+	}{
+		{"failed_attestations_exported", WriteValidatorFailedAttestationsStatisticsForDay},
+		{"attestation_components_exported", WriteValidatorAttestationComponentsForDay},
+		{"inclusion_distance_exported", WriteValidatorAttestationInclusionStats},
+		{"inclusion_delay_exported", WriteValidatorInclusionDelayForDay},
+		{"sync_duties_exported", WriteValidatorSyncDutiesForDay},
+		{"slashing_income_exported", WriteValidatorSlashingIncome},
+	}
+	for _, tt := range exports {
+		t.Run(tt.column, func(t *testing.T) {
+			before := len(recorder.executed())
+			if err := tt.export(10); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			statements := recorder.executed()[before:]
+			marked := false
+			for _, stmt := range statements {
+				if strings.Contains(strings.ToLower(stmt), "insert into validator_stats (") {
+					t.Errorf("expected no validator rows to be written without data, got %v", stmt)
+				}
+				if strings.Contains(stmt, "validator_stats_status") && strings.Contains(stmt, tt.column) {
+					marked = true
+				}
+			}
+			if !marked {
+				t.Errorf("expected %v to be marked without data, got statements %v", tt.column, statements)
+			}
+		})
+	}
+}
+
+func TestWriteValidatorBalancesWithoutBigtableData(t *testing.T) {
+	// the recording driver counts 225 validators activated until the end of the day
+	recorder := newRecordingDb(t)
+	utils.Config.Statistics.BigtableMaxAttempts = 1
+	BigtableClient = newEmptyBigtable(t)
+
+	if err := WriteValidatorBalances(10); err == nil {
+		t.Errorf("expected an error for a day with activated validators but without balances")
+	}
+	if statements := recorder.executed(); len(statements) != 0 {
+		t.Errorf("expected nothing to be written without the balances of the activated validators, got %v", statements)
+	}
+
+	// a day without activated validators has no balances
+	recorder = newRecordingDb(t, recordingResult{contains: "FROM validators WHERE activationepoch <= $1", columns: []string{"count"}, row: []driver.Value{int64(0)}})
+	utils.Config.Statistics.BigtableMaxAttempts = 1
+	if err := WriteValidatorBalances(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statements := recorder.executed(); len(statements) != 1 || !strings.Contains(statements[0], "balance_exported") {
+		t.Errorf("expected only balance_exported to be marked without activated validators, got %v", statements)
+	}
+	if args := recorder.executedArgs(); !reflect.DeepEqual(args[0][:1], []driver.Value{int64(10)}) {
+		t.Errorf("expected the day to be marked, got %v", args[0])
+	}
+}
+
+func TestWriteValidatorClIcomeWithoutBigtableIncome(t *testing.T) {
+	recorder := newRecordingDb(t,
+		// the cl rewards require the balances and deposits of the day to be exported
+		recordingResult{
+			contains: "last_balance_exported",
+			columns:  []string{"last_balance_exported", "cur_balance_exported", "cur_withdrawals_deposits_exported"},
+			row:      []driver.Value{true, true, true},
+		},
+		recordingResult{contains: "max(validatorindex) + 1", columns: []string{"count"}, row: []driver.Value{int64(2)}},
+	)
+	utils.Config.Statistics.BigtableMaxAttempts = 1
+	BigtableClient = newEmptyBigtable(t)
+
+	if err := WriteValidatorClIcome(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statements, args := recorder.executed(), recorder.executedArgs()
+	derived, marked := false, false
+	for i, stmt := range statements {
+		if strings.Contains(stmt, "cl_proposer_rewards_gwei") {
+			t.Errorf("expected no proposer and net rewards to be written without income, got %v", stmt)
+		}
+		if strings.Contains(stmt, "INSERT INTO validator_stats (validatorindex, day, cl_rewards_gwei)") {
+			derived = true
+			// the day and the validator range
+			if expected := []driver.Value{int64(10), int64(0), int64(2)}; !reflect.DeepEqual(args[i], expected) {
+				t.Errorf("expected the cl rewards to be derived for %v, got %v", expected, args[i])
+			}
+		}
+		if strings.Contains(stmt, "validator_stats_status") && strings.Contains(stmt, "cl_rewards_exported") {
+			marked = true
+		}
+	}
+	if !derived {
+		t.Errorf("expected the cl rewards to be derived from the balances without income, got %v", statements)
+	}
+	if !marked {
+		t.Errorf("expected cl_rewards_exported to be marked, got %v", statements)
+	}
+}
+
+func TestGetValidatorStatsStatus(t *testing.T) {
+	columns := []string{
+		"day", "status", "failed_attestations_exported", "sync_duties_exported", "withdrawals_deposits_exported", "balance_exported",
+		"cl_rewards_exported", "el_rewards_exported", "total_performance_exported", "block_stats_exported", "inclusion_distance_exported",
+		"slashing_events_exported", "slashing_income_exported", "relay_stats_exported", "withdrawal_address_stats_exported",
+		"network_stats_exported",
+	}
+	recorder := newRecordingDb(t,
+		recordingResult{
+			contains: "FROM validator_stats_status WHERE day = $1",
+			columns:  columns,
+			// the cl rewards and everything depending on them are still missing
+			row: []driver.Value{int64(10), false, true, true, true, true, false, true, false, true, true, true, false, true, true, false},
+		},
+	)
+
+	status, err := GetValidatorStatsStatus(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &types.ValidatorStatsStatus{
+		Day:                 10,
+		FailedAttestations:  true,
+		SyncDuties:          true,
+		WithdrawalsDeposits: true,
+		Balance:             true,
+		ElRewards:           true,
+		BlockStats:          true,
+		InclusionDistance:   true,
+		SlashingEvents:      true,
+		RelayStats:          true,
+		WithdrawalAddresses: true,
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("expected %+v, got %+v", want, status)
+	}
+	if status.AllSubExportsExported() {
+		t.Errorf("expected the day to miss sub-exports")
+	}
+
+	// a day without a status row has not been exported at all
+	recorder.results = nil
+	status, err = GetValidatorStatsStatus(11)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(status, &types.ValidatorStatsStatus{Day: 11}) {
+		t.Errorf("expected nothing to be exported for day 11, got %+v", status)
+	}
+}
+
+func TestWriteValidatorStatisticsForDayBestEffort(t *testing.T) {
+	// everything but the inclusion distance and the withdrawal addresses is exported, the inclusion distance fails as its
+	// dependency is reported as missing
+	recorder := newRecordingDb(t,
+		recordingResult{
+			contains: "SELECT failed_attestations_exported FROM",
+			columns:  []string{"failed_attestations_exported"},
+			row:      []driver.Value{false},
+		},
+		recordingResult{
+			contains: "FROM validator_stats_status WHERE day = $1",
+			columns: []string{
+				"day", "status", "failed_attestations_exported", "sync_duties_exported", "withdrawals_deposits_exported", "balance_exported",
+				"cl_rewards_exported", "el_rewards_exported", "total_performance_exported", "block_stats_exported", "inclusion_distance_exported",
+				"inclusion_delay_exported", "slashing_events_exported", "slashing_income_exported", "relay_stats_exported",
+				"withdrawal_address_stats_exported", "network_stats_exported",
+			},
+			row: []driver.Value{int64(10), false, true, true, true, true, true, true, true, true, false, true, true, true, true, false, true},
+		},
+	)
+
+	withdrawalAddressesMarked := func(statements []string) bool {
+		for _, stmt := range statements {
+			if strings.Contains(stmt, "INSERT INTO validator_stats_status") && strings.Contains(stmt, "withdrawal_address_stats_exported") {
+				return true
+			}
+		}
+		return false
+	}
+
+	// the strict mode stops at the first failed sub-export
+	err := WriteValidatorStatisticsForDay(10)
+	var subExportsErr *SubExportsError
+	if !errors.Is(err, ErrMissingDependency) || errors.As(err, &subExportsErr) {
+		t.Fatalf("expected the error of the inclusion distance export, got %v", err)
+	}
+	if withdrawalAddressesMarked(recorder.executed()) {
+		t.Errorf("expected the withdrawal addresses not to be exported after the failed inclusion distance export")
+	}
+
+	utils.Config.Statistics.BestEffort = true
+	before := len(recorder.executed())
+	err = WriteValidatorStatisticsForDay(10)
+	if !errors.As(err, &subExportsErr) || !errors.Is(err, ErrMissingDependency) {
+		t.Fatalf("expected the failed sub-exports to be collected, got %v", err)
+	}
+	if len(subExportsErr.Failures) != 1 || subExportsErr.Failures[0].Export != "attestation inclusion distance" {
+		t.Errorf("expected only the inclusion distance export to fail, got %v", subExportsErr.Failures)
+	}
+	if !withdrawalAddressesMarked(recorder.executed()[before:]) {
+		t.Errorf("expected the withdrawal addresses to be exported after the failed inclusion distance export")
+	}
+	for _, stmt := range recorder.executed()[before:] {
+		if strings.Contains(stmt, "SET status = true") {
+			t.Errorf("expected the day not to be marked as completed with failed sub-exports")
+		}
+	}
+}
+
+func TestWriteValidatorStatsExportedLocksStatusRow(t *testing.T) {
+	recorder := newRecordingDb(t)
+
+	columns := []string{
+		"failed_attestations_exported", "sync_duties_exported", "withdrawals_deposits_exported", "balance_exported", "cl_rewards_exported",
+		"el_rewards_exported", "total_performance_exported", "block_stats_exported", "inclusion_distance_exported", "inclusion_delay_exported",
+		"slashing_events_exported", "slashing_income_exported", "relay_stats_exported", "withdrawal_address_stats_exported", "network_stats_exported",
+	}
+
+	// sub-exports marking their column race with completion checks of the same day
+	g := errgroup.Group{}
+	for _, column := range columns {
+		column := column
+		g.Go(func() error {
+			return markColumnExported(10, column, time.Second)
+		})
+		g.Go(func() error {
+			_, err := WriteValidatorStatsExported(10)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	statements := recorder.executed()
+	locks, completions, marks := 0, 0, 0
+	for _, stmt := range statements {
+		switch {
+		case strings.Contains(stmt, "FOR UPDATE"):
+			locks++
+		case strings.Contains(stmt, "SET status = true"):
+			// every completion check has to hold the row lock
+			if completions >= locks {
+				t.Errorf("expected the status row to be locked before the completion check")
+			}
+			completions++
+		case strings.Contains(stmt, "INSERT INTO validator_stats_status"):
+			marks++
+		}
+	}
+	if locks != len(columns) || completions != len(columns) || marks != len(columns) {
+		t.Errorf("expected %v locks, completion checks and marked columns, got %v, %v and %v", len(columns), locks, completions, marks)
+	}
+}
+
+func TestValidatorFilter(t *testing.T) {
+	recorder := newRecordingDb(t)
+	utils.Config.Statistics.ValidatorFilter = []uint64{5, 42}
+
+	if err := WriteValidatorDepositWithdrawals(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inserts := 0
+	for i, stmt := range recorder.executed() {
+		if !strings.Contains(stmt, "insert into validator_stats") {
+			continue
+		}
+		inserts++
+		if !strings.Contains(stmt, "= ANY($4)") {
+			t.Errorf("expected the insert to be restricted to the filtered validators, got %v", stmt)
+		}
+		if args := recorder.args[i]; len(args) != 4 || fmt.Sprintf("%s", args[3]) != "{5,42}" {
+			t.Errorf("expected the filtered validators as last argument, got %v", args)
+		}
+	}
+	if inserts != 2 {
+		t.Errorf("expected the deposits and withdrawals to be inserted, got %v inserts", inserts)
+	}
+
+	// a day exported for a subset of the validators is never marked as exported
+	if completed, err := WriteValidatorStatsExported(10); err != nil || completed {
+		t.Errorf("expected the day not to be completed, got %v, %v", completed, err)
+	}
+	if err := writeNetworkBalancePercentiles(10, []*types.ValidatorBalanceStatistic{{Index: 5, EndBalance: 32e9}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := WriteNetworkStatsForDay(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, stmt := range recorder.executed() {
+		if strings.Contains(stmt, "validator_stats_status") || strings.Contains(stmt, "network_stats_per_day") {
+			t.Errorf("expected neither the export state nor the network stats to be written, got %v", stmt)
+		}
+	}
+
+	// the validator_stats batches only cover the filtered validators
+	var batches [][2]int
+	err := forEachValidatorBatch(10, "test", func(start, end int) error {
+		batches = append(batches, [2]int{start, end})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(batches, [][2]int{{5, 6}, {42, 43}}) {
+		t.Errorf("expected a batch per filtered validator, got %v", batches)
+	}
+
+	if !isExportedValidator(42) || isExportedValidator(41) {
+		t.Errorf("expected only the filtered validators to be exported")
+	}
+	utils.Config.Statistics.ValidatorFilter = nil
+	if !isExportedValidator(41) || len(exportedValidators()) != 0 {
+		t.Errorf("expected all validators to be exported without a filter")
+	}
+}
+
+func TestGetChartSeriesUnknownIndicator(t *testing.T) {
+	for _, indicator := range []string{"", "UNKNOWN", "ETH_PRICE_", "burned_fees"} {
+		if _, err := GetChartSeries(indicator, time.Unix(0, 0), time.Now()); !errors.Is(err, ErrUnknownChartSeriesIndicator) {
+			t.Errorf("expected an unknown indicator error for %q, got %v", indicator, err)
+		}
+	}
+	for _, indicator := range []string{"BURNED_FEES", "GASPRICE_P50", "TOTAL_WITHDRAWN", "ETH_PRICE_USD"} {
+		if !isChartSeriesIndicator(indicator) {
+			t.Errorf("expected %v to be a known indicator", indicator)
+		}
+	}
+}
+
+func TestWriteGenesisDayDepositsTwice(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{contains: "AS counted", columns: []string{"stored", "counted"}, row: []driver.Value{int64(4), int64(4)}},
+	)
+
+	// every export of day 0 resets the deposits of day -1 and day 0 before inserting them, so a second run can't count them twice
+	for run := 0; run < 2; run++ {
+		if err := WriteValidatorDepositWithdrawals(0); err != nil {
+			t.Fatalf("run %v: unexpected error: %v", run, err)
+		}
+	}
+	reset, inserted := []int{}, []int{}
+	for i, stmt := range recorder.executed() {
+		switch {
+		case strings.Contains(stmt, "UPDATE validator_stats SET deposits = NULL"):
+			reset = append(reset, i)
+		case strings.Contains(stmt, "insert into validator_stats (validatorindex, day, deposits, deposits_amount)"):
+			inserted = append(inserted, i)
+		}
+	}
+	if len(reset) != 2 || len(inserted) != 2 || reset[0] > inserted[0] || reset[1] > inserted[1] {
+		t.Errorf("expected the deposits to be reset before each insert, got resets %v and inserts %v", reset, inserted)
+	}
+
+	// deposits stored on both day -1 and day 0 fail the export
+	recorder.results[0].row = []driver.Value{int64(8), int64(4)}
+	if err := WriteValidatorDepositWithdrawals(0); err == nil || !strings.Contains(err.Error(), "counted twice") {
+		t.Errorf("expected an error for double counted genesis deposits, got %v", err)
+	}
+}
+
+func TestValidatorSyncCommitteePeriod(t *testing.T) {
+	p := validatorSyncCommitteePeriod(3, 768, 1023, &types.ValidatorSyncDutiesStatistic{Index: 1, ParticipatedSync: 24, MissedSync: 8, OrphanedSync: 2})
+	if p.Period != 3 || p.FirstEpoch != 768 || p.LastEpoch != 1023 || p.ParticipatedSync != 24 || p.MissedSync != 8 || p.OrphanedSync != 2 {
+		t.Errorf("unexpected sync committee period %+v", p)
+	}
+	if p.ParticipationRate == nil || *p.ParticipationRate != 0.75 {
+		t.Errorf("expected a participation rate of 0.75, got %v", p.ParticipationRate)
+	}
+
+	// a period without any duties found yet
+	if p := validatorSyncCommitteePeriod(4, 1024, 1279, nil); p.ParticipationRate != nil || p.ParticipatedSync != 0 {
+		t.Errorf("expected no duties for a period without data, got %+v", p)
+	}
+}
+
+func TestNetworkStatsAggregate(t *testing.T) {
+	rows := []types.ValidatorStatsRow{
+		{ValidatorIndex: 1, EndBalance: 32_010_000_000, EndEffectiveBalance: 32e9, ClRewards: 10_000_000, ElRewards: decimal.NewFromInt(4e16), MevRewards: decimal.NewFromInt(5e16), ProposedBlocks: 1, ParticipatedSync: 30, MissedSync: 2},
+		{ValidatorIndex: 2, EndBalance: 31_990_000_000, EndEffectiveBalance: 31e9, ClRewards: -5_000_000, MissedBlocks: 1, Withdrawals: 1, WithdrawalsAmount: 12_000_000},
+		// deposited but not yet active
+		{ValidatorIndex: 3, EndBalance: 32e9, Deposits: 1, DepositsAmount: 32e9},
+	}
+
+	aggregate := newNetworkStatsAggregate(10)
+	for i := range rows {
+		aggregate.add(&rows[i])
+	}
+	stats := aggregate.result()
+
+	rate := 30.0 / 32.0
+	want := &types.NetworkDayStats{
+		Day:                   10,
+		ActiveValidators:      2,
+		TotalBalance:          96e9,
+		TotalStake:            63e9,
+		AverageBalance:        32e9,
+		ClRewards:             5_000_000,
+		ElRewards:             decimal.NewFromInt(4e16),
+		MevRewards:            decimal.NewFromInt(5e16),
+		Deposits:              1,
+		DepositsAmount:        32e9,
+		Withdrawals:           1,
+		WithdrawalsAmount:     12_000_000,
+		ProposedBlocks:        1,
+		MissedBlocks:          1,
+		SyncParticipationRate: &rate,
+	}
+	if !stats.ElRewards.Equal(want.ElRewards) || !stats.MevRewards.Equal(want.MevRewards) {
+		t.Errorf("expected el rewards of %v and mev rewards of %v, got %v and %v", want.ElRewards, want.MevRewards, stats.ElRewards, stats.MevRewards)
+	}
+	stats.ElRewards, stats.MevRewards = want.ElRewards, want.MevRewards
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+
+	if empty := newNetworkStatsAggregate(11).result(); empty.AverageBalance != 0 || empty.SyncParticipationRate != nil {
+		t.Errorf("expected no average balance and participation rate without validators, got %+v", empty)
+	}
+}
+
+func TestWriteNetworkStatsForDayStoresAggregates(t *testing.T) {
+	exported := []string{"failed_attestations_exported", "sync_duties_exported", "withdrawals_deposits_exported", "balance_exported", "cl_rewards_exported", "el_rewards_exported", "total_performance_exported",
+		"block_stats_exported", "inclusion_distance_exported", "inclusion_delay_exported", "slashing_events_exported", "slashing_income_exported", "relay_stats_exported",
+		"withdrawal_address_stats_exported"}
+	status := make([]driver.Value, len(exported))
+	for i := range status {
+		status[i] = true
+	}
+	recorder := newRecordingDb(t,
+		recordingResult{contains: "FROM validator_stats_status WHERE day = $1", columns: exported, row: status},
+		recordingResult{
+			contains: "validatorindex >= $2",
+			columns:  []string{"validatorindex", "end_balance", "end_effective_balance", "cl_rewards_gwei", "deposits", "deposits_amount"},
+			rows: [][]driver.Value{
+				{int64(1), int64(32_010_000_000), int64(32e9), int64(10_000_000), int64(0), int64(0)},
+				{int64(2), int64(31_990_000_000), int64(31e9), int64(-5_000_000), int64(0), int64(0)},
+				// deposited but not yet active
+				{int64(3), int64(32e9), int64(0), int64(0), int64(1), int64(32e9)},
+			},
+		},
+	)
+
+	if err := WriteNetworkStatsForDay(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	statements, args := recorder.executed(), recorder.executedArgs()
+	for i, stmt := range statements {
+		if !strings.Contains(stmt, "INSERT INTO network_stats_per_day (day, active_validators") {
+			continue
+		}
+		// day, active validators, total balance, total effective balance, average balance of the active validators and cl rewards
+		want := []driver.Value{int64(10), int64(2), int64(96e9), int64(63e9), int64(32e9), int64(5_000_000)}
+		if !reflect.DeepEqual(args[i][:len(want)], want) {
+			t.Errorf("expected the sums of the validator stats %v, got %v", want, args[i][:len(want)])
+		}
+		if deposits, depositsAmount := args[i][8], args[i][9]; deposits != int64(1) || depositsAmount != int64(32e9) {
+			t.Errorf("expected 1 deposit of 32 ETH, got %v of %v", deposits, depositsAmount)
+		}
+		return
+	}
+	t.Errorf("expected the network stats to be written, got %v", statements)
+}
+
+func TestIncomeAnnotations(t *testing.T) {
+	rows := []validatorIncomeAnnotationRow{
+		{Day: 10, ClRewards: -12_000, MissedAttestations: 3},
+		{Day: 11, ClRewards: 5_000, MissedAttestations: 1},
+		{Day: 12, ClRewards: -1_000_000_000, SlashingEvents: 1, MissedAttestations: 20, MissedSync: 4},
+		{Day: 13, ClRewards: -8_000},
+	}
+
+	want := []types.ValidatorIncomeAnnotation{
+		{Day: 10, Reasons: []string{types.IncomeReasonMissedAttestations}},
+		{Day: 12, Reasons: []string{types.IncomeReasonSlashed, types.IncomeReasonMissedSync, types.IncomeReasonMissedAttestations}},
+		{Day: 13, Reasons: []string{types.IncomeReasonUnknown}},
+	}
+	if got := incomeAnnotations(rows); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestWriteValidatorStatisticsForDayLocked(t *testing.T) {
+	recorder := newRecordingDb(t)
+
+	memoryLocker := &memoryExportLocker{locks: map[string]chan struct{}{}}
+	statisticsExportLocker = memoryLocker
+
+	// another instance is exporting day 10
+	unlock, err := memoryLocker.lock(10, "validator_stats")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer unlock()
+
+	utils.Config.Statistics.ExportLockMode = "error"
+	if err := WriteValidatorStatisticsForDay(10); !errors.Is(err, ErrStatisticsExportLocked) {
+		t.Errorf("expected ErrStatisticsExportLocked, got %v", err)
+	}
+
+	utils.Config.Statistics.ExportLockMode = ""
+	if err := WriteValidatorStatisticsForDay(10); !errors.Is(err, ErrStatisticsExportSkipped) {
+		t.Errorf("expected the locked day to be skipped with ErrStatisticsExportSkipped, got %v", err)
+	}
+	if statements := recorder.executed(); len(statements) != 0 {
+		t.Errorf("expected nothing to be written for a locked day, got %v", statements)
+	}
+}
+
+func TestWriteValidatorStatisticsForDayHooksAfterUnlock(t *testing.T) {
+	// all sub-exports of day 10 are done, only the completion of the day is outstanding
+	newRecordingDb(t,
+		recordingResult{
+			contains: "FROM validator_stats_status WHERE day = $1",
+			columns: []string{
+				"day", "status", "failed_attestations_exported", "sync_duties_exported", "withdrawals_deposits_exported", "balance_exported",
+				"cl_rewards_exported", "el_rewards_exported", "total_performance_exported", "block_stats_exported", "inclusion_distance_exported",
+				"inclusion_delay_exported", "slashing_events_exported", "slashing_income_exported", "relay_stats_exported",
+				"withdrawal_address_stats_exported", "network_stats_exported",
+			},
+			row: []driver.Value{int64(10), false, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true},
+		},
+	)
+
+	memoryLocker := &memoryExportLocker{locks: map[string]chan struct{}{}}
+	statisticsExportLocker = memoryLocker
+
+	hookCalled, lockedDuringHook := false, false
+	RegisterStatisticsExportHook(func(day uint64, duration time.Duration) {
+		hookCalled = true
+		unlock, ok, err := memoryLocker.tryLock(day, "validator_stats")
+		if err != nil || !ok {
+			lockedDuringHook = true
+			return
+		}
+		unlock()
+	})
+	defer func() {
+		statisticsExportHooks.hooks = nil
+	}()
+
+	if err := WriteValidatorStatisticsForDay(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hookCalled {
+		t.Fatalf("expected the hooks to be called for the completed day")
+	}
+	if lockedDuringHook {
+		t.Errorf("expected the lock of the day to be released before the hooks are called")
+	}
+}
+
+func TestVerifyBlockStatsForDayAfterReorg(t *testing.T) {
+	// validator 5 was exported with a proposed block that has been orphaned by a reorg since
+	newRecordingDb(t,
+		recordingResult{
+			contains: "FROM blocks",
+			columns:  []string{"validatorindex", "proposed_blocks", "missed_blocks", "orphaned_blocks"},
+			row:      []driver.Value{int64(5), int64(0), int64(0), int64(1)},
+		},
+		recordingResult{
+			contains: "FROM validator_stats",
+			columns:  []string{"validatorindex", "proposed_blocks", "missed_blocks", "orphaned_blocks"},
+			row:      []driver.Value{int64(5), int64(1), int64(0), int64(0)},
+		},
+	)
+
+	validators, err := VerifyBlockStatsForDay(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(validators, []uint64{5}) {
+		t.Errorf("expected the block stats of validator 5 to be stale, got %v", validators)
+	}
+}
+
+func TestMissedRewardsEstimate(t *testing.T) {
+	utils.Config = &types.Config{}
+
+	row := validatorMissedRewardsRow{
+		MissedAttestations:      10,
+		MissedSync:              5,
+		MissedBlocks:            1,
+		AttestationDays:         2,
+		AttestationDayRewards:   440 * 10,
+		AttestationDayMissed:    10,
+		SyncDays:                1,
+		SyncDayRewards:          225*10 + 100*20,
+		ParticipatedSync:        100,
+		ProposedBlocks:          1,
+		ProposerRewards:         30_000_000,
+		ProposedBlocksElRewards: 20_000_000,
+	}
+	expected := &types.ValidatorMissedRewardsEstimate{
+		MissedAttestations:    10,
+		MissedSync:            5,
+		MissedBlocks:          1,
+		AttestationRewardGwei: 10,
+		SyncRewardGwei:        20,
+		ProposalRewardGwei:    50_000_000,
+		AttestationsGwei:      100,
+		SyncGwei:              100,
+		ProposalsGwei:         50_000_000,
+		TotalGwei:             50_000_200,
+	}
+	if res := missedRewardsEstimate(row, 225); !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected estimate %+v, got %+v", expected, res)
+	}
+
+	// without any proposed block during the period the reward of a proposal can't be derived
+	row.ProposedBlocks, row.ProposerRewards, row.ProposedBlocksElRewards = 0, 0, 0
+	if res := missedRewardsEstimate(row, 225); res.ProposalRewardGwei != 0 || res.TotalGwei != 200 {
+		t.Errorf("expected no proposal reward without proposed blocks, got %+v", res)
+	}
+
+	utils.Config.Statistics.MissedRewardsAssumptions.ProposalRewardGwei = 40_000_000
+	utils.Config.Statistics.MissedRewardsAssumptions.AttestationRewardGwei = 12
+	res := missedRewardsEstimate(row, 225)
+	if res.ProposalRewardGwei != 40_000_000 || res.AttestationRewardGwei != 12 || res.SyncRewardGwei != 20 {
+		t.Errorf("expected the configured assumptions to override the derived rewards, got %+v", res)
+	}
+	if res.TotalGwei != 10*12+5*20+40_000_000 {
+		t.Errorf("expected a total of %v, got %v", 10*12+5*20+40_000_000, res.TotalGwei)
+	}
+}
+
+func TestValidatorStatsBucketRows(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Statistics.ValidatorFilter = []uint64{1, 2}
+
+	balances := map[uint64]*types.ValidatorBalanceStatistic{
+		1: {Index: 1, MinBalance: 31, MaxBalance: 32, EndBalance: 32},
+		3: {Index: 3, MinBalance: 32, MaxBalance: 32, EndBalance: 32},
+	}
+	syncStats := map[uint64]*types.ValidatorSyncDutiesStatistic{
+		2: {Index: 2, ParticipatedSync: 3, MissedSync: 1},
+	}
+	failed := map[uint64]*types.ValidatorFailedAttestationsStatistic{
+		1: {Index: 1, MissedAttestations: 2},
+	}
+	components := map[uint64]*types.ValidatorAttestationComponentsStatistic{
+		1: {Index: 1, MissedSource: 2, MissedTarget: 2, MissedHead: 3},
+	}
+
+	rows := validatorStatsBucketRows(balances, syncStats, failed, components)
+	if len(rows) != 2 || rows[0].ValidatorIndex != 1 || rows[1].ValidatorIndex != 2 {
+		t.Fatalf("expected rows of the filtered validators 1 and 2, got %+v", rows)
+	}
+	if rows[0].Balance != balances[1] || rows[0].Failed != failed[1] || rows[0].Components != components[1] || rows[0].Sync != nil {
+		t.Errorf("expected the balance, failed attestations and attestation components of validator 1 to be merged, got %+v", rows[0])
+	}
+	if values := rows[0].values("2023-07-08T10", 2260, 2269); !reflect.DeepEqual(values[16:], []interface{}{uint64(2), uint64(0), uint64(2), uint64(2), uint64(3)}) {
+		t.Errorf("expected the failed attestations and attestation components of validator 1, got %v", values[16:])
+	}
+
+	values := rows[1].values("2023-07-08T10", 2260, 2269)
+	if len(values) != len(strings.Split(validatorStatsBucketColumns, ", ")) {
+		t.Fatalf("expected a value for each of the bucket columns, got %v", values)
+	}
+	if values[1] != "2023-07-08T10" || values[2] != uint64(2260) || values[3] != uint64(2269) {
+		t.Errorf("expected the bucket and its epoch range, got %v", values[:4])
+	}
+	if values[4] != nil || values[12] != uint64(3) || values[16] != nil {
+		t.Errorf("expected NULL balances and failed attestations for a validator with sync duties only, got %v", values)
+	}
+}
+
+func TestWriteValidatorStatisticsForEpochRange(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{contains: "FROM epochs", columns: []string{"count"}, row: []driver.Value{int64(10)}},
+	)
+	utils.Config.Statistics.BigtableMaxAttempts = 1
+
+	// ten epochs of day 10, which spans the epochs 2250 to 2474
+	firstEpoch, lastEpoch := uint64(2260), uint64(2269)
+
+	BigtableClient = newEmptyBigtable(t)
+
+	// validator 1 gains 1 mETH, participates in its sync duty and misses its attestation, validator 2 misses its sync duty
+	for epoch, balance := range map[uint64]uint64{firstEpoch: 32e9, lastEpoch: 32_001_000_000} {
+		err := BigtableClient.SaveValidatorBalances(epoch, []*types.Validator{
+			{Index: 1, Balance: balance, EffectiveBalance: 32e9},
+			{Index: 2, Balance: 32e9, EffectiveBalance: 32e9},
+		})
+		if err != nil {
+			t.Fatalf("error saving balances: %v", err)
+		}
+	}
+	syncSlot := 2262 * utils.Config.Chain.Config.SlotsPerEpoch
+	err := BigtableClient.SaveSyncComitteeDuties(map[uint64]map[string]*types.Block{
+		syncSlot: {"a": {Slot: syncSlot, Status: 1, SyncAggregate: &types.SyncAggregate{SyncCommitteeValidators: []uint64{1, 2}, SyncCommitteeBits: []byte{0x01}}}},
+	})
+	if err != nil {
+		t.Fatalf("error saving sync duties: %v", err)
+	}
+	attesterSlot := 2263 * utils.Config.Chain.Config.SlotsPerEpoch
+	err = BigtableClient.SaveAttestationAssignments(2263, map[string]uint64{fmt.Sprintf("%d-0-0", attesterSlot): 1, fmt.Sprintf("%d-0-1", attesterSlot): 2})
+	if err != nil {
+		t.Fatalf("error saving attestation assignments: %v", err)
+	}
+	err = BigtableClient.SaveAttestations(map[uint64]map[string]*types.Block{
+		attesterSlot + 1: {"a": {Slot: attesterSlot + 1, Attestations: []*types.Attestation{{Attesters: []uint64{2}, Data: &types.AttestationData{Slot: attesterSlot}}}}},
+	})
+	if err != nil {
+		t.Fatalf("error saving attestations: %v", err)
+	}
+
+	if err := WriteValidatorStatisticsForEpochRange(firstEpoch, lastEpoch, "2023-07-08T10"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statements, args := recorder.executed(), recorder.executedArgs()
+	inserted := false
+	for i, stmt := range statements {
+		if strings.Contains(stmt, "validator_stats ") || strings.Contains(stmt, "validator_stats_status") {
+			t.Errorf("expected the daily tables not to be touched, got %v", stmt)
+		}
+		if !strings.Contains(stmt, "insert into validator_stats_buckets") {
+			continue
+		}
+		inserted = true
+		// validatorindex, bucket, first_epoch, last_epoch, 8 balance columns, 4 sync columns, 2 failed attestation columns and the
+		// 3 attestation component columns, which are not exported by default
+		want := []driver.Value{
+			int64(1), "2023-07-08T10", int64(firstEpoch), int64(lastEpoch),
+			int64(32e9), int64(32_001_000_000), int64(32e9), int64(32e9), int64(32e9), int64(32e9), int64(32_001_000_000), int64(32e9),
+			int64(1), int64(0), int64(0), float64(1),
+			int64(1), int64(0), nil, nil, nil,
+			int64(2), "2023-07-08T10", int64(firstEpoch), int64(lastEpoch),
+			int64(32e9), int64(32e9), int64(32e9), int64(32e9), int64(32e9), int64(32e9), int64(32e9), int64(32e9),
+			int64(0), int64(1), int64(0), float64(0),
+			nil, nil, nil, nil, nil,
+		}
+		if !reflect.DeepEqual(args[i], want) {
+			t.Errorf("expected the bucket rows %v, got %v", want, args[i])
+		}
+	}
+	if !inserted {
+		t.Errorf("expected the bucket rows to be inserted, got %v", statements)
+	}
+
+	// the range is finalized on its own, the rest of the day is not required
+	recorder.mu.Lock()
+	recorder.results = []recordingResult{{contains: "FROM epochs", columns: []string{"count"}, row: []driver.Value{int64(9)}}}
+	recorder.mu.Unlock()
+	if err := WriteValidatorStatisticsForEpochRange(firstEpoch, lastEpoch, "2023-07-08T10"); !errors.Is(err, ErrDayNotFinalized) {
+		t.Errorf("expected ErrDayNotFinalized for a partially finalized range, got %v", err)
+	}
+
+	if err := WriteValidatorStatisticsForEpochRange(lastEpoch, firstEpoch, "2023-07-08T10"); err == nil {
+		t.Errorf("expected an error for a range ending before it starts")
+	}
+	if err := WriteValidatorStatisticsForEpochRange(firstEpoch, lastEpoch, ""); err == nil {
+		t.Errorf("expected an error for an empty bucket key")
+	}
+	if err := WriteValidatorStatisticsForEpochRange(2250, 2250+utils.EpochsPerDay(), "2023-07-08"); err == nil {
+		t.Errorf("expected an error for a range exceeding a day")
+	}
+}
+
+func TestBalanceCheckpoint(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{
+			contains: "SELECT balance_checkpoint",
+			columns:  []string{"balance_checkpoint"},
+			row:      []driver.Value{int64(3)},
+		},
+	)
+
+	// the validators up to the checkpoint have been written by a previous run
+	resumeAfter, err := getBalanceCheckpoint(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resumeAfter.Valid || resumeAfter.Int64 != 3 {
+		t.Fatalf("expected the checkpoint after validator 3, got %v", resumeAfter)
+	}
+
+	pending := []*types.ValidatorBalanceStatistic{}
+	for _, validatorIndex := range []uint64{5, 7, 8, 9} {
+		pending = append(pending, &types.ValidatorBalanceStatistic{Index: validatorIndex})
+	}
+
+	// the pending validators are written in the batches 5, 7 and 8, 9
+	checkpoint := newBalanceCheckpoint(10, pending, 2)
+	if err := checkpoint.batchCompleted(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statements := recorder.executed(); len(statements) != 0 {
+		t.Errorf("expected no checkpoint before the first batch completed, got %v", statements)
+	}
+	if err := checkpoint.batchCompleted(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recorder.mu.Lock()
+	args := recorder.args
+	recorder.mu.Unlock()
+	if len(args) != 1 || !reflect.DeepEqual(args[0], []driver.Value{int64(10), int64(9)}) {
+		t.Errorf("expected a single checkpoint after validator 9 once both batches completed, got %v", args)
+	}
+}
+
+func TestValidatorIndexAboveRegex(t *testing.T) {
+	for _, validatorIndex := range []uint64{0, 3, 9, 10, 99, 109, 123, 990} {
+		re := regexp.MustCompile("^" + validatorIndexAboveRegex(validatorIndex) + "$")
+		for i := uint64(0); i < 2000; i++ {
+			if matched := re.MatchString(strconv.FormatUint(i, 10)); matched != (i > validatorIndex) {
+				t.Errorf("expected the regex of validator %v to match %v: %v, got %v", validatorIndex, i, i > validatorIndex, matched)
+			}
+		}
+	}
+}
+
+func TestWriteValidatorBalancesResumesAfterCheckpoint(t *testing.T) {
+	recorder := newRecordingDb(t,
+		recordingResult{
+			contains: "SELECT balance_checkpoint",
+			columns:  []string{"balance_checkpoint"},
+			row:      []driver.Value{int64(9)},
+		},
+	)
+	utils.Config.Statistics.BigtableMaxAttempts = 1
+	utils.Config.Statistics.BalancesCheckpoint = true
+	BigtableClient = newEmptyBigtable(t)
+
+	firstEpoch, lastEpoch := utils.GetFirstAndLastEpochForDay(10)
+	validators := []*types.Validator{}
+	for _, validatorIndex := range []uint64{1, 2, 9, 10, 11, 25} {
+		validators = append(validators, &types.Validator{Index: validatorIndex, Balance: 32e9, EffectiveBalance: 32e9})
+	}
+	for _, epoch := range []uint64{firstEpoch, lastEpoch} {
+		if err := BigtableClient.SaveValidatorBalances(epoch, validators); err != nil {
+			t.Fatalf("error saving balances: %v", err)
+		}
+	}
+
+	if err := WriteValidatorBalances(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// only the balances of the validators after the checkpoint are read and written
+	written := []driver.Value{}
+	storedPercentiles := false
+	args := recorder.executedArgs()
+	for i, stmt := range recorder.executed() {
+		if strings.Contains(stmt, "insert into validator_stats (validatorindex, day, min_balance") {
+			for a := 0; a < len(args[i]); a += 10 {
+				written = append(written, args[i][a])
+			}
+		}
+		if strings.Contains(stmt, "INSERT INTO network_stats_per_day") && strings.Contains(stmt, "percentile_cont") {
+			storedPercentiles = true
+		}
+	}
+	if want := []driver.Value{int64(10), int64(11), int64(25)}; !reflect.DeepEqual(written, want) {
+		t.Errorf("expected the balances of validators %v to be written, got %v", want, written)
+	}
+	if !storedPercentiles {
+		t.Errorf("expected the balance percentiles to be computed from the stored balances")
+	}
+}
+
+func TestGetValidatorDailyPerformance(t *testing.T) {
+	tests := []struct {
+		name               string
+		offsetDay          uint64
+		lastFinalizedEpoch uint64
+		// wantToDay is the newest day of the page, -1 if no day is finalized yet
+		wantToDay int64
+	}{
+		{name: "offset before the last finalized day", offsetDay: 7, lastFinalizedEpoch: 2474, wantToDay: 7},
+		{name: "offset after the last finalized day", offsetDay: 20, lastFinalizedEpoch: 2474, wantToDay: 10},
+		{name: "last day not finalized completely", offsetDay: 20, lastFinalizedEpoch: 2473, wantToDay: 9},
+		{name: "no day finalized", offsetDay: 0, lastFinalizedEpoch: 100, wantToDay: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newRecordingDb(t, recordingResult{
+				contains: "FROM validator_stats",
+				columns:  []string{"day", "cl_rewards_gwei"},
+				rows:     [][]driver.Value{{int64(7), int64(1_000_000)}, {int64(6), int64(2_000_000)}},
+			})
+
+			rows, err := getValidatorDailyPerformance(5, tt.offsetDay, 2, tt.lastFinalizedEpoch)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantToDay < 0 {
+				if recorder.queried() != 0 || len(rows) != 0 {
+					t.Errorf("expected no query without a finalized day, got %v queries and %v rows", recorder.queried(), len(rows))
+				}
+				return
+			}
+			if want := [][]driver.Value{{int64(5), tt.wantToDay, int64(2)}}; !reflect.DeepEqual(recorder.queriedArgs(), want) {
+				t.Errorf("expected the query args %v, got %v", want, recorder.queriedArgs())
+			}
+			if len(rows) != 2 || rows[0].Day != 7 || rows[1].Day != 6 {
+				t.Errorf("expected days 7 and 6, got %+v", rows)
+			}
+		})
+	}
+}
@@ -978,7 +978,7 @@ func getValidatorExecutionPerformance(queryIndices []uint64) ([]types.ExecutionP
 	if err != nil {
 		return nil, fmt.Errorf("error getting last statistic day: %w", err)
 	}
-	firstEpochTime := utils.EpochToTime((lastStatsDay + 1) * utils.EpochsPerDay())
+	firstEpochTime := utils.EpochToTime(utils.FirstEpochOfDay(lastStatsDay + 1))
 
 	for _, block := range blocks {
 		proposer := blockToProposerMap[block.Number].Proposer
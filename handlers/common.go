@@ -54,7 +54,7 @@ func GetValidatorEarnings(validators []uint64, currency string) (*types.Validato
 	if err != nil {
 		return nil, nil, err
 	}
-	firstEpoch := (lastStatsDay + 1) * utils.EpochsPerDay()
+	firstEpoch := utils.FirstEpochOfDay(lastStatsDay + 1)
 
 	balancesMap := make(map[uint64]*types.Validator, 0)
 	totalBalance := uint64(0)
@@ -175,7 +175,7 @@ func GetValidatorEarnings(validators []uint64, currency string) (*types.Validato
 	}
 
 	proposedToday := []uint64{}
-	todayStartEpoch := uint64(lastStatsDay+1) * utils.EpochsPerDay()
+	todayStartEpoch := utils.FirstEpochOfDay(lastStatsDay + 1)
 	validatorProposalData := types.ValidatorProposalData{}
 	validatorProposalData.Proposals = make([][]uint64, len(proposals))
 	for i, b := range proposals {
@@ -365,7 +365,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			timings.Charts = time.Since(start)
 		}()
 
-		validatorPageData.IncomeHistoryChartData, err = db.GetValidatorIncomeHistoryChart([]uint64{index}, currency, lastFinalizedEpoch)
+		validatorPageData.IncomeHistoryChartData, err = db.GetValidatorIncomeHistoryChart([]uint64{index}, currency, lastFinalizedEpoch, false)
 
 		if err != nil {
 			return fmt.Errorf("error calling db.GetValidatorIncomeHistoryChart: %v", err)
@@ -578,7 +578,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			}
 
 			// add attestationStats that are not yet in validator_stats
-			lookback := int64(lastFinalizedEpoch - (lastStatsDay+1)*utils.EpochsPerDay())
+			lookback := int64(lastFinalizedEpoch - utils.FirstEpochOfDay(lastStatsDay+1))
 			if lookback > 0 {
 				// logger.Infof("retrieving attestations not yet in stats, lookback is %v", lookback)
 				attestations, err := db.BigtableClient.GetValidatorFailedAttestationHistory([]uint64{index}, lastFinalizedEpoch-uint64(lookback), lastFinalizedEpoch)
@@ -704,7 +704,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			}
 
 			// if sync duties of last period haven't fully been exported yet, fetch remaining duties from bigtable
-			lastExportedEpoch := (lastStatsDay+1)*utils.EpochsPerDay() - 1
+			lastExportedEpoch := utils.FirstEpochOfDay(lastStatsDay+1) - 1
 			lastSyncPeriod := actualSyncPeriods[0]
 			if lastSyncPeriod.LastEpoch > lastExportedEpoch {
 				res, err := db.BigtableClient.GetValidatorSyncDutiesHistory([]uint64{index}, lastExportedEpoch+1, latestEpoch)
@@ -1577,7 +1577,7 @@ func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
-		lastActionEpoch := (lastActionDay + 1) * utils.EpochsPerDay()
+		lastActionEpoch := utils.FirstEpochOfDay(lastActionDay + 1)
 		// if the validator had some duties after the exit epoch we calculate how many epochs we have to check after the exit epoch
 		if lastActionEpoch > currentEpoch {
 			postExitEpochs = protomath.MinU64(lastActionEpoch, services.LatestEpoch()-1) - currentEpoch
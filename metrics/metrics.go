@@ -65,6 +65,22 @@ var (
 		Name: "notifications_sent",
 		Help: "Counter of notifications sent with the channel and notification type in the label",
 	}, []string{"channel", "status"})
+	StatsExportBatchesTotal = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "stats_export_batches_total",
+		Help: "Gauge of batches scheduled by the last run of a statistics sub-export with the sub-export in labels",
+	}, []string{"export"})
+	StatsExportBatchesCompleted = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "stats_export_batches_completed",
+		Help: "Gauge of batches completed by the last run of a statistics sub-export with the sub-export in labels",
+	}, []string{"export"})
+	RowsExported = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "stats_rows_exported",
+		Help: "Counter of validator_stats rows written by a statistics sub-export with the sub-export in labels",
+	}, []string{"export"})
+	StatsLastExportedDay = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "stats_last_exported_day",
+		Help: "Gauge of the last day whose statistics export has been completed",
+	})
 )
 
 var logger = logrus.New().WithField("module", "metrics")
@@ -97,6 +97,7 @@ func (lc *LighthouseClient) GetNewBlockChan() chan *types.Block {
 
 		for {
 			e := <-stream.Events
+			seenAt := time.Now()
 			// logger.Infof("retrieved %v via event stream", e.Data())
 			var parsed StreamedBlockEventData
 			err = json.Unmarshal([]byte(e.Data()), &parsed)
@@ -114,6 +115,7 @@ func (lc *LighthouseClient) GetNewBlockChan() chan *types.Block {
 			logger.Infof("retrieved %v blocks for slot %v", len(blks), parsed.Slot)
 			for _, blk := range blks {
 				// logger.Infof("pushing block %v", blk.Slot)
+				blk.SeenAt = seenAt
 				blkCh <- blk
 			}
 		}
@@ -1318,7 +1318,7 @@ func TotalEmissionChartData() (*types.GenericChartData, error) {
 	for _, row := range rows {
 		seriesData = append(seriesData, []float64{
 			float64(row.Day.UnixMilli()),
-			72009990.50 + row.Value,
+			utils.GenesisSupply() + row.Value,
 		})
 	}
 
@@ -23,6 +23,66 @@ type ValidatorFailedAttestationsStatistic struct {
 	OrphanedAttestations uint64
 }
 
+// ValidatorAttestationComponentsStatistic are the source, target and head votes a validator missed
+type ValidatorAttestationComponentsStatistic struct {
+	Index        uint64
+	MissedSource uint64
+	MissedTarget uint64
+	MissedHead   uint64
+}
+
+type ValidatorAttestationInclusionStatistic struct {
+	Index                uint64
+	IncludedAttestations uint64
+	InclusionDistanceSum uint64
+	MaxInclusionDistance uint64
+	// FailedAttestations are the missed and orphaned attestations of the validator, they are not part of the inclusion statistics
+	FailedAttestations uint64
+}
+
+// AvgInclusionDistance returns the average distance in slots between the attester slot and the inclusion slot of the included attestations
+func (s *ValidatorAttestationInclusionStatistic) AvgInclusionDistance() float64 {
+	if s.IncludedAttestations == 0 {
+		return 0
+	}
+	return float64(s.InclusionDistanceSum) / float64(s.IncludedAttestations)
+}
+
+// AvgInclusionDelay returns the average number of slots the included attestations were included later than the earliest possible
+// slot, the slot after the attester slot. An attestation included in the next slot has an inclusion delay of 0.
+func (s *ValidatorAttestationInclusionStatistic) AvgInclusionDelay() float64 {
+	if s.IncludedAttestations == 0 {
+		return 0
+	}
+	return s.AvgInclusionDistance() - 1
+}
+
+// MaxInclusionDelay returns the highest inclusion delay of the included attestations
+func (s *ValidatorAttestationInclusionStatistic) MaxInclusionDelay() uint64 {
+	if s.MaxInclusionDistance == 0 {
+		return 0
+	}
+	return s.MaxInclusionDistance - 1
+}
+
+// AttestationEffectiveness returns the attestation effectiveness in percent, the share of included attestations weighted by how
+// close to the optimal inclusion distance of 1 slot they were included: included / duties * 1 / avg inclusion distance. A missed
+// or orphaned attestation counts as 0%. It is nil if the validator had no attestation duties.
+func (s *ValidatorAttestationInclusionStatistic) AttestationEffectiveness() *float64 {
+	duties := s.IncludedAttestations + s.FailedAttestations
+	if duties == 0 {
+		return nil
+	}
+	effectiveness := 0.0
+	if s.IncludedAttestations > 0 {
+		effectiveness = float64(s.IncludedAttestations) / float64(duties) * 100
+		if avgDistance := s.AvgInclusionDistance(); avgDistance > 1 {
+			effectiveness /= avgDistance
+		}
+	}
+	return &effectiveness
+}
+
 type ValidatorSyncDutiesStatistic struct {
 	Index            uint64
 	ParticipatedSync uint64
@@ -30,6 +90,17 @@ type ValidatorSyncDutiesStatistic struct {
 	OrphanedSync     uint64
 }
 
+// ParticipationRate returns participated / (participated + missed) sync duties of the validator, nil if it had none of them.
+// Orphaned sync duties were performed but their block did not make it into the chain, so they are not counted.
+func (s *ValidatorSyncDutiesStatistic) ParticipationRate() *float64 {
+	total := s.ParticipatedSync + s.MissedSync
+	if total == 0 {
+		return nil
+	}
+	rate := float64(s.ParticipatedSync) / float64(total)
+	return &rate
+}
+
 type ValidatorWithdrawal struct {
 	Index  uint64
 	Epoch  uint64
@@ -102,4 +102,9 @@ type ChainConfig struct {
 	MaxWithdrawalsPerPayload        uint64 `yaml:"MAX_WITHDRAWALS_PER_PAYLOAD"`
 	MaxValidatorsPerWithdrawalSweep uint64 `yaml:"MAX_VALIDATORS_PER_WITHDRAWALS_SWEEP"`
 	MaxBlsToExecutionChange         uint64 `yaml:"MAX_BLS_TO_EXECUTION_CHANGES"`
+
+	// explorer specific, not part of the consensus specs
+	// ClCurrencyDivisor is the number of gwei per unit of the consensus layer currency, 1e9 for ETH. Balances on Gnosis Chain
+	// are denominated in mGNO, so 32e9 of them make up 1 GNO.
+	ClCurrencyDivisor uint64 `yaml:"CL_CURRENCY_DIVISOR"`
 }
@@ -31,12 +31,14 @@ type Config struct {
 	} `yaml:"bigtable"`
 	LastAttestationCachePath string `yaml:"lastAttestationCachePath" envconfig:"LAST_ATTESTATION_CACHE_PATH"`
 	Chain                    struct {
-		Name                       string `yaml:"name" envconfig:"CHAIN_NAME"`
-		GenesisTimestamp           uint64 `yaml:"genesisTimestamp" envconfig:"CHAIN_GENESIS_TIMESTAMP"`
-		GenesisValidatorsRoot      string `yaml:"genesisValidatorsRoot" envconfig:"CHAIN_GENESIS_VALIDATORS_ROOT"`
-		DomainBLSToExecutionChange string `yaml:"domainBLSToExecutionChange" envconfig:"CHAIN_DOMAIN_BLS_TO_EXECUTION_CHANGE"`
-		DomainVoluntaryExit        string `yaml:"domainVoluntaryExit" envconfig:"CHAIN_DOMAIN_VOLUNTARY_EXIT"`
-		ConfigPath                 string `yaml:"configPath" envconfig:"CHAIN_CONFIG_PATH"`
+		Name                       string  `yaml:"name" envconfig:"CHAIN_NAME"`
+		GenesisTimestamp           uint64  `yaml:"genesisTimestamp" envconfig:"CHAIN_GENESIS_TIMESTAMP"`
+		GenesisValidatorsRoot      string  `yaml:"genesisValidatorsRoot" envconfig:"CHAIN_GENESIS_VALIDATORS_ROOT"`
+		GenesisSupply              float64 `yaml:"genesisSupply" envconfig:"CHAIN_GENESIS_SUPPLY"`
+		MergeBlock                 uint64  `yaml:"mergeBlock" envconfig:"CHAIN_MERGE_BLOCK"`
+		DomainBLSToExecutionChange string  `yaml:"domainBLSToExecutionChange" envconfig:"CHAIN_DOMAIN_BLS_TO_EXECUTION_CHANGE"`
+		DomainVoluntaryExit        string  `yaml:"domainVoluntaryExit" envconfig:"CHAIN_DOMAIN_VOLUNTARY_EXIT"`
+		ConfigPath                 string  `yaml:"configPath" envconfig:"CHAIN_CONFIG_PATH"`
 		Config                     ChainConfig
 	} `yaml:"chain"`
 	Eth1ErigonEndpoint  string `yaml:"eth1ErigonEndpoint" envconfig:"ETH1_ERIGON_ENDPOINT"`
@@ -192,6 +194,39 @@ type Config struct {
 		Enabled bool   `yaml:"enabled" envconfig:"PPROF_ENABLED"`
 		Port    string `yaml:"port" envconfig:"PPROF_PORT"`
 	} `yaml:"pprof"`
+	Statistics struct {
+		BigtableMaxAttempts                     int                      `yaml:"bigtableMaxAttempts" envconfig:"STATISTICS_BIGTABLE_MAX_ATTEMPTS"`
+		BigtableRetryDelay                      time.Duration            `yaml:"bigtableRetryDelay" envconfig:"STATISTICS_BIGTABLE_RETRY_DELAY"`
+		MarketCapCurrency                       string                   `yaml:"marketCapCurrency" envconfig:"STATISTICS_MARKET_CAP_CURRENCY"`
+		FailedAttestationsEpochBatchSize        uint64                   `yaml:"failedAttestationsEpochBatchSize" envconfig:"STATISTICS_FAILED_ATTESTATIONS_EPOCH_BATCH_SIZE"`
+		FailedAttestationsAdaptiveBatchSize     bool                     `yaml:"failedAttestationsAdaptiveBatchSize" envconfig:"STATISTICS_FAILED_ATTESTATIONS_ADAPTIVE_BATCH_SIZE"`
+		FailedAttestationsBatchLatencyThreshold time.Duration            `yaml:"failedAttestationsBatchLatencyThreshold" envconfig:"STATISTICS_FAILED_ATTESTATIONS_BATCH_LATENCY_THRESHOLD"`
+		AttestationComponents                   bool                     `yaml:"attestationComponents" envconfig:"STATISTICS_ATTESTATION_COMPONENTS"`
+		ElBlocksBatchSize                       int                      `yaml:"elBlocksBatchSize" envconfig:"STATISTICS_EL_BLOCKS_BATCH_SIZE"`
+		ElBlocksConcurrency                     int                      `yaml:"elBlocksConcurrency" envconfig:"STATISTICS_EL_BLOCKS_CONCURRENCY"`
+		SyncDutiesBatchSize                     int                      `yaml:"syncDutiesBatchSize" envconfig:"STATISTICS_SYNC_DUTIES_BATCH_SIZE"`
+		BalancesBatchSize                       int                      `yaml:"balancesBatchSize" envconfig:"STATISTICS_BALANCES_BATCH_SIZE"`
+		BalancesCheckpoint                      bool                     `yaml:"balancesCheckpoint" envconfig:"STATISTICS_BALANCES_CHECKPOINT"`
+		ClRewardsBatchSize                      int                      `yaml:"clRewardsBatchSize" envconfig:"STATISTICS_CL_REWARDS_BATCH_SIZE"`
+		ElRewardsBatchSize                      int                      `yaml:"elRewardsBatchSize" envconfig:"STATISTICS_EL_REWARDS_BATCH_SIZE"`
+		TotalPerformanceBatchSize               int                      `yaml:"totalPerformanceBatchSize" envconfig:"STATISTICS_TOTAL_PERFORMANCE_BATCH_SIZE"`
+		ComputeRanks                            *bool                    `yaml:"computeRanks" envconfig:"STATISTICS_COMPUTE_RANKS"`
+		ExportLockMode                          string                   `yaml:"exportLockMode" envconfig:"STATISTICS_EXPORT_LOCK_MODE"`
+		ExportDeadlines                         map[string]time.Duration `yaml:"exportDeadlines" envconfig:"STATISTICS_EXPORT_DEADLINES"`
+		ExportWebhookURL                        string                   `yaml:"exportWebhookUrl" envconfig:"STATISTICS_EXPORT_WEBHOOK_URL"`
+		ExportWebhookTimeout                    time.Duration            `yaml:"exportWebhookTimeout" envconfig:"STATISTICS_EXPORT_WEBHOOK_TIMEOUT"`
+		LateBlockSlotThreshold                  float64                  `yaml:"lateBlockSlotThreshold" envconfig:"STATISTICS_LATE_BLOCK_SLOT_THRESHOLD"`
+		DryRun                                  bool                     `yaml:"dryRun" envconfig:"STATISTICS_DRY_RUN"`
+		BestEffort                              bool                     `yaml:"bestEffort" envconfig:"STATISTICS_BEST_EFFORT"`
+		ValidatorFilter                         []uint64                 `yaml:"-" ignored:"true"`
+		ChartExcludedValidators                 []uint64                 `yaml:"chartExcludedValidators" envconfig:"STATISTICS_CHART_EXCLUDED_VALIDATORS"`
+		MevBribeOverrides                       map[string]string        `yaml:"mevBribeOverrides" envconfig:"STATISTICS_MEV_BRIBE_OVERRIDES"`
+		MissedRewardsAssumptions                struct {
+			AttestationRewardGwei int64 `yaml:"attestationRewardGwei" envconfig:"STATISTICS_MISSED_REWARDS_ATTESTATION_REWARD_GWEI"`
+			SyncRewardGwei        int64 `yaml:"syncRewardGwei" envconfig:"STATISTICS_MISSED_REWARDS_SYNC_REWARD_GWEI"`
+			ProposalRewardGwei    int64 `yaml:"proposalRewardGwei" envconfig:"STATISTICS_MISSED_REWARDS_PROPOSAL_REWARD_GWEI"`
+		} `yaml:"missedRewardsAssumptions"`
+	} `yaml:"statistics"`
 	NodeJobsProcessor struct {
 		ElEndpoint string `yaml:"elEndpoint" envconfig:"NODE_JOBS_PROCESSOR_EL_ENDPOINT"`
 		ClEndpoint string `yaml:"clEndpoint" envconfig:"NODE_JOBS_PROCESSOR_CL_ENDPOINT"`
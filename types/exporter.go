@@ -123,6 +123,7 @@ type Block struct {
 	ExecutionPayload           *ExecutionPayload // warning: payload may be nil, for phase0/altair blocks
 	Canonical                  bool
 	SignedBLSToExecutionChange []*SignedBLSToExecutionChange
+	SeenAt                     time.Time // time the block was announced by the node's head event stream, zero if it was not received live
 }
 
 type SignedBLSToExecutionChange struct {
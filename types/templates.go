@@ -17,6 +17,7 @@ import (
 	itypes "github.com/gobitfly/eth-rewards/types"
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
 // PageData is a struct to hold web page data
@@ -514,14 +515,261 @@ type ValidatorBalanceHistory struct {
 	EffectiveBalance uint64 `db:"effectivebalance"`
 }
 
+// ValidatorStatsStatus is the export state of the validator statistics of a day. Status is set once all sub-exports are exported.
+type ValidatorStatsStatus struct {
+	Day                 uint64 `db:"day"`
+	Status              bool   `db:"status"`
+	FailedAttestations  bool   `db:"failed_attestations_exported"`
+	SyncDuties          bool   `db:"sync_duties_exported"`
+	WithdrawalsDeposits bool   `db:"withdrawals_deposits_exported"`
+	Balance             bool   `db:"balance_exported"`
+	ClRewards           bool   `db:"cl_rewards_exported"`
+	ElRewards           bool   `db:"el_rewards_exported"`
+	TotalPerformance    bool   `db:"total_performance_exported"`
+	BlockStats          bool   `db:"block_stats_exported"`
+	InclusionDistance   bool   `db:"inclusion_distance_exported"`
+	InclusionDelay      bool   `db:"inclusion_delay_exported"`
+	SlashingEvents      bool   `db:"slashing_events_exported"`
+	SlashingIncome      bool   `db:"slashing_income_exported"`
+	RelayStats          bool   `db:"relay_stats_exported"`
+	WithdrawalAddresses bool   `db:"withdrawal_address_stats_exported"`
+	NetworkStats        bool   `db:"network_stats_exported"`
+	// AttestationComponents is only exported if enabled by statistics.attestationComponents and not required to complete the day
+	AttestationComponents bool `db:"attestation_components_exported"`
+}
+
+// AllSubExportsExported reports whether all sub-exports of the day have been exported, regardless of the aggregate Status
+func (s *ValidatorStatsStatus) AllSubExportsExported() bool {
+	return s.FailedAttestations && s.SyncDuties && s.WithdrawalsDeposits && s.Balance && s.ClRewards && s.ElRewards && s.TotalPerformance &&
+		s.BlockStats && s.InclusionDistance && s.InclusionDelay && s.SlashingEvents && s.SlashingIncome && s.RelayStats && s.WithdrawalAddresses && s.NetworkStats
+}
+
+// ValidatorEffectiveBalanceHistory is the summed up effective balance (in gwei) of a set of validators at the end of a day
+type ValidatorEffectiveBalanceHistory struct {
+	Day              int64  `db:"day"`
+	EffectiveBalance uint64 `db:"end_effective_balance"`
+}
+
+// ValidatorDepositHistory are the summed up deposits (amount in gwei) of a set of validators during a day
+type ValidatorDepositHistory struct {
+	Day            int64  `db:"day"`
+	Deposits       uint64 `db:"deposits"`
+	DepositsAmount uint64 `db:"deposits_amount"`
+	// IncludesGenesisDeposits is set on day 0 if it contains the genesis deposits, which are stored at day -1
+	IncludesGenesisDeposits bool `db:"-"`
+}
+
+// ValidatorMissedRewardsEstimate is the estimated consensus and execution layer income in gwei a set of validators did not earn
+// because of missed duties, together with the number of missed duties and the assumed reward of a single duty
+type ValidatorMissedRewardsEstimate struct {
+	MissedAttestations    int64 `json:"missed_attestations"`
+	MissedSync            int64 `json:"missed_sync"`
+	MissedBlocks          int64 `json:"missed_blocks"`
+	AttestationRewardGwei int64 `json:"attestation_reward_gwei"`
+	SyncRewardGwei        int64 `json:"sync_reward_gwei"`
+	ProposalRewardGwei    int64 `json:"proposal_reward_gwei"`
+	AttestationsGwei      int64 `json:"attestations_gwei"`
+	SyncGwei              int64 `json:"sync_gwei"`
+	ProposalsGwei         int64 `json:"proposals_gwei"`
+	TotalGwei             int64 `json:"total_gwei"`
+}
+
+// ValidatorIncomeAnnotation explains why the cl rewards of a set of validators were negative on a day, Reasons contains the
+// IncomeReason constants that apply, ordered by severity
+type ValidatorIncomeAnnotation struct {
+	Day     int64    `json:"day"`
+	Reasons []string `json:"reasons"`
+}
+
+const (
+	IncomeReasonSlashed              = "slashed"
+	IncomeReasonOrphanedBlocks       = "orphaned_blocks"
+	IncomeReasonMissedBlocks         = "missed_blocks"
+	IncomeReasonMissedSync           = "missed_sync"
+	IncomeReasonMissedAttestations   = "missed_attestations"
+	IncomeReasonOrphanedAttestations = "orphaned_attestations"
+	// IncomeReasonUnknown is used for days without any failed duties, e.g. because of an inactivity leak
+	IncomeReasonUnknown = "unknown"
+)
+
+// ValidatorWithdrawalHistory are the summed up withdrawals (amount in gwei) of a set of validators during a day
+type ValidatorWithdrawalHistory struct {
+	Day               int64  `db:"day"`
+	Withdrawals       uint64 `db:"withdrawals"`
+	WithdrawalsAmount uint64 `db:"withdrawals_amount"`
+}
+
 // ValidatorBalanceHistory is a struct for the validator income history data
 type ValidatorIncomeHistory struct {
-	Day              int64         `db:"day"` // day can be -1 which is pre-genesis
-	ClRewards        int64         `db:"cl_rewards_gwei"`
+	Day int64 `db:"day"` // day can be -1 which is pre-genesis
+	// ClRewards is the gross income derived from the balance change (end - start + withdrawals - deposits)
+	ClRewards int64 `db:"cl_rewards_gwei"`
+	// ClRewardsNet only contains the rewards and penalties of the validator duties, balance jumps caused by activations or top-up deposits are excluded.
+	// It is not set for the current day which has not been exported yet.
+	ClRewardsNet     int64         `db:"cl_rewards_gwei_net"`
 	EndBalance       sql.NullInt64 `db:"end_balance"`
 	StartBalance     sql.NullInt64 `db:"start_balance"`
 	DepositAmount    sql.NullInt64 `db:"deposits_amount"`
 	WithdrawalAmount sql.NullInt64 `db:"withdrawals_amount"`
+	// ElRewards and MevRewards are in wei, for the current day they are estimated from the blocks proposed so far
+	ElRewards  decimal.Decimal `db:"el_rewards_wei"`
+	MevRewards decimal.Decimal `db:"mev_rewards_wei"`
+}
+
+// ValidatorDailyPerformance is a struct for a single day of the validator daily stats table, balances and cl rewards are in gwei, el and mev rewards in wei
+type ValidatorDailyPerformance struct {
+	Day               int64           `db:"day"`
+	StartBalance      int64           `db:"start_balance"`
+	EndBalance        int64           `db:"end_balance"`
+	ClRewards         int64           `db:"cl_rewards_gwei"`
+	ElRewards         decimal.Decimal `db:"el_rewards_wei"`
+	MevRewards        decimal.Decimal `db:"mev_rewards_wei"`
+	ProposedBlocks    int64           `db:"proposed_blocks"`
+	MissedBlocks      int64           `db:"missed_blocks"`
+	OrphanedBlocks    int64           `db:"orphaned_blocks"`
+	ParticipatedSync  int64           `db:"participated_sync"`
+	MissedSync        int64           `db:"missed_sync"`
+	OrphanedSync      int64           `db:"orphaned_sync"`
+	Deposits          int64           `db:"deposits"`
+	DepositsAmount    int64           `db:"deposits_amount"`
+	Withdrawals       int64           `db:"withdrawals"`
+	WithdrawalsAmount int64           `db:"withdrawals_amount"`
+}
+
+// ValidatorStatsRow is a full row of the validator_stats table, balances and cl rewards are in gwei, el and mev rewards in wei.
+// Columns that have not been exported yet are zero.
+type ValidatorStatsRow struct {
+	ValidatorIndex                        uint64          `db:"validatorindex"`
+	Day                                   int64           `db:"day"`
+	StartBalance                          int64           `db:"start_balance"`
+	EndBalance                            int64           `db:"end_balance"`
+	MinBalance                            int64           `db:"min_balance"`
+	MaxBalance                            int64           `db:"max_balance"`
+	StartEffectiveBalance                 int64           `db:"start_effective_balance"`
+	EndEffectiveBalance                   int64           `db:"end_effective_balance"`
+	MinEffectiveBalance                   int64           `db:"min_effective_balance"`
+	MaxEffectiveBalance                   int64           `db:"max_effective_balance"`
+	MissedAttestations                    int64           `db:"missed_attestations"`
+	OrphanedAttestations                  int64           `db:"orphaned_attestations"`
+	MissedSource                          int64           `db:"missed_source"`
+	MissedTarget                          int64           `db:"missed_target"`
+	MissedHead                            int64           `db:"missed_head"`
+	AvgInclusionDistance                  float64         `db:"avg_inclusion_distance"`
+	MaxInclusionDistance                  int64           `db:"max_inclusion_distance"`
+	ParticipatedSync                      int64           `db:"participated_sync"`
+	MissedSync                            int64           `db:"missed_sync"`
+	OrphanedSync                          int64           `db:"orphaned_sync"`
+	SyncParticipationRate                 float64         `db:"sync_participation_rate"`
+	ProposedBlocks                        int64           `db:"proposed_blocks"`
+	MissedBlocks                          int64           `db:"missed_blocks"`
+	OrphanedBlocks                        int64           `db:"orphaned_blocks"`
+	LateBlocks                            int64           `db:"late_blocks"`
+	MevBlocks                             int64           `db:"mev_blocks"`
+	LocalBlocks                           int64           `db:"local_blocks"`
+	AttesterSlashings                     int64           `db:"attester_slashings"`
+	ProposerSlashings                     int64           `db:"proposer_slashings"`
+	Deposits                              int64           `db:"deposits"`
+	DepositsAmount                        int64           `db:"deposits_amount"`
+	Withdrawals                           int64           `db:"withdrawals"`
+	WithdrawalsAmount                     int64           `db:"withdrawals_amount"`
+	ClRewards                             int64           `db:"cl_rewards_gwei"`
+	ClRewardsTotal                        int64           `db:"cl_rewards_gwei_total"`
+	ClRewardsNet                          int64           `db:"cl_rewards_gwei_net"`
+	ClProposerRewards                     int64           `db:"cl_proposer_rewards_gwei"`
+	ClProposerRewardsTotal                int64           `db:"cl_proposer_rewards_gwei_total"`
+	ClProposerAttestationInclusionRewards int64           `db:"cl_proposer_attestation_inclusion_rewards_gwei"`
+	ClProposerSyncInclusionRewards        int64           `db:"cl_proposer_sync_inclusion_rewards_gwei"`
+	ClProposerSlashingInclusionRewards    int64           `db:"cl_proposer_slashing_inclusion_rewards_gwei"`
+	SlashingReward                        int64           `db:"slashing_reward_gwei"`
+	ElRewards                             decimal.Decimal `db:"el_rewards_wei"`
+	ElRewardsTotal                        decimal.Decimal `db:"el_rewards_wei_total"`
+	MevRewards                            decimal.Decimal `db:"mev_rewards_wei"`
+	MevRewardsTotal                       decimal.Decimal `db:"mev_rewards_wei_total"`
+	HadRelayData                          bool            `db:"had_relay_data"`
+	AttestationEffectiveness              float64         `db:"attestation_effectiveness"`
+}
+
+// ValidatorWithdrawalAddressStats are the withdrawals of a validator to a withdrawal address during a day, the amount is in gwei
+type ValidatorWithdrawalAddressStats struct {
+	ValidatorIndex    uint64 `db:"validatorindex"`
+	Address           []byte `db:"address"`
+	Day               int64  `db:"day"`
+	Withdrawals       uint64 `db:"withdrawals"`
+	WithdrawalsAmount uint64 `db:"withdrawals_amount"`
+}
+
+// ValidatorAttestationEffectiveness is the attestation effectiveness of a validator for a day in percent
+type ValidatorAttestationEffectiveness struct {
+	Day           int64   `db:"day"`
+	Effectiveness float64 `db:"attestation_effectiveness"`
+}
+
+// ValidatorSyncCommitteePeriod is a sync committee period a validator was a member of with its sync duties during the period
+type ValidatorSyncCommitteePeriod struct {
+	Period            uint64
+	FirstEpoch        uint64
+	LastEpoch         uint64
+	ParticipatedSync  uint64
+	MissedSync        uint64
+	OrphanedSync      uint64
+	ParticipationRate *float64
+}
+
+// ValidatorDayMetric is the value of a single daily statistic of a validator
+type ValidatorDayMetric struct {
+	Day   int64 `db:"day"`
+	Value int64 `db:"value"`
+}
+
+// ValidatorPerformanceExtremes contains the best and worst days of a validator by consensus rewards and by missed attestations
+type ValidatorPerformanceExtremes struct {
+	BestClRewardsDays            []ValidatorDayMetric
+	WorstClRewardsDays           []ValidatorDayMetric
+	FewestMissedAttestationsDays []ValidatorDayMetric
+	MostMissedAttestationsDays   []ValidatorDayMetric
+}
+
+// ValidatorBlockStats contains the number of proposed, missed and orphaned blocks of a validator
+type ValidatorBlockStats struct {
+	ProposedBlocks int64 `db:"proposed_blocks"`
+	MissedBlocks   int64 `db:"missed_blocks"`
+	OrphanedBlocks int64 `db:"orphaned_blocks"`
+}
+
+// ValidatorBlockStatsDiscrepancy is a validator whose block stats stored in validator_stats differ from the blocks table
+type ValidatorBlockStatsDiscrepancy struct {
+	ValidatorIndex uint64
+	Stored         ValidatorBlockStats
+	Counted        ValidatorBlockStats
+}
+
+// NetworkDayStats is a struct for the network wide aggregates of an exported day, balances and cl rewards are in gwei, el and mev rewards in wei
+type NetworkDayStats struct {
+	Day               uint64          `db:"day"`
+	ActiveValidators  uint64          `db:"active_validators"`
+	TotalBalance      int64           `db:"total_balance"`
+	TotalStake        int64           `db:"total_effective_balance"`
+	AverageBalance    int64           `db:"avg_balance"`
+	ClRewards         int64           `db:"cl_rewards_gwei"`
+	ElRewards         decimal.Decimal `db:"el_rewards_wei"`
+	MevRewards        decimal.Decimal `db:"mev_rewards_wei"`
+	Deposits          int64           `db:"deposits"`
+	DepositsAmount    int64           `db:"deposits_amount"`
+	Withdrawals       int64           `db:"withdrawals"`
+	WithdrawalsAmount int64           `db:"withdrawals_amount"`
+	ProposedBlocks    int64           `db:"proposed_blocks"`
+	MissedBlocks      int64           `db:"missed_blocks"`
+	OrphanedBlocks    int64           `db:"orphaned_blocks"`
+	// SyncParticipationRate is participated / (participated + missed) of all sync duties of the day, nil if there were none
+	SyncParticipationRate *float64 `db:"sync_participation_rate"`
+}
+
+// NetworkBalancePercentiles is a struct for the distribution of the validator end balances of a day in gwei
+type NetworkBalancePercentiles struct {
+	Day    uint64 `db:"day"`
+	P25    int64  `db:"balance_p25"`
+	Median int64  `db:"balance_median"`
+	P75    int64  `db:"balance_p75"`
 }
 
 type ValidatorBalanceHistoryChartData struct {
@@ -551,6 +799,55 @@ type ValidatorPerformance struct {
 	Performance365d int64  `db:"performance365d"`
 	Rank7d          int64  `db:"rank7d"`
 	TotalCount      uint64 `db:"total_count"`
+
+	// consensus layer performance in gwei
+	ClPerformance1d            int64 `db:"cl_performance_1d"`
+	ClPerformance7d            int64 `db:"cl_performance_7d"`
+	ClPerformance31d           int64 `db:"cl_performance_31d"`
+	ClPerformance365d          int64 `db:"cl_performance_365d"`
+	ClPerformanceTotal         int64 `db:"cl_performance_total"`
+	ClProposerPerformanceTotal int64 `db:"cl_proposer_performance_total"`
+
+	// execution layer and mev performance in wei
+	ElPerformance1d     decimal.Decimal `db:"el_performance_1d"`
+	ElPerformance7d     decimal.Decimal `db:"el_performance_7d"`
+	ElPerformance31d    decimal.Decimal `db:"el_performance_31d"`
+	ElPerformance365d   decimal.Decimal `db:"el_performance_365d"`
+	ElPerformanceTotal  decimal.Decimal `db:"el_performance_total"`
+	MevPerformance1d    decimal.Decimal `db:"mev_performance_1d"`
+	MevPerformance7d    decimal.Decimal `db:"mev_performance_7d"`
+	MevPerformance31d   decimal.Decimal `db:"mev_performance_31d"`
+	MevPerformance365d  decimal.Decimal `db:"mev_performance_365d"`
+	MevPerformanceTotal decimal.Decimal `db:"mev_performance_total"`
+}
+
+// AggregatedValidatorPerformance contains the summed up validator_performance rows of a group of validators
+type AggregatedValidatorPerformance struct {
+	ValidatorCount  uint64 `db:"validator_count"`
+	Balance         uint64 `db:"balance"`
+	Performance1d   int64  `db:"performance1d"`
+	Performance7d   int64  `db:"performance7d"`
+	Performance31d  int64  `db:"performance31d"`
+	Performance365d int64  `db:"performance365d"`
+
+	// consensus layer performance in gwei
+	ClPerformance1d    int64 `db:"cl_performance_1d"`
+	ClPerformance7d    int64 `db:"cl_performance_7d"`
+	ClPerformance31d   int64 `db:"cl_performance_31d"`
+	ClPerformance365d  int64 `db:"cl_performance_365d"`
+	ClPerformanceTotal int64 `db:"cl_performance_total"`
+
+	// execution layer and mev performance in wei
+	ElPerformance1d     decimal.Decimal `db:"el_performance_1d"`
+	ElPerformance7d     decimal.Decimal `db:"el_performance_7d"`
+	ElPerformance31d    decimal.Decimal `db:"el_performance_31d"`
+	ElPerformance365d   decimal.Decimal `db:"el_performance_365d"`
+	ElPerformanceTotal  decimal.Decimal `db:"el_performance_total"`
+	MevPerformance1d    decimal.Decimal `db:"mev_performance_1d"`
+	MevPerformance7d    decimal.Decimal `db:"mev_performance_7d"`
+	MevPerformance31d   decimal.Decimal `db:"mev_performance_31d"`
+	MevPerformance365d  decimal.Decimal `db:"mev_performance_365d"`
+	MevPerformanceTotal decimal.Decimal `db:"mev_performance_total"`
 }
 
 // ValidatorAttestation is a struct for the validators attestations data
@@ -1094,6 +1391,16 @@ type ValidatorSlashing struct {
 	Type                   string        `db:"type" json:"type"`
 }
 
+// ValidatorSlashingEvent is a struct for a single slashing of a validator
+type ValidatorSlashingEvent struct {
+	ValidatorIndex uint64 `db:"validatorindex" json:"validatorindex"`
+	Day            uint64 `db:"day" json:"day"`
+	Slot           uint64 `db:"slot" json:"slot"`
+	BlockIndex     uint64 `db:"block_index" json:"block_index"`
+	Slasher        uint64 `db:"slasher" json:"slasher"`
+	Type           string `db:"type" json:"type"` // either "attester" or "proposer"
+}
+
 type StakingCalculatorPageData struct {
 	BestValidatorBalanceHistory *[]ValidatorBalanceHistory
 	WatchlistBalanceHistory     [][]interface{}
@@ -35,6 +35,25 @@ func Eth1BlockReward(blockNumber uint64, difficulty []byte) *big.Int {
 	}
 }
 
+// Eth1UncleRewards returns the newly issued ether for the uncles of a PoW block, the rewards of the uncle miners
+// ((uncle number + 8 - block number) * block reward / 8) plus the inclusion reward of block reward / 32 per uncle
+func Eth1UncleRewards(block *types.Eth1Block) *big.Int {
+	total := big.NewInt(0)
+	if len(block.GetDifficulty()) == 0 { // no uncle rewards in PoS
+		return total
+	}
+
+	blockReward := Eth1BlockReward(block.GetNumber(), block.GetDifficulty())
+	for _, uncle := range block.GetUncles() {
+		r := new(big.Int).SetUint64(uncle.GetNumber() + 8 - block.GetNumber())
+		r.Mul(r, blockReward)
+		r.Div(r, big.NewInt(8))
+		total.Add(total, r)
+		total.Add(total, new(big.Int).Div(blockReward, big.NewInt(32)))
+	}
+	return total
+}
+
 func Eth1TotalReward(block *types.Eth1BlockIndexed) *big.Int {
 	blockReward := Eth1BlockReward(block.GetNumber(), block.GetDifficulty())
 	uncleReward := big.NewInt(0).SetBytes(block.GetUncleReward())
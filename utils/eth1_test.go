@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"eth2-exporter/types"
+	"math/big"
+	"testing"
+)
+
+func TestEth1UncleRewards(t *testing.T) {
+	tests := []struct {
+		name  string
+		block *types.Eth1Block
+		want  string
+	}{
+		{
+			name:  "one uncle two blocks back",
+			block: &types.Eth1Block{Number: 100, Difficulty: []byte{1}, Uncles: []*types.Eth1Block{{Number: 98}}},
+			want:  "3906250000000000000", // 6/8 * 5 ETH + 5/32 ETH
+		},
+		{
+			name:  "two uncles",
+			block: &types.Eth1Block{Number: 100, Difficulty: []byte{1}, Uncles: []*types.Eth1Block{{Number: 99}, {Number: 94}}},
+			want:  "5937500000000000000", // 7/8 * 5 ETH + 2/8 * 5 ETH + 2 * 5/32 ETH
+		},
+		{
+			name:  "proof of stake block",
+			block: &types.Eth1Block{Number: 16000000, Uncles: []*types.Eth1Block{{Number: 15999999}}},
+			want:  "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, _ := new(big.Int).SetString(tt.want, 10)
+			if got := Eth1UncleRewards(tt.block); got.Cmp(want) != 0 {
+				t.Errorf("Eth1UncleRewards() = %v, want %v", got, want)
+			}
+		})
+	}
+}
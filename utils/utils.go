@@ -296,6 +296,25 @@ func TimeToSyncPeriod(t time.Time) uint64 {
 	return SyncPeriodOfEpoch(uint64(TimeToEpoch(t)))
 }
 
+// defaultGenesisSupply is the ether supply of the mainnet genesis block, which is used for all chains without a configured genesis supply
+const defaultGenesisSupply = 72009990.50
+
+// GenesisSupply returns the ether supply of the genesis block of the chain, the mainnet one unless configured
+func GenesisSupply() float64 {
+	if Config == nil || Config.Chain.GenesisSupply == 0 {
+		return defaultGenesisSupply
+	}
+	return Config.Chain.GenesisSupply
+}
+
+// ClCurrencyDivisor returns the number of gwei per unit of the consensus layer currency of the chain, 1e9 unless configured
+func ClCurrencyDivisor() uint64 {
+	if Config == nil || Config.Chain.Config.ClCurrencyDivisor == 0 {
+		return 1e9
+	}
+	return Config.Chain.Config.ClCurrencyDivisor
+}
+
 // EpochOfSlot returns the corresponding epoch of a slot
 func EpochOfSlot(slot uint64) uint64 {
 	return slot / Config.Chain.Config.SlotsPerEpoch
@@ -462,6 +481,37 @@ func ReadConfig(cfg *types.Config, path string) error {
 		}
 	}
 
+	if cfg.Chain.Config.ClCurrencyDivisor == 0 {
+		cfg.Chain.Config.ClCurrencyDivisor = 1e9
+		if cfg.Chain.Name == "gnosis" {
+			// 1 GNO = 32 mGNO
+			cfg.Chain.Config.ClCurrencyDivisor = 32e9
+		}
+	}
+
+	if cfg.Chain.MergeBlock == 0 {
+		// first proof of stake block, chains without a known merge block are assumed to have started after the merge
+		switch cfg.Chain.Name {
+		case "mainnet":
+			cfg.Chain.MergeBlock = 15537394
+		case "prater":
+			cfg.Chain.MergeBlock = 7382819
+		case "sepolia":
+			cfg.Chain.MergeBlock = 1735371
+		case "gnosis":
+			cfg.Chain.MergeBlock = 25349537
+		}
+	}
+
+	if cfg.Statistics.MarketCapCurrency == "" {
+		cfg.Statistics.MarketCapCurrency = "USD"
+	}
+
+	if cfg.Statistics.ComputeRanks == nil {
+		computeRanks := true
+		cfg.Statistics.ComputeRanks = &computeRanks
+	}
+
 	if cfg.Chain.DomainBLSToExecutionChange == "" {
 		cfg.Chain.DomainBLSToExecutionChange = "0x0A000000"
 	}
@@ -1100,15 +1150,28 @@ func GetTimeToNextWithdrawal(distance uint64) time.Time {
 	return timeToWithdrawal
 }
 
+// EpochsPerDay returns the number of epochs that fit into 24 hours. If a day is not a multiple of the epoch duration the number of
+// epochs per day varies, use GetFirstAndLastEpochForDay and EpochToDay to map between days and epochs exactly.
 func EpochsPerDay() uint64 {
 	day := time.Hour * 24
 	return (uint64(day.Seconds()) / Config.Chain.Config.SlotsPerEpoch) / Config.Chain.Config.SecondsPerSlot
 }
 
+// EpochToDay returns the day an epoch belongs to, which is the day since genesis the epoch starts in
+func EpochToDay(epoch uint64) uint64 {
+	return epoch * Config.Chain.Config.SlotsPerEpoch * Config.Chain.Config.SecondsPerSlot / uint64((time.Hour * 24).Seconds())
+}
+
+// FirstEpochOfDay returns the first epoch starting in the day, the epoch overlapping the start of the day belongs to the previous day
+func FirstEpochOfDay(day uint64) uint64 {
+	epochSeconds := Config.Chain.Config.SlotsPerEpoch * Config.Chain.Config.SecondsPerSlot
+	return (day*uint64((time.Hour*24).Seconds()) + epochSeconds - 1) / epochSeconds
+}
+
+// GetFirstAndLastEpochForDay returns the first and last epoch (inclusive) belonging to the day. It agrees with EpochToDay for every
+// epoch, also on chains where a day is not a multiple of the epoch duration.
 func GetFirstAndLastEpochForDay(day uint64) (uint64, uint64) {
-	firstEpoch := day * EpochsPerDay()
-	lastEpoch := firstEpoch + EpochsPerDay() - 1
-	return firstEpoch, lastEpoch
+	return FirstEpochOfDay(day), FirstEpochOfDay(day+1) - 1
 }
 
 // ForkVersionAtEpoch returns the forkversion active a specific epoch
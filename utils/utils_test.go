@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"eth2-exporter/types"
 	"testing"
 )
 
@@ -26,3 +27,42 @@ func TestIsValidUrl(t *testing.T) {
 		}
 	}
 }
+
+func TestGetFirstAndLastEpochForDay(t *testing.T) {
+	tests := []struct {
+		name           string
+		slotsPerEpoch  uint64
+		secondsPerSlot uint64
+		day1FirstEpoch uint64
+	}{
+		{"mainnet", 32, 12, 225},
+		{"gnosis", 16, 5, 1080},
+		{"uneven", 32, 14, 193}, // 448s epochs, epoch 192 starts before and ends after the first midnight
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = &types.Config{}
+			Config.Chain.Config.SlotsPerEpoch = tt.slotsPerEpoch
+			Config.Chain.Config.SecondsPerSlot = tt.secondsPerSlot
+
+			if first, _ := GetFirstAndLastEpochForDay(1); first != tt.day1FirstEpoch {
+				t.Errorf("expected day 1 to start at epoch %v, got %v", tt.day1FirstEpoch, first)
+			}
+
+			expectedFirst := uint64(0)
+			for day := uint64(0); day < 30; day++ {
+				first, last := GetFirstAndLastEpochForDay(day)
+				if first != expectedFirst {
+					t.Fatalf("expected day %v to start at epoch %v right after the previous day, got %v", day, expectedFirst, first)
+				}
+				if EpochToDay(first) != day || EpochToDay(last) != day || EpochToDay(last+1) != day+1 {
+					t.Fatalf("epochs %v to %v of day %v disagree with EpochToDay", first, last, day)
+				}
+				if EpochToTime(first).Before(DayToTime(int64(day))) || !EpochToTime(last).Before(DayToTime(int64(day+1))) {
+					t.Fatalf("epochs %v to %v do not start within day %v", first, last, day)
+				}
+				expectedFirst = last + 1
+			}
+		})
+	}
+}